@@ -0,0 +1,57 @@
+package adapter_test
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/kylesnowschwartz/the-themer/adapter"
+	_ "github.com/kylesnowschwartz/the-themer/adapter/base16"
+	_ "github.com/kylesnowschwartz/the-themer/adapter/bat"
+	_ "github.com/kylesnowschwartz/the-themer/adapter/delta"
+	_ "github.com/kylesnowschwartz/the-themer/adapter/fzf"
+	_ "github.com/kylesnowschwartz/the-themer/adapter/ghdash"
+	_ "github.com/kylesnowschwartz/the-themer/adapter/ghostty"
+	_ "github.com/kylesnowschwartz/the-themer/adapter/hud"
+	_ "github.com/kylesnowschwartz/the-themer/adapter/tcm"
+	"github.com/kylesnowschwartz/the-themer/palette"
+)
+
+// passesAlpha lists the adapters whose format carries #RRGGBBAA; each
+// tests that itself.
+var passesAlpha = map[string]bool{"tcm": true}
+
+var eightDigitHex = regexp.MustCompile(`\b[0-9a-fA-F]{8}\b`)
+
+// TestGenerate_Alpha makes every color but bg translucent and checks that
+// adapters whose apps can't draw alpha emit only opaque colors.
+func TestGenerate_Alpha(t *testing.T) {
+	cfg, err := palette.Load("../testdata/bleu.toml")
+	if err != nil {
+		t.Fatalf("Load bleu.toml: %v", err)
+	}
+	translucent := func(key string, v *palette.Color) {
+		if key != "bg" && len(*v) == len("#RRGGBB") {
+			*v += "80"
+		}
+	}
+	cfg.Palette.Walk(translucent)
+	cfg.RawPalette.Walk(translucent)
+
+	if len(adapter.All()) == 0 {
+		t.Fatal("no adapters registered")
+	}
+	for _, a := range adapter.All() {
+		if passesAlpha[a.Name()] {
+			continue
+		}
+		t.Run(a.Name(), func(t *testing.T) {
+			got, err := a.Generate(cfg)
+			if err != nil {
+				t.Fatalf("Generate: %v", err)
+			}
+			if m := eightDigitHex.Find(got); m != nil {
+				t.Errorf("output contains translucent color %s:\n%s", m, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,30 @@
+// Package base16 generates base16 and base24 scheme YAML, the interchange
+// format consumed by tinted-theming templates and other base16 tooling.
+// The slot mapping lives in palette/base16 so importing shares it.
+package base16
+
+import (
+	"github.com/kylesnowschwartz/the-themer/adapter"
+	"github.com/kylesnowschwartz/the-themer/palette"
+	scheme "github.com/kylesnowschwartz/the-themer/palette/base16"
+)
+
+func init() {
+	adapter.Register(&schemeAdapter{system: "base16"})
+	adapter.Register(&schemeAdapter{system: "base24"})
+}
+
+// schemeAdapter emits one scheme system; base16 and base24 differ only in
+// how many slots they carry.
+type schemeAdapter struct {
+	system string
+}
+
+func (s *schemeAdapter) Name() string                     { return s.system }
+func (s *schemeAdapter) DirName() string                  { return s.system }
+func (s *schemeAdapter) FileName(themeName string) string { return themeName + ".yaml" }
+
+func (s *schemeAdapter) Generate(cfg palette.Config) ([]byte, error) {
+	// Scheme slots are six-digit hex by spec.
+	return scheme.FromConfig(cfg.Opaque(), s.system).Encode(), nil
+}
@@ -0,0 +1,56 @@
+package base16_test
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/kylesnowschwartz/the-themer/adapter"
+	_ "github.com/kylesnowschwartz/the-themer/adapter/base16"
+	"github.com/kylesnowschwartz/the-themer/palette"
+)
+
+func TestGenerate_OracleBleu(t *testing.T) {
+	cfg, err := palette.Load("../../testdata/bleu.toml")
+	if err != nil {
+		t.Fatalf("Load bleu.toml: %v", err)
+	}
+
+	for _, system := range []string{"base16", "base24"} {
+		t.Run(system, func(t *testing.T) {
+			adapters := adapter.ByName([]string{system})
+			if len(adapters) != 1 {
+				t.Fatalf("expected 1 %s adapter, got %d", system, len(adapters))
+			}
+
+			got, err := adapters[0].Generate(cfg)
+			if err != nil {
+				t.Fatalf("Generate: %v", err)
+			}
+
+			expected, err := os.ReadFile("../../testdata/expected/" + system + "/bleu.yaml")
+			if err != nil {
+				t.Fatalf("reading expected fixture: %v", err)
+			}
+			if !bytes.Equal(got, expected) {
+				t.Errorf("output differs from oracle\n--- got ---\n%s\n--- want ---\n%s", got, expected)
+			}
+		})
+	}
+}
+
+func TestAdapterRegistration(t *testing.T) {
+	for _, system := range []string{"base16", "base24"} {
+		adapters := adapter.ByName([]string{system})
+		if len(adapters) != 1 {
+			t.Fatalf("%s adapter not registered", system)
+		}
+		a := adapters[0]
+		if a.DirName() != system {
+			t.Errorf("DirName: got %q, want %q", a.DirName(), system)
+		}
+		if a.FileName("bleu") != "bleu.yaml" {
+			t.Errorf("FileName: got %q, want %q", a.FileName("bleu"), "bleu.yaml")
+		}
+	}
+}
@@ -21,6 +21,10 @@ func (b *batAdapter) DirName() string                  { return "bat" }
 func (b *batAdapter) FileName(themeName string) string { return themeName + ".tmTheme" }
 
 func (b *batAdapter) Generate(cfg palette.Config) ([]byte, error) {
+	// .tmTheme allows #RRGGBBAA, but bat doesn't blend: it draws the RGB
+	// part as is and reserves alpha 00 and 01 to encode ANSI palette
+	// indexes. Composite so translucent colors render as intended.
+	cfg = cfg.Opaque()
 	var buf bytes.Buffer
 	if err := batTmpl.Execute(&buf, cfg); err != nil {
 		return nil, err
@@ -43,19 +47,20 @@ func titleCase(s string) string {
 
 // batTmpl renders a TextMate .tmTheme XML file from the palette.
 //
-// Color mapping from palette to TextMate scopes:
+// Scopes take their colors from the palette's syntax roles (see
+// palette.Syntax), whose defaults reproduce bat's original mapping:
 //
 //	Global settings: BG, FG, Cursor, SelectionBG/FG, Syntax.LineHighlight
-//	Structural (type, operator, punctuation, tag, property): Color4
-//	Keyword/accent (keyword, attribute, boolean, link, import, decorator): UI.Accent
-//	String/literal (string, regexp, code): Color5
-//	Comment/dimmed (comment, quote, preprocessor): UI.Dimmed
-//	Emphasis (function, heading, bold): Color15 (dark) / Color0 (light)
-//	Variable, parameter: FG
-//	Number/constant: Syntax.Number
-//	Invalid/diff-deleted: Syntax.Error
-//	Diff added: Color2
-//	Diff changed: UI.Accent
+//	keyword: keyword, boolean, import, built-in function
+//	string: string, markup code; regexp: regular expression
+//	comment: comment, quote, preprocessor
+//	function: function; heading: heading, bold
+//	type: type, class, namespace
+//	operator: operator, punctuation, embedded, escape sequence
+//	tag: tag, property; attribute: attribute, decorator
+//	number: number; constant: constant, built-in constant
+//	variable, parameter, link: their scopes; italic markup: FG
+//	error: invalid; diff_added/diff_removed/diff_changed: diff markup
 var batTmpl = template.Must(template.New("bat").Funcs(template.FuncMap{
 	"title": titleCase,
 }).Parse(`<?xml version="1.0" encoding="UTF-8"?>
@@ -107,7 +112,7 @@ var batTmpl = template.Must(template.New("bat").Funcs(template.FuncMap{
 			<key>settings</key>
 			<dict>
 				<key>foreground</key>
-				<string>{{.Palette.UI.Dimmed}}</string>
+				<string>{{.Palette.Syntax.Comment}}</string>
 				<key>fontStyle</key>
 				<string>italic</string>
 			</dict>
@@ -120,7 +125,7 @@ var batTmpl = template.Must(template.New("bat").Funcs(template.FuncMap{
 			<key>settings</key>
 			<dict>
 				<key>foreground</key>
-				<string>{{.Palette.UI.Accent}}</string>
+				<string>{{.Palette.Syntax.Keyword}}</string>
 				<key>fontStyle</key>
 				<string>italic</string>
 			</dict>
@@ -133,7 +138,7 @@ var batTmpl = template.Must(template.New("bat").Funcs(template.FuncMap{
 			<key>settings</key>
 			<dict>
 				<key>foreground</key>
-				<string>{{.Palette.Color5}}</string>
+				<string>{{.Palette.Syntax.String}}</string>
 			</dict>
 		</dict>
 		<dict>
@@ -155,7 +160,7 @@ var batTmpl = template.Must(template.New("bat").Funcs(template.FuncMap{
 			<key>settings</key>
 			<dict>
 				<key>foreground</key>
-				<string>{{.Palette.UI.Accent}}</string>
+				<string>{{.Palette.Syntax.Keyword}}</string>
 			</dict>
 		</dict>
 		<dict>
@@ -166,7 +171,7 @@ var batTmpl = template.Must(template.New("bat").Funcs(template.FuncMap{
 			<key>settings</key>
 			<dict>
 				<key>foreground</key>
-				<string>{{.Palette.Syntax.Number}}</string>
+				<string>{{.Palette.Syntax.Constant}}</string>
 			</dict>
 		</dict>
 		<dict>
@@ -177,7 +182,7 @@ var batTmpl = template.Must(template.New("bat").Funcs(template.FuncMap{
 			<key>settings</key>
 			<dict>
 				<key>foreground</key>
-				<string>{{if eq .Theme.Variant "light"}}{{.Palette.Color0}}{{else}}{{.Palette.Color15}}{{end}}</string>
+				<string>{{.Palette.Syntax.Function}}</string>
 				<key>fontStyle</key>
 				<string>bold</string>
 			</dict>
@@ -190,7 +195,7 @@ var batTmpl = template.Must(template.New("bat").Funcs(template.FuncMap{
 			<key>settings</key>
 			<dict>
 				<key>foreground</key>
-				<string>{{.Palette.Color4}}</string>
+				<string>{{.Palette.Syntax.Type}}</string>
 			</dict>
 		</dict>
 		<dict>
@@ -201,7 +206,7 @@ var batTmpl = template.Must(template.New("bat").Funcs(template.FuncMap{
 			<key>settings</key>
 			<dict>
 				<key>foreground</key>
-				<string>{{.Palette.FG}}</string>
+				<string>{{.Palette.Syntax.Variable}}</string>
 			</dict>
 		</dict>
 		<dict>
@@ -212,7 +217,7 @@ var batTmpl = template.Must(template.New("bat").Funcs(template.FuncMap{
 			<key>settings</key>
 			<dict>
 				<key>foreground</key>
-				<string>{{.Palette.Color4}}</string>
+				<string>{{.Palette.Syntax.Operator}}</string>
 			</dict>
 		</dict>
 		<dict>
@@ -223,7 +228,7 @@ var batTmpl = template.Must(template.New("bat").Funcs(template.FuncMap{
 			<key>settings</key>
 			<dict>
 				<key>foreground</key>
-				<string>{{.Palette.Color4}}</string>
+				<string>{{.Palette.Syntax.Operator}}</string>
 			</dict>
 		</dict>
 		<dict>
@@ -234,7 +239,7 @@ var batTmpl = template.Must(template.New("bat").Funcs(template.FuncMap{
 			<key>settings</key>
 			<dict>
 				<key>foreground</key>
-				<string>{{.Palette.Color4}}</string>
+				<string>{{.Palette.Syntax.Tag}}</string>
 			</dict>
 		</dict>
 		<dict>
@@ -245,7 +250,7 @@ var batTmpl = template.Must(template.New("bat").Funcs(template.FuncMap{
 			<key>settings</key>
 			<dict>
 				<key>foreground</key>
-				<string>{{.Palette.UI.Accent}}</string>
+				<string>{{.Palette.Syntax.Attribute}}</string>
 			</dict>
 		</dict>
 		<dict>
@@ -256,7 +261,7 @@ var batTmpl = template.Must(template.New("bat").Funcs(template.FuncMap{
 			<key>settings</key>
 			<dict>
 				<key>foreground</key>
-				<string>{{.Palette.Color4}}</string>
+				<string>{{.Palette.Syntax.Operator}}</string>
 			</dict>
 		</dict>
 		<dict>
@@ -278,7 +283,7 @@ var batTmpl = template.Must(template.New("bat").Funcs(template.FuncMap{
 			<key>settings</key>
 			<dict>
 				<key>foreground</key>
-				<string>{{.Palette.Color2}}</string>
+				<string>{{.Palette.Syntax.DiffAdded}}</string>
 			</dict>
 		</dict>
 		<dict>
@@ -289,7 +294,7 @@ var batTmpl = template.Must(template.New("bat").Funcs(template.FuncMap{
 			<key>settings</key>
 			<dict>
 				<key>foreground</key>
-				<string>{{.Palette.Syntax.Error}}</string>
+				<string>{{.Palette.Syntax.DiffRemoved}}</string>
 			</dict>
 		</dict>
 		<dict>
@@ -300,7 +305,7 @@ var batTmpl = template.Must(template.New("bat").Funcs(template.FuncMap{
 			<key>settings</key>
 			<dict>
 				<key>foreground</key>
-				<string>{{.Palette.UI.Accent}}</string>
+				<string>{{.Palette.Syntax.DiffChanged}}</string>
 			</dict>
 		</dict>
 		<dict>
@@ -311,7 +316,7 @@ var batTmpl = template.Must(template.New("bat").Funcs(template.FuncMap{
 			<key>settings</key>
 			<dict>
 				<key>foreground</key>
-				<string>{{if eq .Theme.Variant "light"}}{{.Palette.Color0}}{{else}}{{.Palette.Color15}}{{end}}</string>
+				<string>{{.Palette.Syntax.Heading}}</string>
 				<key>fontStyle</key>
 				<string>bold</string>
 			</dict>
@@ -324,7 +329,7 @@ var batTmpl = template.Must(template.New("bat").Funcs(template.FuncMap{
 			<key>settings</key>
 			<dict>
 				<key>foreground</key>
-				<string>{{if eq .Theme.Variant "light"}}{{.Palette.Color0}}{{else}}{{.Palette.Color15}}{{end}}</string>
+				<string>{{.Palette.Syntax.Heading}}</string>
 				<key>fontStyle</key>
 				<string>bold</string>
 			</dict>
@@ -350,7 +355,7 @@ var batTmpl = template.Must(template.New("bat").Funcs(template.FuncMap{
 			<key>settings</key>
 			<dict>
 				<key>foreground</key>
-				<string>{{.Palette.UI.Accent}}</string>
+				<string>{{.Palette.Syntax.Link}}</string>
 			</dict>
 		</dict>
 		<dict>
@@ -361,7 +366,7 @@ var batTmpl = template.Must(template.New("bat").Funcs(template.FuncMap{
 			<key>settings</key>
 			<dict>
 				<key>foreground</key>
-				<string>{{.Palette.Color5}}</string>
+				<string>{{.Palette.Syntax.String}}</string>
 			</dict>
 		</dict>
 		<dict>
@@ -372,7 +377,7 @@ var batTmpl = template.Must(template.New("bat").Funcs(template.FuncMap{
 			<key>settings</key>
 			<dict>
 				<key>foreground</key>
-				<string>{{.Palette.UI.Dimmed}}</string>
+				<string>{{.Palette.Syntax.Comment}}</string>
 				<key>fontStyle</key>
 				<string>italic</string>
 			</dict>
@@ -385,7 +390,7 @@ var batTmpl = template.Must(template.New("bat").Funcs(template.FuncMap{
 			<key>settings</key>
 			<dict>
 				<key>foreground</key>
-				<string>{{.Palette.UI.Accent}}</string>
+				<string>{{.Palette.Syntax.Keyword}}</string>
 				<key>fontStyle</key>
 				<string>italic</string>
 			</dict>
@@ -398,7 +403,7 @@ var batTmpl = template.Must(template.New("bat").Funcs(template.FuncMap{
 			<key>settings</key>
 			<dict>
 				<key>foreground</key>
-				<string>{{.Palette.UI.Accent}}</string>
+				<string>{{.Palette.Syntax.Attribute}}</string>
 				<key>fontStyle</key>
 				<string>italic</string>
 			</dict>
@@ -411,7 +416,7 @@ var batTmpl = template.Must(template.New("bat").Funcs(template.FuncMap{
 			<key>settings</key>
 			<dict>
 				<key>foreground</key>
-				<string>{{.Palette.Color4}}</string>
+				<string>{{.Palette.Syntax.Operator}}</string>
 				<key>fontStyle</key>
 				<string>bold</string>
 			</dict>
@@ -424,7 +429,7 @@ var batTmpl = template.Must(template.New("bat").Funcs(template.FuncMap{
 			<key>settings</key>
 			<dict>
 				<key>foreground</key>
-				<string>{{.Palette.Color5}}</string>
+				<string>{{.Palette.Syntax.Regexp}}</string>
 			</dict>
 		</dict>
 		<dict>
@@ -435,7 +440,7 @@ var batTmpl = template.Must(template.New("bat").Funcs(template.FuncMap{
 			<key>settings</key>
 			<dict>
 				<key>foreground</key>
-				<string>{{.Palette.UI.Dimmed}}</string>
+				<string>{{.Palette.Syntax.Comment}}</string>
 				<key>fontStyle</key>
 				<string>italic</string>
 			</dict>
@@ -448,7 +453,7 @@ var batTmpl = template.Must(template.New("bat").Funcs(template.FuncMap{
 			<key>settings</key>
 			<dict>
 				<key>foreground</key>
-				<string>{{.Palette.Color4}}</string>
+				<string>{{.Palette.Syntax.Tag}}</string>
 			</dict>
 		</dict>
 		<dict>
@@ -459,7 +464,7 @@ var batTmpl = template.Must(template.New("bat").Funcs(template.FuncMap{
 			<key>settings</key>
 			<dict>
 				<key>foreground</key>
-				<string>{{.Palette.FG}}</string>
+				<string>{{.Palette.Syntax.Parameter}}</string>
 				<key>fontStyle</key>
 				<string>italic</string>
 			</dict>
@@ -472,7 +477,7 @@ var batTmpl = template.Must(template.New("bat").Funcs(template.FuncMap{
 			<key>settings</key>
 			<dict>
 				<key>foreground</key>
-				<string>{{.Palette.UI.Accent}}</string>
+				<string>{{.Palette.Syntax.Keyword}}</string>
 				<key>fontStyle</key>
 				<string>bold</string>
 			</dict>
@@ -485,7 +490,7 @@ var batTmpl = template.Must(template.New("bat").Funcs(template.FuncMap{
 			<key>settings</key>
 			<dict>
 				<key>foreground</key>
-				<string>{{.Palette.Syntax.Number}}</string>
+				<string>{{.Palette.Syntax.Constant}}</string>
 				<key>fontStyle</key>
 				<string>bold</string>
 			</dict>
@@ -498,7 +503,7 @@ var batTmpl = template.Must(template.New("bat").Funcs(template.FuncMap{
 			<key>settings</key>
 			<dict>
 				<key>foreground</key>
-				<string>{{.Palette.Color4}}</string>
+				<string>{{.Palette.Syntax.Type}}</string>
 				<key>fontStyle</key>
 				<string>italic</string>
 			</dict>
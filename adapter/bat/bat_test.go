@@ -55,3 +55,25 @@ func TestAdapterRegistration(t *testing.T) {
 		t.Fatal("bat adapter not registered")
 	}
 }
+
+// TestGenerate_SyntaxRoles checks that scopes follow the palette's syntax
+// roles rather than fixed ANSI slots.
+func TestGenerate_SyntaxRoles(t *testing.T) {
+	cfg, err := palette.Load("../../testdata/bleu.toml")
+	if err != nil {
+		t.Fatalf("Load bleu.toml: %v", err)
+	}
+	cfg.Palette.Syntax.Keyword = "#123456"
+	cfg.Palette.Syntax.DiffAdded = "#654321"
+
+	got, err := adapter.ByName([]string{"bat"})[0].Generate(cfg)
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	if n := bytes.Count(got, []byte("#123456")); n != 4 {
+		t.Errorf("keyword color used %d times, want 4 (keyword, boolean, import, built-in function)", n)
+	}
+	if n := bytes.Count(got, []byte("#654321")); n != 1 {
+		t.Errorf("diff_added color used %d times, want 1", n)
+	}
+}
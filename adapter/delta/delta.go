@@ -21,6 +21,8 @@ func (d *deltaAdapter) DirName() string                  { return "delta" }
 func (d *deltaAdapter) FileName(themeName string) string { return themeName + ".gitconfig" }
 
 func (d *deltaAdapter) Generate(cfg palette.Config) ([]byte, error) {
+	// Delta styles take #RRGGBB only.
+	cfg = cfg.Opaque()
 	var buf bytes.Buffer
 	if err := deltaTmpl.Execute(&buf, cfg); err != nil {
 		return nil, err
@@ -33,4 +35,4 @@ func (d *deltaAdapter) Generate(cfg palette.Config) ([]byte, error) {
 // Output format: [delta "<name>"] section with tab-indented key-value pairs.
 // Semicolon comments before the section header.
 // Trailing newline after the last key-value pair.
-var deltaTmpl = template.Must(template.New("delta").Parse("; {{.Theme.Name}} theme for delta\n; Add to .gitconfig or include via [include] path = <this-file>\n[delta \"{{.Theme.Name}}\"]\n\tlight = {{if eq .Theme.Variant \"light\"}}true{{else}}false{{end}}\n\tsyntax-theme = {{if eq .Theme.Variant \"light\"}}GitHub{{else}}Nord{{end}}\n\tnavigate = true\n\tkeep-plus-minus-markers = true\n\tfile-decoration-style = \"none\"\n\tfile-style = \"{{.Palette.Color4}} bold\"\n\tminus-style = \"{{.Palette.Color1}}\"\n\tminus-emph-style = \"{{.Palette.Color1}} bold\"\n\tplus-style = \"{{.Palette.Color2}}\"\n\tplus-emph-style = \"{{.Palette.Color2}} bold\"\n\thunk-header-style = \"{{.Palette.UI.Accent}} bold\"\n\tline-numbers = true\n\tline-numbers-minus-style = \"{{.Palette.Color1}}\"\n\tline-numbers-plus-style = \"{{.Palette.Color2}}\"\n\tline-numbers-left-style = \"{{.Palette.Color8}}\"\n\tline-numbers-right-style = \"{{.Palette.Color8}}\"\n\tline-numbers-zero-style = \"{{.Palette.UI.Dimmed}}\"\n\tzero-style = \"syntax\"\n\twhitespace-error-style = \"reverse {{.Palette.Color5}}\"\n"))
+var deltaTmpl = template.Must(template.New("delta").Parse("; {{.Theme.Name}} theme for delta\n; Add to .gitconfig or include via [include] path = <this-file>\n[delta \"{{.Theme.Name}}\"]\n\tlight = {{if eq .Theme.Variant \"light\"}}true{{else}}false{{end}}\n\tsyntax-theme = {{if eq .Theme.Variant \"light\"}}GitHub{{else}}Nord{{end}}\n\tnavigate = true\n\tkeep-plus-minus-markers = true\n\tfile-decoration-style = \"none\"\n\tfile-style = \"{{.Palette.Color4}} bold\"\n\tminus-style = \"{{.Palette.Color1}} {{.Palette.UI.DiffDelBG}}\"\n\tminus-emph-style = \"{{.Palette.Color1}} {{.Palette.UI.DiffDelBG}} bold\"\n\tplus-style = \"{{.Palette.Color2}} {{.Palette.UI.DiffAddBG}}\"\n\tplus-emph-style = \"{{.Palette.Color2}} {{.Palette.UI.DiffAddBG}} bold\"\n\thunk-header-style = \"{{.Palette.UI.Accent}} bold\"\n\tline-numbers = true\n\tline-numbers-minus-style = \"{{.Palette.Color1}}\"\n\tline-numbers-plus-style = \"{{.Palette.Color2}}\"\n\tline-numbers-left-style = \"{{.Palette.Color8}}\"\n\tline-numbers-right-style = \"{{.Palette.Color8}}\"\n\tline-numbers-zero-style = \"{{.Palette.UI.Dimmed}}\"\n\tzero-style = \"syntax\"\n\twhitespace-error-style = \"reverse {{.Palette.Color5}}\"\n"))
@@ -21,6 +21,8 @@ func (f *fzfAdapter) DirName() string                  { return "fzf" }
 func (f *fzfAdapter) FileName(themeName string) string { return themeName + ".zsh" }
 
 func (f *fzfAdapter) Generate(cfg palette.Config) ([]byte, error) {
+	// fzf's --color takes #rrggbb only.
+	cfg = cfg.Opaque()
 	var buf bytes.Buffer
 	if err := fzfTmpl.Execute(&buf, cfg); err != nil {
 		return nil, err
@@ -31,7 +33,7 @@ func (f *fzfAdapter) Generate(cfg palette.Config) ([]byte, error) {
 // fzfTmpl renders the fzf color configuration for zsh.
 //
 // Output format: export FZF_DEFAULT_OPTS appended with --color flags.
-// 18 color parameters across 7 --color lines.
+// 19 color parameters across 7 --color lines.
 // Trailing newline after closing single quote.
 var fzfTmpl = template.Must(template.New("fzf").Parse(
 	`#!/bin/zsh
@@ -42,8 +44,8 @@ export FZF_DEFAULT_OPTS=$FZF_DEFAULT_OPTS'
   --color=fg+:{{if eq .Theme.Variant "light"}}{{.Palette.SelectionFG}}{{else}}{{.Palette.Color15}}{{end}},bg+:{{.Palette.SelectionBG}},hl+:{{.Palette.UI.Accent}}
   --color=info:{{.Palette.UI.Info}},prompt:{{.Palette.Color4}},pointer:{{.Palette.UI.Accent}}
   --color=marker:{{.Palette.UI.Success}},spinner:{{.Palette.Color4}},header:{{.Palette.UI.Dimmed}}
-  --color=border:{{.Palette.UI.Border}},gutter:{{.Palette.BG}}
+  --color=border:{{.Palette.UI.BorderInactive}},gutter:{{.Palette.BG}},scrollbar:{{.Palette.UI.Overlay}}
   --color=query:{{.Palette.FG}},disabled:{{.Palette.UI.Dimmed}}
-  --color=preview-fg:{{.Palette.FG}},preview-bg:{{if eq .Theme.Variant "light"}}{{.Palette.BG}}{{else}}{{.Palette.UI.Border}}{{end}}
+  --color=preview-fg:{{.Palette.FG}},preview-bg:{{.Palette.UI.Surface0}}
 '
 `))
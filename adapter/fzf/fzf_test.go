@@ -16,18 +16,13 @@ func TestGenerate_OracleBleu(t *testing.T) {
 		t.Fatalf("Load bleu.toml: %v", err)
 	}
 
-	// fzf uses a per-adapter palette override; simulate what generate.go does.
-	override, ok := cfg.Adapters["fzf"]
-	if !ok {
+	// fzf uses a per-adapter palette override layered over the base palette.
+	if _, ok := cfg.Adapters["fzf"]; !ok {
 		t.Fatal("expected fzf adapter override in bleu.toml")
 	}
-	adapterCfg := palette.Config{
-		Theme:   cfg.Theme,
-		Palette: override.Palette,
-	}
-	adapterCfg.ApplyDefaults()
-	if err := adapterCfg.Validate(); err != nil {
-		t.Fatalf("fzf override validation: %v", err)
+	adapterCfg, err := cfg.ForAdapter("fzf")
+	if err != nil {
+		t.Fatalf("fzf override: %v", err)
 	}
 
 	fzf := adapter.ByName([]string{"fzf"})
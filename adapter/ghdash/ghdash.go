@@ -0,0 +1,56 @@
+// Package ghdash generates the theme block of a gh-dash config.
+//
+// The output is only the theme.colors section of ~/.config/gh-dash/config.yml,
+// not a full config: the-themer's switch command merges it into the user's
+// config in place, leaving sections, layout, keybindings and comments alone.
+package ghdash
+
+import (
+	"bytes"
+	"text/template"
+
+	"github.com/kylesnowschwartz/the-themer/adapter"
+	"github.com/kylesnowschwartz/the-themer/palette"
+)
+
+func init() {
+	adapter.Register(&ghDashAdapter{})
+}
+
+type ghDashAdapter struct{}
+
+func (g *ghDashAdapter) Name() string                     { return "gh-dash" }
+func (g *ghDashAdapter) DirName() string                  { return "gh-dash" }
+func (g *ghDashAdapter) FileName(themeName string) string { return themeName + ".yml" }
+
+func (g *ghDashAdapter) Generate(cfg palette.Config) ([]byte, error) {
+	// gh-dash (lipgloss) takes #RRGGBB only.
+	cfg = cfg.Opaque()
+	var buf bytes.Buffer
+	if err := ghDashTmpl.Execute(&buf, cfg); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// ghDashTmpl renders theme.colors with gh-dash's own 4-space indentation.
+// Text is blue with magenta secondary, like the hand-made configs this
+// replaces; faint borders sit one surface step off bg.
+var ghDashTmpl = template.Must(template.New("gh-dash").Parse(`# {{.Theme.Name}} theme for gh-dash
+# Merged into ~/.config/gh-dash/config.yml by the-themer switch
+theme:
+    colors:
+        text:
+            primary: "{{.Palette.Color4}}"
+            secondary: "{{.Palette.Color5}}"
+            inverted: "{{.Palette.BG}}"
+            faint: "{{.Palette.UI.Dimmed}}"
+            warning: "{{.Palette.UI.Warning}}"
+            success: "{{.Palette.UI.Success}}"
+        background:
+            selected: "{{.Palette.SelectionBG}}"
+        border:
+            primary: "{{.Palette.Color6}}"
+            secondary: "{{.Palette.Color8}}"
+            faint: "{{.Palette.UI.Surface0}}"
+`))
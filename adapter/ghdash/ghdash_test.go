@@ -0,0 +1,57 @@
+package ghdash_test
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/kylesnowschwartz/the-themer/adapter"
+	_ "github.com/kylesnowschwartz/the-themer/adapter/ghdash"
+	"github.com/kylesnowschwartz/the-themer/palette"
+)
+
+func TestGenerate_OracleBleu(t *testing.T) {
+	cfg, err := palette.Load("../../testdata/bleu.toml")
+	if err != nil {
+		t.Fatalf("Load bleu.toml: %v", err)
+	}
+
+	ghDash := adapter.ByName([]string{"gh-dash"})
+	if len(ghDash) != 1 {
+		t.Fatalf("expected 1 gh-dash adapter, got %d", len(ghDash))
+	}
+
+	got, err := ghDash[0].Generate(cfg)
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+
+	expected, err := os.ReadFile("../../testdata/expected/gh-dash/bleu.yml")
+	if err != nil {
+		t.Fatalf("reading expected fixture: %v", err)
+	}
+
+	if !bytes.Equal(got, expected) {
+		t.Errorf("output differs from oracle\n--- got ---\n%s\n--- want ---\n%s", got, expected)
+	}
+}
+
+func TestAdapterRegistration(t *testing.T) {
+	all := adapter.All()
+
+	found := false
+	for _, a := range all {
+		if a.Name() == "gh-dash" {
+			found = true
+			if a.DirName() != "gh-dash" {
+				t.Errorf("DirName: got %q, want %q", a.DirName(), "gh-dash")
+			}
+			if a.FileName("bleu") != "bleu.yml" {
+				t.Errorf("FileName: got %q, want %q", a.FileName("bleu"), "bleu.yml")
+			}
+		}
+	}
+	if !found {
+		t.Fatal("gh-dash adapter not registered")
+	}
+}
@@ -21,6 +21,9 @@ func (g *ghosttyAdapter) DirName() string                  { return "ghostty" }
 func (g *ghosttyAdapter) FileName(themeName string) string { return themeName + ".ghostty" }
 
 func (g *ghosttyAdapter) Generate(cfg palette.Config) ([]byte, error) {
+	// Ghostty colors are RGB only (translucency is the separate
+	// background-opacity setting), so translucent colors are flattened.
+	cfg = cfg.Opaque()
 	var buf bytes.Buffer
 	if err := ghosttyTmpl.Execute(&buf, cfg); err != nil {
 		return nil, err
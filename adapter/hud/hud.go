@@ -25,6 +25,9 @@ func (h *hudAdapter) DirName() string                  { return "hud" }
 func (h *hudAdapter) FileName(themeName string) string { return themeName + ".toml" }
 
 func (h *hudAdapter) Generate(cfg palette.Config) ([]byte, error) {
+	// Widget colors are drawn as terminal truecolor escapes, which have no
+	// alpha.
+	cfg = cfg.Opaque()
 	var buf bytes.Buffer
 	if err := hudTmpl.Execute(&buf, cfg); err != nil {
 		return nil, err
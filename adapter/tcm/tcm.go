@@ -45,27 +45,27 @@ func (a *tcmAdapter) FileName(themeName string) string { return themeName + ".js
 // Field order is significant: encoding/json emits in declaration order, and
 // the oracle test asserts byte equality.
 type tcmPalette struct {
-	Text     string `json:"text"`
-	Subtext0 string `json:"subtext0"`
-	Subtext1 string `json:"subtext1"`
-	Overlay0 string `json:"overlay0"`
-	Overlay1 string `json:"overlay1"`
-	Blue     string `json:"blue"`
-	Lavender string `json:"lavender"`
-	Pink     string `json:"pink"`
-	Mauve    string `json:"mauve"`
-	Yellow   string `json:"yellow"`
-	Green    string `json:"green"`
-	Red      string `json:"red"`
-	Peach    string `json:"peach"`
-	Teal     string `json:"teal"`
-	Sky      string `json:"sky"`
-	Surface0 string `json:"surface0"`
-	Surface1 string `json:"surface1"`
-	Surface2 string `json:"surface2"`
-	Base     string `json:"base"`
-	Mantle   string `json:"mantle"`
-	Crust    string `json:"crust"`
+	Text     palette.Color `json:"text"`
+	Subtext0 palette.Color `json:"subtext0"`
+	Subtext1 palette.Color `json:"subtext1"`
+	Overlay0 palette.Color `json:"overlay0"`
+	Overlay1 palette.Color `json:"overlay1"`
+	Blue     palette.Color `json:"blue"`
+	Lavender palette.Color `json:"lavender"`
+	Pink     palette.Color `json:"pink"`
+	Mauve    palette.Color `json:"mauve"`
+	Yellow   palette.Color `json:"yellow"`
+	Green    palette.Color `json:"green"`
+	Red      palette.Color `json:"red"`
+	Peach    palette.Color `json:"peach"`
+	Teal     palette.Color `json:"teal"`
+	Sky      palette.Color `json:"sky"`
+	Surface0 palette.Color `json:"surface0"`
+	Surface1 palette.Color `json:"surface1"`
+	Surface2 palette.Color `json:"surface2"`
+	Base     palette.Color `json:"base"`
+	Mantle   palette.Color `json:"mantle"`
+	Crust    palette.Color `json:"crust"`
 }
 
 type tcmTheme struct {
@@ -85,6 +85,8 @@ type tcmTheme struct {
 // original contract.
 
 func (a *tcmAdapter) Generate(cfg palette.Config) ([]byte, error) {
+	// Colors are emitted as is, alpha included: OpenTUI parses #RRGGBBAA
+	// and blends translucent surfaces itself.
 	p := cfg.Palette // post-ApplyDefaults: every UI/Selection field is populated.
 
 	// Spec fallback for "blue" diverges from ApplyDefaults:
@@ -102,8 +104,8 @@ func (a *tcmAdapter) Generate(cfg palette.Config) ([]byte, error) {
 		Palette: tcmPalette{
 			Text:     p.FG,
 			Subtext0: p.UI.Dimmed,
-			Subtext1: p.UI.Dimmed,
-			Overlay0: p.Color8,
+			Subtext1: p.UI.Hint,
+			Overlay0: p.UI.Overlay,
 			Overlay1: p.Color8,
 			Blue:     blue,
 			Lavender: p.Color5,
@@ -115,9 +117,9 @@ func (a *tcmAdapter) Generate(cfg palette.Config) ([]byte, error) {
 			Peach:    p.Color3,
 			Teal:     p.Color6,
 			Sky:      p.Color6,
-			Surface0: p.SelectionBG,
-			Surface1: p.SelectionBG,
-			Surface2: p.Color8,
+			Surface0: p.UI.Surface0,
+			Surface1: p.UI.Surface1,
+			Surface2: p.UI.Surface2,
 			Base:     p.BG,
 			Mantle:   p.BG,
 			Crust:    p.BG,
@@ -56,29 +56,23 @@ func TestAdapterRegistration(t *testing.T) {
 	}
 }
 
-// TestGenerate_AdapterOverride ensures the [adapters.tcm.palette] override
-// path goes through ApplyDefaults + Validate just like the input palette.
-// We synthesize a Config with only tcm tokens overridden and verify the
-// generated JSON reflects those overrides.
+// TestGenerate_AdapterOverride ensures a partial [adapters.tcm.palette]
+// override is layered over the base palette: only the overridden tokens
+// change, everything else cascades.
 func TestGenerate_AdapterOverride(t *testing.T) {
 	cfg, err := palette.Load("../../testdata/bleu.toml")
 	if err != nil {
 		t.Fatalf("Load bleu.toml: %v", err)
 	}
 
-	// Synthesize an override that only changes a few tokens. The full
-	// palette is required (Validate enforces all 16 ANSI + bg/fg).
-	override := cfg.Palette
+	var override palette.PaletteColors
 	override.UI.Accent = "#123456" // changes tcm "blue"
 	override.UI.Warning = "#abcdef"
+	cfg.Adapters["tcm"] = palette.AdapterConfig{Palette: override}
 
-	adapterCfg := palette.Config{
-		Theme:   cfg.Theme,
-		Palette: override,
-	}
-	adapterCfg.ApplyDefaults()
-	if err := adapterCfg.Validate(); err != nil {
-		t.Fatalf("override validation: %v", err)
+	adapterCfg, err := cfg.ForAdapter("tcm")
+	if err != nil {
+		t.Fatalf("ForAdapter: %v", err)
 	}
 
 	tcms := adapter.ByName([]string{"tcm"})
@@ -93,4 +87,25 @@ func TestGenerate_AdapterOverride(t *testing.T) {
 	if !bytes.Contains(got, []byte(`"yellow": "#abcdef"`)) {
 		t.Errorf("override warning did not propagate to yellow token\n%s", got)
 	}
+	if !bytes.Contains(got, []byte(`"text": "#e0ecf4"`)) {
+		t.Errorf("base fg did not cascade to text token\n%s", got)
+	}
+}
+
+// TestGenerate_Alpha checks that translucent colors are passed through,
+// since OpenTUI blends #RRGGBBAA itself.
+func TestGenerate_Alpha(t *testing.T) {
+	cfg, err := palette.Load("../../testdata/bleu.toml")
+	if err != nil {
+		t.Fatalf("Load bleu.toml: %v", err)
+	}
+	cfg.Palette.UI.Surface0 = "#3344ff80"
+
+	got, err := adapter.ByName([]string{"tcm"})[0].Generate(cfg)
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	if !bytes.Contains(got, []byte(`"surface0": "#3344ff80"`)) {
+		t.Errorf("surface0 lost its alpha:\n%s", got)
+	}
 }
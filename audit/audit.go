@@ -0,0 +1,632 @@
+// Package audit checks a palette for perceptual contrast (APCA-W3), hue
+// identity (OKLCH), normal/bright pair coherence, and cross-family
+// distinguishability.
+//
+// It is a port of the original scripts/contrast-audit.py and reproduces its
+// thresholds, classifications, and report layout exactly.
+package audit
+
+import (
+	"sort"
+
+	"github.com/kylesnowschwartz/the-themer/palette"
+	"github.com/kylesnowschwartz/the-themer/palette/color"
+)
+
+// Severity grades a single audit finding.
+type Severity string
+
+const (
+	Fail   Severity = "FAIL"
+	Warn   Severity = "WARN"
+	Pass   Severity = "PASS"
+	Info   Severity = "INFO"
+	Exempt Severity = "EXEMPT"
+)
+
+// ANSINames maps ANSI slot indexes to their conventional names.
+var ANSINames = [16]string{
+	"black", "red", "green", "yellow", "blue", "magenta", "cyan", "white",
+	"br black", "br red", "br green", "br yellow", "br blue", "br magenta", "br cyan", "br white",
+}
+
+// ansiPairs lists the normal/bright pairs sharing a hue family.
+var ansiPairs = [][2]int{{1, 9}, {2, 10}, {3, 11}, {4, 12}, {5, 13}, {6, 14}}
+
+// Chromatic slots (achromatic 0/7/8/15 excluded).
+var (
+	chromaticNormals = []int{1, 2, 3, 4, 5, 6}
+	chromaticBrights = []int{9, 10, 11, 12, 13, 14}
+)
+
+// HueFamily is a named hue region defined by a centroid angle and the
+// maximum angular distance a color may sit from it and still read as
+// belonging to the family.
+type HueFamily struct {
+	Name        string
+	Centroid    float64 // degrees
+	MaxDistance float64 // degrees
+	NormalSlot  int
+	BrightSlot  int
+}
+
+// HueFamilies are the six chromatic ANSI families in OKLCH hue space.
+var HueFamilies = []HueFamily{
+	{"red", 30, 35, 1, 9},
+	{"green", 145, 35, 2, 10},
+	{"yellow", 82, 35, 3, 11},
+	{"blue", 260, 30, 4, 12},
+	{"magenta", 330, 45, 5, 13},
+	{"cyan", 205, 35, 6, 14},
+}
+
+// FamilyForSlot returns the hue family an ANSI slot belongs to, or false
+// for the achromatic slots.
+func FamilyForSlot(slot int) (HueFamily, bool) {
+	for _, f := range HueFamilies {
+		if f.NormalSlot == slot || f.BrightSlot == slot {
+			return f, true
+		}
+	}
+	return HueFamily{}, false
+}
+
+// NearestFamily returns the hue family closest to hue and its distance.
+func NearestFamily(hue float64) (HueFamily, float64) {
+	best := HueFamilies[0]
+	bestDist := color.HueDistance(hue, best.Centroid)
+	for _, f := range HueFamilies[1:] {
+		if d := color.HueDistance(hue, f.Centroid); d < bestDist {
+			best, bestDist = f, d
+		}
+	}
+	return best, bestDist
+}
+
+// Thresholds holds the |Lc| floors for a contrast category. A zero WarnBelow
+// means the category has no warn tier.
+type Thresholds struct {
+	FailBelow float64
+	WarnBelow float64
+}
+
+// Contrast categories.
+const (
+	CategoryBody            = "body"
+	CategoryComment         = "comment"
+	CategoryChromaticNormal = "chromatic-normal"
+	CategoryChromaticBright = "chromatic-bright"
+	CategoryUIElement       = "ui-element"
+	CategoryUIStructural    = "ui-structural"
+	CategoryBGMatch         = "bg-match"
+)
+
+// ContrastThresholds maps each category to its APCA floors.
+var ContrastThresholds = map[string]Thresholds{
+	CategoryBody:            {FailBelow: 75, WarnBelow: 90},
+	CategoryComment:         {FailBelow: 30, WarnBelow: 45},
+	CategoryChromaticNormal: {FailBelow: 30, WarnBelow: 45},
+	CategoryChromaticBright: {FailBelow: 45, WarnBelow: 60},
+	CategoryUIElement:       {FailBelow: 45, WarnBelow: 60},
+	CategoryUIStructural:    {FailBelow: 15},
+	CategoryBGMatch:         {}, // exempt
+}
+
+// ClassifySlot returns the contrast category for an ANSI slot in a theme of
+// the given variant.
+func ClassifySlot(slot int, variant string) string {
+	dark := variant == "dark"
+
+	switch {
+	// bg-matching slots are exempt.
+	case dark && slot == 0, !dark && slot == 15:
+		return CategoryBGMatch
+	// Strong anchors: the text end of the achromatic pair.
+	case dark && slot == 15, !dark && slot == 0:
+		return CategoryBody
+	// Comment and secondary text share a tier.
+	case slot == 7, slot == 8:
+		return CategoryComment
+	}
+	for _, s := range chromaticNormals {
+		if s == slot {
+			return CategoryChromaticNormal
+		}
+	}
+	for _, s := range chromaticBrights {
+		if s == slot {
+			return CategoryChromaticBright
+		}
+	}
+	return "unknown"
+}
+
+// classifyUISlot returns the contrast category for a UI role.
+func classifyUISlot(name string) string {
+	if name == "border" || name == "dimmed" {
+		return CategoryUIStructural
+	}
+	return CategoryUIElement
+}
+
+// Evaluate grades an Lc value against a category's thresholds.
+func Evaluate(lc float64, category string) Severity {
+	if category == CategoryBGMatch {
+		return Exempt
+	}
+	t := ContrastThresholds[category]
+	abs := lc
+	if abs < 0 {
+		abs = -abs
+	}
+	if abs < t.FailBelow {
+		return Fail
+	}
+	if t.WarnBelow != 0 && abs < t.WarnBelow {
+		return Warn
+	}
+	return Pass
+}
+
+// ContrastResult is the APCA contrast of one color against a background.
+type ContrastResult struct {
+	Slot     string
+	Hex      palette.Color
+	Lc       float64
+	Category string
+	Severity Severity
+}
+
+// OKLCHResult is the OKLCH decomposition of one palette slot.
+type OKLCHResult struct {
+	Slot  string
+	Hex   palette.Color
+	OKLCH color.OKLCH
+}
+
+// HueResult reports whether a chromatic slot lands in its expected family.
+type HueResult struct {
+	Slot           int
+	Hex            palette.Color
+	ExpectedFamily string
+	ActualFamily   string
+	Distance       float64
+	MaxDistance    float64
+	Severity       Severity
+	Note           string
+}
+
+// PairResult reports the coherence of a normal/bright pair.
+type PairResult struct {
+	Family     string
+	NormalSlot int
+	BrightSlot int
+	DeltaL     float64
+	DeltaC     float64
+	HueDrift   float64
+	Severity   Severity
+	Note       string
+}
+
+// DistinguishResult reports a pair of chromatic slots that sit close
+// together in OKLab.
+type DistinguishResult struct {
+	SlotA    int
+	SlotB    int
+	DeltaE   float64
+	HueDist  float64
+	Severity Severity
+	Note     string
+}
+
+// Sections holds the per-palette audit sections that are also run on each
+// adapter override.
+type Sections struct {
+	Contrast    []ContrastResult
+	OKLCH       []OKLCHResult
+	Hue         []HueResult
+	Pairs       []PairResult
+	Distinguish []DistinguishResult
+}
+
+// OverrideReport is the audit of one [adapters.<name>.palette] override.
+type OverrideReport struct {
+	Adapter string
+	Sections
+}
+
+// Report is the full audit of a palette.
+type Report struct {
+	Name    string
+	Variant string
+	BG      palette.Color
+
+	Sections
+	CrossContext []ContrastResult
+	Overrides    []OverrideReport
+}
+
+// variantOf returns the theme variant, defaulting to "dark" when unset.
+func variantOf(cfg palette.Config) string {
+	if cfg.Theme.Variant == "" {
+		return "dark"
+	}
+	return cfg.Theme.Variant
+}
+
+// Run audits a loaded (defaults-applied) palette config. Each adapter
+// override is audited as the adapter sees it, via Config.ForAdapter.
+// Translucent colors are audited as drawn: composited over bg.
+func Run(cfg palette.Config) (Report, error) {
+	cfg = cfg.Opaque()
+	variant := variantOf(cfg)
+	r := Report{
+		Name:         cfg.Theme.Name,
+		Variant:      variant,
+		BG:           cfg.Palette.BG,
+		Sections:     auditSections(cfg.Palette, variant),
+		CrossContext: auditCrossContext(cfg.Palette),
+	}
+
+	names := make([]string, 0, len(cfg.Adapters))
+	for name := range cfg.Adapters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if cfg.Adapters[name].Palette == (palette.PaletteColors{}) {
+			continue
+		}
+		oc, err := cfg.ForAdapter(name)
+		if err != nil {
+			return Report{}, err
+		}
+		r.Overrides = append(r.Overrides, OverrideReport{
+			Adapter:  name,
+			Sections: auditSections(oc.Opaque().Palette, variant),
+		})
+	}
+	return r, nil
+}
+
+func auditSections(p palette.PaletteColors, variant string) Sections {
+	return Sections{
+		Contrast:    auditContrast(p, variant),
+		OKLCH:       auditOKLCH(p),
+		Hue:         auditHueIdentity(p),
+		Pairs:       auditPairCoherence(p, variant),
+		Distinguish: auditDistinguishability(p),
+	}
+}
+
+// Failures counts FAIL results that contribute to the exit status. Under
+// strict, WARN results in the main and cross-context contrast sections also
+// count.
+func (r Report) Failures(strict bool) int {
+	n := countSeverity(r.Contrast, Fail) + countSeverity(r.CrossContext, Fail)
+	for _, o := range r.Overrides {
+		n += countSeverity(o.Contrast, Fail)
+	}
+	if strict {
+		n += r.Warnings()
+	}
+	return n
+}
+
+// Warnings counts WARN results in the main and cross-context contrast sections.
+func (r Report) Warnings() int {
+	return countSeverity(r.Contrast, Warn) + countSeverity(r.CrossContext, Warn)
+}
+
+func countSeverity(results []ContrastResult, sev Severity) int {
+	n := 0
+	for _, r := range results {
+		if r.Severity == sev {
+			n++
+		}
+	}
+	return n
+}
+
+func lc(fg, bg palette.Color) float64 {
+	return color.APCA(fg.RGB(), bg.RGB())
+}
+
+// auditContrast checks every color slot against bg using APCA.
+func auditContrast(p palette.PaletteColors, variant string) []ContrastResult {
+	var results []ContrastResult
+
+	// fg and selection_fg are body text.
+	for _, s := range []struct {
+		slot string
+		hex  palette.Color
+	}{{"fg", p.FG}, {"selection_fg", p.SelectionFG}} {
+		if s.hex == "" {
+			continue
+		}
+		v := lc(s.hex, p.BG)
+		results = append(results, ContrastResult{s.slot, s.hex, v, CategoryBody, Evaluate(v, CategoryBody)})
+	}
+
+	for i, hex := range p.Colors() {
+		if hex == "" {
+			continue
+		}
+		cat := ClassifySlot(i, variant)
+		v := lc(hex, p.BG)
+		results = append(results, ContrastResult{slotName(i), hex, v, cat, Evaluate(v, cat)})
+	}
+
+	uiSlots := []struct {
+		name string
+		hex  palette.Color
+	}{
+		{"accent", p.UI.Accent},
+		{"success", p.UI.Success},
+		{"warning", p.UI.Warning},
+		{"error", p.UI.Error},
+		{"info", p.UI.Info},
+		{"border", p.UI.Border},
+		{"dimmed", p.UI.Dimmed},
+	}
+	for _, s := range uiSlots {
+		if s.hex == "" {
+			continue
+		}
+		cat := classifyUISlot(s.name)
+		v := lc(s.hex, p.BG)
+		results = append(results, ContrastResult{"ui." + s.name, s.hex, v, cat, Evaluate(v, cat)})
+	}
+	return results
+}
+
+// auditOKLCH decomposes bg, fg, and the ANSI 16 into OKLCH.
+func auditOKLCH(p palette.PaletteColors) []OKLCHResult {
+	var results []OKLCHResult
+	for _, s := range []struct {
+		slot string
+		hex  palette.Color
+	}{{"bg", p.BG}, {"fg", p.FG}} {
+		if s.hex != "" {
+			results = append(results, OKLCHResult{s.slot, s.hex, s.hex.OKLCH()})
+		}
+	}
+	for i, hex := range p.Colors() {
+		if hex != "" {
+			results = append(results, OKLCHResult{slotName(i), hex, hex.OKLCH()})
+		}
+	}
+	return results
+}
+
+// auditHueIdentity checks that chromatic slots land in their expected family.
+func auditHueIdentity(p palette.PaletteColors) []HueResult {
+	colors := p.Colors()
+	var results []HueResult
+
+	for _, slot := range append(append([]int{}, chromaticNormals...), chromaticBrights...) {
+		hex := colors[slot]
+		if hex == "" {
+			continue
+		}
+		o := hex.OKLCH()
+		expected, _ := FamilyForSlot(slot)
+
+		if o.IsAchromatic() {
+			results = append(results, HueResult{
+				Slot:           slot,
+				Hex:            hex,
+				ExpectedFamily: expected.Name,
+				ActualFamily:   "achromatic",
+				MaxDistance:    expected.MaxDistance,
+				Severity:       Warn,
+				Note:           fmtf("C=%.3f — achromatic in chromatic slot", o.C),
+			})
+			continue
+		}
+
+		dist := color.HueDistance(o.H, expected.Centroid)
+		actual, _ := NearestFamily(o.H)
+
+		var sev Severity
+		var note string
+		switch {
+		case dist <= expected.MaxDistance:
+			sev = Pass
+		case o.HueUnreliable():
+			sev = Info
+			note = fmtf("C=%.3f — low chroma, hue unreliable", o.C)
+		default:
+			sev = Warn
+			note = fmtf("H=%.0f° — reads as %s", o.H, actual.Name)
+		}
+
+		results = append(results, HueResult{
+			Slot:           slot,
+			Hex:            hex,
+			ExpectedFamily: expected.Name,
+			ActualFamily:   actual.Name,
+			Distance:       dist,
+			MaxDistance:    expected.MaxDistance,
+			Severity:       sev,
+			Note:           note,
+		})
+	}
+	return results
+}
+
+// auditPairCoherence checks that normal/bright pairs have coherent L, C, and hue.
+func auditPairCoherence(p palette.PaletteColors, variant string) []PairResult {
+	colors := p.Colors()
+	var results []PairResult
+
+	for _, pair := range ansiPairs {
+		normalHex, brightHex := colors[pair[0]], colors[pair[1]]
+		if normalHex == "" || brightHex == "" {
+			continue
+		}
+		n, b := normalHex.OKLCH(), brightHex.OKLCH()
+		fam, _ := FamilyForSlot(pair[0])
+
+		deltaL := b.L - n.L
+		deltaC := b.C - n.C
+		drift := color.HueDistance(n.H, b.H)
+
+		var notes []string
+		sev := Pass
+
+		// Dark themes expect brights to be lighter. Light themes tolerate
+		// lighter brights unless the jump is large.
+		if variant == "dark" && deltaL < -0.05 {
+			notes = append(notes, "bright is darker than normal")
+			sev = Warn
+		} else if variant == "light" && deltaL > 0.15 {
+			notes = append(notes, "bright significantly lighter than normal")
+			sev = Warn
+		}
+
+		if !n.IsAchromatic() && !b.IsAchromatic() && drift > 30 {
+			notes = append(notes, fmtf("hue drift %.0f° between normal/bright", drift))
+			sev = Warn
+		}
+
+		results = append(results, PairResult{
+			Family:     fam.Name,
+			NormalSlot: pair[0],
+			BrightSlot: pair[1],
+			DeltaL:     deltaL,
+			DeltaC:     deltaC,
+			HueDrift:   drift,
+			Severity:   sev,
+			Note:       joinNotes(notes),
+		})
+	}
+	return results
+}
+
+// auditDistinguishability checks pairwise deltaE_OK among chromatic normals
+// and among chromatic brights. Only non-passing pairs are reported.
+func auditDistinguishability(p palette.PaletteColors) []DistinguishResult {
+	colors := p.Colors()
+	var results []DistinguishResult
+
+	for _, slots := range [][]int{chromaticNormals, chromaticBrights} {
+		var present []int
+		for _, s := range slots {
+			if colors[s] != "" {
+				present = append(present, s)
+			}
+		}
+
+		for i, sa := range present {
+			for _, sb := range present[i+1:] {
+				a, b := colors[sa].RGB(), colors[sb].RGB()
+				de := color.DeltaEOK(a, b)
+				hd := color.HueDistance(a.OKLCH().H, b.OKLCH().H)
+
+				var sev Severity
+				var note string
+				switch {
+				case de < 0.04:
+					sev, note = Fail, "likely confusable"
+				case de < 0.07 && hd > 30:
+					sev, note = Info, "metrically close but hue-distinct"
+				case de < 0.07:
+					sev, note = Warn, "may be confused in some contexts"
+				default:
+					continue
+				}
+				results = append(results, DistinguishResult{sa, sb, de, hd, sev, note})
+			}
+		}
+	}
+	return results
+}
+
+// auditCrossContext checks contrast in non-standard contexts: selection,
+// block cursor, and UI/syntax colors on bg.
+func auditCrossContext(p palette.PaletteColors) []ContrastResult {
+	var results []ContrastResult
+
+	pairs := []struct {
+		label    string
+		fg, bg   palette.Color
+		category string
+	}{
+		{"selection_fg on selection_bg", p.SelectionFG, p.SelectionBG, CategoryBody},
+		// Cursor text is a single transient glyph, not body text.
+		{"cursor_text on cursor (block)", p.CursorText, p.Cursor, CategoryUIElement},
+		{"cursor on bg", p.Cursor, p.BG, CategoryUIElement},
+		{"ui.accent on bg", p.UI.Accent, p.BG, CategoryUIElement},
+		{"ui.success on bg", p.UI.Success, p.BG, CategoryUIElement},
+		{"ui.warning on bg", p.UI.Warning, p.BG, CategoryUIElement},
+		{"ui.error on bg", p.UI.Error, p.BG, CategoryUIElement},
+		{"ui.info on bg", p.UI.Info, p.BG, CategoryUIElement},
+		{"syntax.number on bg", p.Syntax.Number, p.BG, CategoryChromaticNormal},
+		{"syntax.error on bg", p.Syntax.Error, p.BG, CategoryChromaticNormal},
+	}
+	for _, s := range pairs {
+		if s.fg == "" || s.bg == "" {
+			continue
+		}
+		v := lc(s.fg, s.bg)
+		results = append(results, ContrastResult{s.label, s.fg, v, s.category, Evaluate(v, s.category)})
+	}
+	return results
+}
+
+// ComparisonDelta is the change in one slot's contrast between two palettes.
+type ComparisonDelta struct {
+	Slot       string
+	LcA, LcB   float64
+	Change     float64
+	SevA, SevB Severity
+	Regression bool
+}
+
+// severityRank orders severities for regression detection. EXEMPT and INFO
+// never count as regressions.
+var severityRank = map[Severity]int{Pass: 0, Warn: 1, Fail: 2, Exempt: -1, Info: -1}
+
+// Compare reports per-slot contrast changes from baseline a to palette b.
+// Like Run, it audits both palettes with translucent colors composited over
+// their bg.
+func Compare(a, b palette.Config) []ComparisonDelta {
+	a, b = a.Opaque(), b.Opaque()
+	ra := map[string]ContrastResult{}
+	for _, r := range auditContrast(a.Palette, variantOf(a)) {
+		ra[r.Slot] = r
+	}
+	rb := map[string]ContrastResult{}
+	for _, r := range auditContrast(b.Palette, variantOf(b)) {
+		rb[r.Slot] = r
+	}
+
+	var slots []string
+	for s := range ra {
+		if _, ok := rb[s]; ok {
+			slots = append(slots, s)
+		}
+	}
+	sort.Strings(slots)
+
+	deltas := make([]ComparisonDelta, 0, len(slots))
+	for _, s := range slots {
+		x, y := ra[s], rb[s]
+		deltas = append(deltas, ComparisonDelta{
+			Slot:       s,
+			LcA:        x.Lc,
+			LcB:        y.Lc,
+			Change:     y.Lc - x.Lc,
+			SevA:       x.Severity,
+			SevB:       y.Severity,
+			Regression: severityRank[y.Severity] > severityRank[x.Severity],
+		})
+	}
+	return deltas
+}
+
+func slotName(i int) string {
+	return fmtf("color%d", i)
+}
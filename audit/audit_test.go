@@ -0,0 +1,203 @@
+package audit_test
+
+import (
+	"bytes"
+	"math"
+	"os"
+	"testing"
+
+	"github.com/kylesnowschwartz/the-themer/audit"
+	"github.com/kylesnowschwartz/the-themer/palette"
+)
+
+// bundledThemes are the warehouse themes whose reports are pinned to the
+// output of scripts/contrast-audit.py ("just audit-fixtures" regenerates
+// them).
+var bundledThemes = []string{
+	"belafonte-day",
+	"catppuccin-latte",
+	"cobalt-next-neon-v2",
+	"dayfox",
+	"tekapo-sunset-dark",
+	"tekapo-sunset-light",
+}
+
+func loadTheme(t *testing.T, name string) palette.Config {
+	t.Helper()
+	cfg, err := palette.Load("../themes/" + name + "/palette.toml")
+	if err != nil {
+		t.Fatalf("Load %s: %v", name, err)
+	}
+	return cfg
+}
+
+func runAudit(t *testing.T, cfg palette.Config) audit.Report {
+	t.Helper()
+	r, err := audit.Run(cfg)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	return r
+}
+
+// oracle reads a pinned fixture and drops the script's closing summary
+// ("Total failures: N" or "All palettes pass audit."), which main() prints
+// after a blank line once every palette is reported. That summary is the
+// CLI's job, not the report's.
+func oracle(t *testing.T, name string) []byte {
+	t.Helper()
+	expected, err := os.ReadFile("../testdata/expected/audit/" + name + ".txt")
+	if err != nil {
+		t.Fatalf("reading expected fixture: %v", err)
+	}
+	i := bytes.LastIndex(expected, []byte("\n\n  "))
+	if i < 0 {
+		t.Fatalf("fixture %s has no closing summary", name)
+	}
+	return expected[:i+1]
+}
+
+func TestReport_OraclePython(t *testing.T) {
+	for _, name := range bundledThemes {
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+			runAudit(t, loadTheme(t, name)).Write(&buf, false)
+
+			got, expected := buf.Bytes(), oracle(t, name)
+			if !bytes.Equal(got, expected) {
+				t.Errorf("report differs from oracle\n--- got ---\n%s\n--- want ---\n%s", got, expected)
+			}
+		})
+	}
+}
+
+func TestCompare_OraclePython(t *testing.T) {
+	baseline := loadTheme(t, "cobalt-next-neon-v2")
+	cfg := loadTheme(t, "tekapo-sunset-dark")
+
+	var buf bytes.Buffer
+	runAudit(t, cfg).Write(&buf, false)
+	audit.WriteComparison(&buf, audit.Compare(baseline, cfg), baseline.Theme.Name, cfg.Theme.Name)
+
+	expected := oracle(t, "tekapo-sunset-dark-vs-cobalt-next-neon-v2")
+	if !bytes.Equal(buf.Bytes(), expected) {
+		t.Errorf("comparison differs from oracle\n--- got ---\n%s\n--- want ---\n%s", buf.Bytes(), expected)
+	}
+}
+
+func TestCompare_Translucent(t *testing.T) {
+	cfg := loadTheme(t, "cobalt-next-neon-v2")
+	cfg.Palette.Color4 = "#3366ff80"
+	cfg.Palette.UI.Border = "#ffffff40"
+
+	contrast := map[string]audit.ContrastResult{}
+	for _, c := range runAudit(t, cfg).Contrast {
+		contrast[c.Slot] = c
+	}
+	for _, d := range audit.Compare(cfg, cfg) {
+		if c, ok := contrast[d.Slot]; ok && (d.LcA != c.Lc || d.LcB != c.Lc) {
+			t.Errorf("%s: Compare Lc %v/%v, Run Lc %v", d.Slot, d.LcA, d.LcB, c.Lc)
+		}
+	}
+}
+
+func TestRun_Numbers(t *testing.T) {
+	r := runAudit(t, loadTheme(t, "cobalt-next-neon-v2"))
+
+	contrast := map[string]audit.ContrastResult{}
+	for _, c := range r.Contrast {
+		contrast[c.Slot] = c
+	}
+
+	fg := contrast["fg"]
+	if math.Abs(fg.Lc-(-83.53600912766724)) > 1e-9 || fg.Severity != audit.Warn || fg.Category != audit.CategoryBody {
+		t.Errorf("fg = %+v, want Lc -83.536 body WARN", fg)
+	}
+	if c := contrast["color0"]; c.Severity != audit.Exempt {
+		t.Errorf("color0 severity = %s, want EXEMPT in a dark theme", c.Severity)
+	}
+	if c := contrast["ui.border"]; c.Category != audit.CategoryUIStructural {
+		t.Errorf("ui.border category = %s, want %s", c.Category, audit.CategoryUIStructural)
+	}
+
+	for _, o := range r.OKLCH {
+		if o.Slot == "color4" {
+			if math.Abs(o.OKLCH.L-0.7037) > 5e-4 || math.Abs(o.OKLCH.C-0.1627) > 5e-4 || math.Abs(o.OKLCH.H-248.4) > 0.05 {
+				t.Errorf("color4 OKLCH = %+v, want L 0.704 C 0.163 H 248", o.OKLCH)
+			}
+		}
+	}
+
+	if r.Failures(false) != 0 {
+		t.Errorf("Failures(false) = %d, want 0", r.Failures(false))
+	}
+	if got, want := r.Failures(true), r.Warnings(); got != want || want == 0 {
+		t.Errorf("Failures(true) = %d, want warnings count %d (> 0)", got, want)
+	}
+}
+
+func TestRun_AdapterOverrideLayered(t *testing.T) {
+	cfg, err := palette.Load("../testdata/bleu.toml")
+	if err != nil {
+		t.Fatalf("Load bleu.toml: %v", err)
+	}
+	r := runAudit(t, cfg)
+
+	if len(r.Overrides) != 1 || r.Overrides[0].Adapter != "fzf" {
+		t.Fatalf("Overrides = %+v, want one fzf override", r.Overrides)
+	}
+	for _, c := range r.Overrides[0].Contrast {
+		switch c.Slot {
+		case "fg":
+			if c.Hex != "#e8f4f8" {
+				t.Errorf("override fg = %s, want the override's #e8f4f8", c.Hex)
+			}
+		case "ui.success":
+			if c.Hex != "#99FFE4" {
+				t.Errorf("override ui.success = %s, want base #99FFE4", c.Hex)
+			}
+		}
+	}
+}
+
+func TestClassifySlot(t *testing.T) {
+	tests := []struct {
+		slot    int
+		variant string
+		want    string
+	}{
+		{0, "dark", audit.CategoryBGMatch},
+		{15, "dark", audit.CategoryBody},
+		{0, "light", audit.CategoryBody},
+		{15, "light", audit.CategoryBGMatch},
+		{7, "dark", audit.CategoryComment},
+		{8, "light", audit.CategoryComment},
+		{4, "dark", audit.CategoryChromaticNormal},
+		{12, "light", audit.CategoryChromaticBright},
+	}
+	for _, tc := range tests {
+		if got := audit.ClassifySlot(tc.slot, tc.variant); got != tc.want {
+			t.Errorf("ClassifySlot(%d, %s) = %s, want %s", tc.slot, tc.variant, got, tc.want)
+		}
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	tests := []struct {
+		lc       float64
+		category string
+		want     audit.Severity
+	}{
+		{-74.9, audit.CategoryBody, audit.Fail},
+		{80, audit.CategoryBody, audit.Warn},
+		{-90, audit.CategoryBody, audit.Pass},
+		{14, audit.CategoryUIStructural, audit.Fail},
+		{16, audit.CategoryUIStructural, audit.Pass},
+		{0, audit.CategoryBGMatch, audit.Exempt},
+	}
+	for _, tc := range tests {
+		if got := audit.Evaluate(tc.lc, tc.category); got != tc.want {
+			t.Errorf("Evaluate(%v, %s) = %s, want %s", tc.lc, tc.category, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,174 @@
+package audit
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// sevMarkers are the ANSI-colored severity labels used in reports.
+var sevMarkers = map[Severity]string{
+	Fail:   "\033[1;31mFAIL\033[0m",
+	Warn:   "\033[1;33mWARN\033[0m",
+	Pass:   "\033[32mPASS\033[0m",
+	Info:   "\033[36mINFO\033[0m",
+	Exempt: "\033[90mEXEMPT\033[0m",
+}
+
+func sevMarker(s Severity) string {
+	if m, ok := sevMarkers[s]; ok {
+		return m
+	}
+	return string(s)
+}
+
+func fmtf(format string, args ...any) string {
+	return fmt.Sprintf(format, args...)
+}
+
+func joinNotes(notes []string) string {
+	return strings.Join(notes, "; ")
+}
+
+// Write prints the full report and returns the number of failures that
+// contribute to the exit status (see Report.Failures).
+func (r Report) Write(w io.Writer, strict bool) int {
+	fmt.Fprintf(w, "\n%s\n", strings.Repeat("=", 76))
+	fmt.Fprintf(w, "  Palette Audit\n")
+	fmt.Fprintf(w, "  %s  (%s)  bg: %s\n", r.Name, r.Variant, r.BG)
+	fmt.Fprintf(w, "%s\n", strings.Repeat("=", 76))
+
+	writeContrast(w, r.Contrast)
+	writeOKLCH(w, r.OKLCH)
+	writeHueIdentity(w, r.Hue)
+	writePairCoherence(w, r.Pairs)
+	writeDistinguishability(w, r.Distinguish)
+	writeCrossContext(w, r.CrossContext)
+
+	for _, o := range r.Overrides {
+		fmt.Fprintf(w, "\n  %s\n", strings.Repeat("=", 40))
+		fmt.Fprintf(w, "  Adapter Override: %s\n", o.Adapter)
+		fmt.Fprintf(w, "  %s\n", strings.Repeat("=", 40))
+		writeContrast(w, o.Contrast)
+		writeOKLCH(w, o.OKLCH)
+		writeHueIdentity(w, o.Hue)
+		writePairCoherence(w, o.Pairs)
+		writeDistinguishability(w, o.Distinguish)
+	}
+
+	failures := r.Failures(strict)
+	warns := r.Warnings()
+
+	fmt.Fprintln(w)
+	switch {
+	case failures > 0:
+		fmt.Fprintf(w, "  RESULT: %d failure(s)\n", failures)
+	case warns > 0:
+		fmt.Fprintf(w, "  RESULT: No failures. %d warning(s).\n", warns)
+	default:
+		fmt.Fprintf(w, "  RESULT: All checks pass.\n")
+	}
+	return failures
+}
+
+func writeSection(w io.Writer, title string) {
+	fmt.Fprintf(w, "\n  --- %s ---\n\n", title)
+}
+
+func writeContrast(w io.Writer, results []ContrastResult) {
+	writeSection(w, "APCA Contrast (Lc)")
+	fmt.Fprintf(w, "  %-26s %-10s %7s  %-18s Status\n", "Slot", "Hex", "Lc", "Category")
+	fmt.Fprintf(w, "  %s\n", strings.Repeat("-", 70))
+	for _, r := range results {
+		fmt.Fprintf(w, "  %-26s %-10s %+7.1f  %-18s %s\n", r.Slot, r.Hex, r.Lc, r.Category, sevMarker(r.Severity))
+	}
+}
+
+func writeOKLCH(w io.Writer, results []OKLCHResult) {
+	writeSection(w, "OKLCH Decomposition")
+	fmt.Fprintf(w, "  %-10s %-10s %6s %6s %6s  Note\n", "Slot", "Hex", "L", "C", "H")
+	fmt.Fprintf(w, "  %s\n", strings.Repeat("-", 52))
+	for _, r := range results {
+		o := r.OKLCH
+		note := ""
+		if o.IsAchromatic() {
+			note = "achromatic"
+		} else if o.HueUnreliable() {
+			note = "low chroma"
+		}
+		fmt.Fprintf(w, "  %-10s %-10s %5.3f %5.3f %5.0f°  %s\n", r.Slot, r.Hex, o.L, o.C, o.H, note)
+	}
+}
+
+func writeHueIdentity(w io.Writer, results []HueResult) {
+	writeSection(w, "Hue Identity")
+	fmt.Fprintf(w, "  %-6s %-10s %-10s %5s°/%3s°  Status  Note\n", "Slot", "Expected", "Actual", "Dist", "Max")
+	fmt.Fprintf(w, "  %s\n", strings.Repeat("-", 62))
+	for _, r := range results {
+		slot := fmt.Sprintf("%-3d %s", r.Slot, ANSINames[r.Slot])
+		fmt.Fprintf(w, "  %-16s %-10s %-10s %4.0f°/%2.0f°  %s  %s\n",
+			slot, r.ExpectedFamily, r.ActualFamily, r.Distance, r.MaxDistance, sevMarker(r.Severity), r.Note)
+	}
+}
+
+func writePairCoherence(w io.Writer, results []PairResult) {
+	writeSection(w, "Pair Coherence (normal/bright)")
+	fmt.Fprintf(w, "  %-10s %-8s %6s %6s %5s°  Status  Note\n", "Family", "Pair", "dL", "dC", "dH")
+	fmt.Fprintf(w, "  %s\n", strings.Repeat("-", 60))
+	for _, r := range results {
+		pair := fmt.Sprintf("%d/%d", r.NormalSlot, r.BrightSlot)
+		fmt.Fprintf(w, "  %-10s %-8s %+5.3f %+5.3f %4.0f°  %s  %s\n",
+			r.Family, pair, r.DeltaL, r.DeltaC, r.HueDrift, sevMarker(r.Severity), r.Note)
+	}
+}
+
+func writeDistinguishability(w io.Writer, results []DistinguishResult) {
+	writeSection(w, "Distinguishability (closest pairs)")
+	if len(results) == 0 {
+		fmt.Fprintln(w, "  All chromatic pairs sufficiently distinct.")
+		return
+	}
+	fmt.Fprintf(w, "  %-18s %6s %5s°  Status  Note\n", "Pair", "dE_OK", "dH")
+	fmt.Fprintf(w, "  %s\n", strings.Repeat("-", 55))
+	for _, r := range results {
+		pair := ANSINames[r.SlotA] + "/" + ANSINames[r.SlotB]
+		fmt.Fprintf(w, "  %-18s %5.3f %4.0f°  %s  %s\n", pair, r.DeltaE, r.HueDist, sevMarker(r.Severity), r.Note)
+	}
+}
+
+func writeCrossContext(w io.Writer, results []ContrastResult) {
+	writeSection(w, "Cross-Context Contrast")
+	fmt.Fprintf(w, "  %-30s %-10s %7s  %-18s Status\n", "Context", "Hex", "Lc", "Category")
+	fmt.Fprintf(w, "  %s\n", strings.Repeat("-", 74))
+	for _, r := range results {
+		fmt.Fprintf(w, "  %-30s %-10s %+7.1f  %-18s %s\n", r.Slot, r.Hex, r.Lc, r.Category, sevMarker(r.Severity))
+	}
+}
+
+// WriteComparison prints the contrast changes from baseline nameA to nameB.
+// Slots exempt in both palettes are omitted.
+func WriteComparison(w io.Writer, deltas []ComparisonDelta, nameA, nameB string) {
+	writeSection(w, fmt.Sprintf("Comparison: %s -> %s", nameA, nameB))
+	fmt.Fprintf(w, "  %-26s %7s %7s %7s  A     B     Reg\n", "Slot", "Lc A", "Lc B", "Change")
+	fmt.Fprintf(w, "  %s\n", strings.Repeat("-", 72))
+
+	regressions := 0
+	for _, d := range deltas {
+		if d.SevA == Exempt && d.SevB == Exempt {
+			continue
+		}
+		marker := ""
+		if d.Regression {
+			marker = " <<<"
+			regressions++
+		}
+		fmt.Fprintf(w, "  %-26s %+7.1f %+7.1f %+7.1f  %-5s %-5s %s\n",
+			d.Slot, d.LcA, d.LcB, d.Change, d.SevA, d.SevB, marker)
+	}
+
+	if regressions > 0 {
+		fmt.Fprintf(w, "\n  %d regression(s) detected.\n", regressions)
+	} else {
+		fmt.Fprintf(w, "\n  No regressions.\n")
+	}
+}
@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kylesnowschwartz/the-themer/audit"
+	"github.com/kylesnowschwartz/the-themer/palette"
+)
+
+var (
+	auditCompareFlag string
+	auditStrictFlag  bool
+)
+
+var auditCmd = &cobra.Command{
+	Use:   "audit <palette.toml>...",
+	Short: "Audit palettes for APCA contrast, hue identity, and distinguishability",
+	Long: `Audit checks each palette for perceptual contrast (APCA-W3), OKLCH hue
+identity, normal/bright pair coherence, and cross-family distinguishability.
+
+Use --compare to report contrast changes against a baseline palette, and
+--strict to treat contrast warnings as failures. Exits non-zero when any
+palette has failures.
+
+Examples:
+  the-themer audit themes/*/palette.toml
+  the-themer audit themes/dayfox/palette.toml --compare old.toml`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runAudit,
+}
+
+func init() {
+	rootCmd.AddCommand(auditCmd)
+	auditCmd.Flags().StringVar(&auditCompareFlag, "compare", "", "baseline palette TOML to compare against")
+	auditCmd.Flags().BoolVar(&auditStrictFlag, "strict", false, "treat warnings as failures")
+}
+
+func runAudit(cmd *cobra.Command, args []string) error {
+	out := cmd.OutOrStdout()
+
+	var baseline palette.Config
+	if auditCompareFlag != "" {
+		var err error
+		baseline, err = palette.Load(auditCompareFlag)
+		if err != nil {
+			return fmt.Errorf("loading baseline %s: %w", auditCompareFlag, err)
+		}
+	}
+
+	total := 0
+	for _, path := range args {
+		cfg, err := palette.Load(path)
+		if err != nil {
+			return fmt.Errorf("loading %s: %w", path, err)
+		}
+		report, err := audit.Run(cfg)
+		if err != nil {
+			return fmt.Errorf("auditing %s: %w", path, err)
+		}
+		total += report.Write(out, auditStrictFlag)
+
+		if auditCompareFlag != "" {
+			deltas := audit.Compare(baseline, cfg)
+			audit.WriteComparison(out, deltas, baseline.Theme.Name, cfg.Theme.Name)
+		}
+	}
+
+	fmt.Fprintln(out)
+	if total > 0 {
+		fmt.Fprintf(out, "  Total failures: %d\n", total)
+		return fmt.Errorf("audit found %d failure(s)", total)
+	}
+	fmt.Fprintln(out, "  All palettes pass audit.")
+	return nil
+}
@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kylesnowschwartz/the-themer/adapter"
+	"github.com/kylesnowschwartz/the-themer/diff"
+	"github.com/kylesnowschwartz/the-themer/theme"
+)
+
+var (
+	checkThemesDir string
+	checkFixFlag   bool
+)
+
+var checkCmd = &cobra.Command{
+	Use:   "check [theme-name...]",
+	Short: "Check that generated theme files match their palettes",
+	Long: `Check regenerates every adapter's output for each theme in memory and
+compares it with the files checked in under themes/<name>/<app>/. Each
+mismatch is printed as a unified diff, from the file on disk to the
+generated content, and check exits non-zero. Only adapters the theme has a
+directory for are checked.
+
+Use --fix to rewrite drifted files in place instead.
+
+Examples:
+  the-themer check
+  the-themer check dayfox --fix`,
+	RunE: runCheck,
+}
+
+func init() {
+	rootCmd.AddCommand(checkCmd)
+	checkCmd.Flags().StringVar(&checkThemesDir, "themes-dir", defaultThemesDir(), "path to the themes directory")
+	checkCmd.Flags().BoolVar(&checkFixFlag, "fix", false, "rewrite drifted files with the generated content")
+}
+
+func runCheck(cmd *cobra.Command, args []string) error {
+	out := cmd.OutOrStdout()
+
+	names := args
+	if len(names) == 0 {
+		var err error
+		names, err = theme.ListThemes(checkThemesDir)
+		if err != nil {
+			return err
+		}
+	}
+
+	drifted := 0
+	for _, name := range names {
+		t, err := theme.LoadTheme(checkThemesDir, name)
+		if err != nil {
+			return err
+		}
+		drifts, err := theme.CheckDrift(t, adapter.All())
+		if err != nil {
+			return fmt.Errorf("checking %s: %w", name, err)
+		}
+
+		for _, d := range drifts {
+			drifted++
+			rel, err := filepath.Rel(t.Dir, d.Path)
+			if err != nil {
+				rel = d.Path
+			}
+			rel = filepath.Join(name, rel)
+
+			if checkFixFlag {
+				if err := d.Fix(); err != nil {
+					return err
+				}
+				fmt.Fprintf(out, "  fixed %s\n", rel)
+				continue
+			}
+			fmt.Fprint(out, diff.Unified(rel, rel+" (generated)", d.OnDisk, d.Want))
+		}
+	}
+
+	switch {
+	case drifted == 0:
+		fmt.Fprintf(out, "All generated files match their palettes (%d theme(s)).\n", len(names))
+	case checkFixFlag:
+		fmt.Fprintf(out, "Fixed %d drifted file(s).\n", drifted)
+	default:
+		return fmt.Errorf("%d generated file(s) out of date; run \"the-themer check --fix\" to regenerate", drifted)
+	}
+	return nil
+}
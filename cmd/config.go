@@ -1,13 +1,66 @@
 package cmd
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kylesnowschwartz/the-themer/theme"
 )
 
+// userConfig is the-themer's config.toml, loaded once before any command
+// runs.
+var userConfig *theme.Config
+
+// loadConfig reads config.toml into userConfig. Its themes_dir becomes the
+// default for the command's --themes-dir, after THE_THEMER_THEMES_DIR.
+func loadConfig(cmd *cobra.Command, args []string) error {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return fmt.Errorf("resolving home directory: %w", err)
+	}
+	cfg, err := theme.LoadConfig(home)
+	if err != nil {
+		return err
+	}
+	userConfig = &cfg
+
+	f := cmd.Flags().Lookup("themes-dir")
+	if f == nil || f.Changed || cfg.ThemesDir == "" || os.Getenv("THE_THEMER_THEMES_DIR") != "" {
+		return nil
+	}
+	return f.Value.Set(cfg.ThemesDir)
+}
+
+// migrateState moves a state directory left in ~/.config to
+// $XDG_CONFIG_HOME (see theme.MigrateStateDir). Commands that change files
+// call it before anything else; a dry run only says it would.
+func migrateState(cmd *cobra.Command, dryRun bool) error {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return fmt.Errorf("resolving home directory: %w", err)
+	}
+	from, to, ok := theme.StateDirMove(home)
+	if !ok {
+		return nil
+	}
+	if dryRun {
+		fmt.Fprintf(cmd.OutOrStdout(), "  state: would move %s to %s (XDG_CONFIG_HOME)\n", from, to)
+		return nil
+	}
+	if _, err := theme.MigrateStateDir(home); err != nil {
+		return err
+	}
+	fmt.Fprintf(cmd.ErrOrStderr(), "Moved %s to %s (XDG_CONFIG_HOME) and left a symlink behind\n", from, to)
+	return nil
+}
+
 // defaultThemesDir returns the themes directory path.
 // Checks THE_THEMER_THEMES_DIR env var first, then resolves "themes/"
-// relative to the executable's real path (follows symlinks).
+// relative to the executable's real path (follows symlinks). config.toml's
+// themes_dir, when set, replaces the latter (see loadConfig).
 func defaultThemesDir() string {
 	if dir := os.Getenv("THE_THEMER_THEMES_DIR"); dir != "" {
 		return dir
@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMigrateState_OnlyWhenChangingFiles(t *testing.T) {
+	home := t.TempDir()
+	xdg := filepath.Join(home, "xdg")
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", xdg)
+	t.Setenv("GIT_CONFIG_GLOBAL", "")
+
+	legacy := filepath.Join(home, ".config", "the-themer")
+	if err := os.MkdirAll(legacy, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(legacy, "current"), []byte("nordfox\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	rootCmd.SetOut(&out)
+	rootCmd.SetErr(&out)
+	t.Cleanup(func() {
+		rootCmd.SetOut(nil)
+		rootCmd.SetErr(nil)
+		rootCmd.SetArgs(nil)
+		switchDryRun = false
+	})
+
+	// A dry run leaves both directories as they are.
+	rootCmd.SetArgs([]string{"switch", "dayfox", "--dry-run", "--themes-dir", "../themes"})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("switch --dry-run: %v\n%s", err, out.String())
+	}
+	if !strings.Contains(out.String(), "would move "+legacy) {
+		t.Errorf("dry run output doesn't mention the move:\n%s", out.String())
+	}
+	info, err := os.Lstat(legacy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !info.IsDir() {
+		t.Fatalf("old state directory after a dry run has mode %v; want it left in place", info.Mode())
+	}
+	if _, err := os.Lstat(filepath.Join(xdg, "the-themer")); !os.IsNotExist(err) {
+		t.Fatalf("new state directory exists after a dry run: %v", err)
+	}
+
+	// A command that changes files moves it first.
+	out.Reset()
+	rootCmd.SetArgs([]string{"set", "dark", "dayfox", "--themes-dir", "../themes"})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("set: %v\n%s", err, out.String())
+	}
+	if target, err := os.Readlink(legacy); err != nil || target != filepath.Join(xdg, "the-themer") {
+		t.Errorf("old state directory after set = %q, %v; want a symlink to the new one", target, err)
+	}
+	for name, want := range map[string]string{"current": "nordfox", "default-dark": "dayfox"} {
+		data, err := os.ReadFile(filepath.Join(xdg, "the-themer", name))
+		if err != nil || strings.TrimSpace(string(data)) != want {
+			t.Errorf("%s after set = %q, %v; want %q", name, data, err, want)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kylesnowschwartz/the-themer/adapter"
+	"github.com/kylesnowschwartz/the-themer/derive"
+	"github.com/kylesnowschwartz/the-themer/palette"
+	"github.com/kylesnowschwartz/the-themer/theme"
+)
+
+var (
+	deriveVariantNameFlag      string
+	deriveVariantThemesDirFlag string
+	deriveVariantForceFlag     bool
+)
+
+var deriveVariantCmd = &cobra.Command{
+	Use:   "derive-variant <theme>",
+	Short: "Derive a theme's counterpart in the opposite variant",
+	Long: `Derive-variant produces the light counterpart of a dark theme, or the
+dark counterpart of a light one. Every color keeps its OKLCH hue and chroma
+while lightness is remapped so the background and foreground trade places;
+colors that then fall short of the audit's contrast thresholds are pushed
+away from the background until they clear them.
+
+The result is written to themes/<name>/ like any other theme, with every
+adapter's output generated. The two themes are linked by setting
+theme.sibling in both palette.toml files.
+
+The new theme's name defaults to the source name with "-dark" and "-light"
+swapped, or with the new variant appended.
+
+Examples:
+  the-themer derive-variant tekapo-sunset-dark
+  the-themer derive-variant dayfox --name nightfox-derived`,
+	Args: cobra.ExactArgs(1),
+	RunE: runDeriveVariant,
+}
+
+func init() {
+	rootCmd.AddCommand(deriveVariantCmd)
+	deriveVariantCmd.Flags().StringVar(&deriveVariantNameFlag, "name", "", "name of the derived theme (default: source name with the variant swapped)")
+	deriveVariantCmd.Flags().StringVar(&deriveVariantThemesDirFlag, "themes-dir", defaultThemesDir(), "path to the themes directory")
+	deriveVariantCmd.Flags().BoolVar(&deriveVariantForceFlag, "force", false, "overwrite an existing theme directory's files")
+}
+
+func runDeriveVariant(cmd *cobra.Command, args []string) error {
+	src, err := theme.LoadTheme(deriveVariantThemesDirFlag, args[0])
+	if err != nil {
+		return err
+	}
+
+	name := deriveVariantNameFlag
+	if name == "" {
+		name = derive.OppositeName(src.Name, src.Config.Theme.Variant)
+	}
+	if name == src.Name {
+		return fmt.Errorf("derived theme cannot replace its source %q", name)
+	}
+
+	cfg, err := derive.Opposite(src.Config, name)
+	if err != nil {
+		return fmt.Errorf("deriving %s: %w", name, err)
+	}
+	// Link by directory name, which is what switch and install resolve.
+	cfg.Theme.Sibling = src.Name
+
+	dir := filepath.Join(deriveVariantThemesDirFlag, name)
+	path := filepath.Join(dir, "palette.toml")
+	if _, err := os.Stat(path); err == nil && !deriveVariantForceFlag {
+		return fmt.Errorf("%s already exists (use --force to overwrite)", path)
+	} else if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("creating directory %s: %w", dir, err)
+	}
+
+	header := fmt.Sprintf("# %s palette\n# Derived from %s by the-themer derive-variant.\n\n", name, src.Name)
+	if err := os.WriteFile(path, append([]byte(header), palette.Encode(cfg)...), 0o644); err != nil {
+		return fmt.Errorf("writing %s: %w", path, err)
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "  palette -> %s\n", path)
+
+	loaded, err := palette.Load(path)
+	if err != nil {
+		return err
+	}
+	if err := writeAdapters(cmd.OutOrStdout(), loaded, dir, adapter.All()); err != nil {
+		return err
+	}
+
+	// Point the source back at its new sibling, editing in place so its
+	// comments and layout are untouched.
+	srcPath := filepath.Join(src.Dir, "palette.toml")
+	data, err := os.ReadFile(srcPath)
+	if err != nil {
+		return fmt.Errorf("reading %s: %w", srcPath, err)
+	}
+	data, err = palette.SetKey(data, "theme", "sibling", name)
+	if err != nil {
+		return fmt.Errorf("linking %s: %w", srcPath, err)
+	}
+	if err := os.WriteFile(srcPath, data, 0o644); err != nil {
+		return fmt.Errorf("writing %s: %w", srcPath, err)
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "  sibling -> %s\n", srcPath)
+
+	fmt.Fprintf(cmd.OutOrStdout(), "Created %s theme %q from %q in %s\n", cfg.Theme.Variant, name, src.Name, dir)
+	return nil
+}
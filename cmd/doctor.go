@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kylesnowschwartz/the-themer/theme"
+)
+
+var (
+	doctorThemesDir string
+	doctorJSON      bool
+)
+
+var doctorCmd = &cobra.Command{
+	Use:     "doctor [theme-name]",
+	Aliases: []string{"status"},
+	Short:   "Check that the current theme is fully installed and switched to",
+	Long: `Doctor checks the current theme (from the state file, or the theme
+named) across every app:
+
+  - installed files exist and match the warehouse
+  - files switch writes (theme.local, bat-theme.txt, delta-theme.txt, ...)
+    hold what switching to the theme would write
+  - symlinks (starship, fzf, eza) point at the installed files and resolve
+  - the global gitconfig includes active.gitconfig
+  - bat's cache lists the theme
+  - each app's program is on PATH
+
+Each app gets pass, warn or fail (or skip if the theme has nothing for
+it), and doctor exits non-zero if any app fails. Use --json for a
+machine-readable report.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runDoctor,
+}
+
+func init() {
+	rootCmd.AddCommand(doctorCmd)
+	doctorCmd.Flags().StringVar(&doctorThemesDir, "themes-dir", defaultThemesDir(), "path to the themes directory")
+	doctorCmd.Flags().BoolVar(&doctorJSON, "json", false, "print the report as JSON")
+}
+
+// doctorReport is the --json output.
+type doctorReport struct {
+	Theme string            `json:"theme"`
+	Apps  []theme.AppHealth `json:"apps"`
+}
+
+func runDoctor(cmd *cobra.Command, args []string) error {
+	out := cmd.OutOrStdout()
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return fmt.Errorf("resolving home directory: %w", err)
+	}
+
+	var themeName string
+	if len(args) == 1 {
+		themeName = args[0]
+	} else {
+		themeName, err = theme.ReadState(home)
+		if err != nil {
+			return err
+		}
+		if themeName == "" {
+			return fmt.Errorf("no current theme — use \"the-themer switch <theme-name>\" first")
+		}
+	}
+
+	t, err := theme.LoadTheme(doctorThemesDir, themeName)
+	if err != nil {
+		return err
+	}
+	apps, err := theme.Doctor(t, theme.DoctorOpts{HomeDir: home, Config: userConfig})
+	if err != nil {
+		return err
+	}
+
+	failed := 0
+	for _, a := range apps {
+		if a.Status == theme.StatusFail {
+			failed++
+		}
+	}
+
+	if doctorJSON {
+		data, err := json.MarshalIndent(doctorReport{Theme: themeName, Apps: apps}, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(out, string(data))
+	} else {
+		fmt.Fprintf(out, "Theme %q\n\n", themeName)
+		for _, a := range apps {
+			fmt.Fprintf(out, "  %-9s %s\n", a.App, strings.ToUpper(string(a.Status)))
+			for _, f := range a.Findings {
+				fmt.Fprintf(out, "    %-4s  %s\n", f.Status, f.Message)
+			}
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d app(s) failed their checks", failed)
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/kylesnowschwartz/the-themer/theme"
+)
+
+func TestDoctor_JSON(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", "")
+	t.Setenv("GIT_CONFIG_GLOBAL", "")
+
+	// Nothing is installed, so every app with files fails.
+	var out bytes.Buffer
+	rootCmd.SetOut(&out)
+	rootCmd.SetArgs([]string{"doctor", "--json", "--themes-dir", "../themes", "dayfox"})
+	t.Cleanup(func() { rootCmd.SetOut(nil); rootCmd.SetArgs(nil) })
+	if err := rootCmd.Execute(); err == nil {
+		t.Error("doctor on an uninstalled theme: got no error, want failed checks")
+	}
+
+	var report doctorReport
+	if err := json.Unmarshal(out.Bytes(), &report); err != nil {
+		t.Fatalf("unmarshalling --json output: %v\n%s", err, out.Bytes())
+	}
+	if report.Theme != "dayfox" {
+		t.Errorf("theme = %q, want dayfox", report.Theme)
+	}
+	byApp := map[string]theme.AppHealth{}
+	for _, a := range report.Apps {
+		byApp[a.App] = a
+	}
+	if h := byApp["ghostty"]; h.Status != theme.StatusFail || len(h.Findings) == 0 {
+		t.Errorf("ghostty = %+v, want fail with findings", h)
+	}
+	if h := byApp["neovim"]; h.Status == "" {
+		t.Errorf("neovim missing from report: %+v", report.Apps)
+	}
+}
@@ -1,28 +1,48 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
-	"os"
+	"io"
 	"path/filepath"
 	"strings"
+	"sync"
 
 	"github.com/spf13/cobra"
 
 	"github.com/kylesnowschwartz/the-themer/adapter"
 	"github.com/kylesnowschwartz/the-themer/palette"
+	"github.com/kylesnowschwartz/the-themer/theme"
 )
 
 var (
 	inputFlag    string
 	outputFlag   string
 	adaptersFlag string
+	lenientFlag  bool
+
+	warehouseFlag     bool
+	generateThemesDir string
+	generateDryRun    bool
 )
 
 var generateCmd = &cobra.Command{
 	Use:   "generate",
 	Short: "Generate themed config files from a TOML palette",
 	Long: `Parse a TOML color palette file, apply defaults, validate, and
-generate themed config files for each registered adapter.`,
+generate themed config files for each registered adapter.
+
+With --warehouse, regenerate every theme in the themes directory in place
+instead: each theme's palette.toml is rendered through the selected
+adapters it has an app directory for, overwriting the existing file (under
+its existing name, when a theme keeps one named after an upstream theme
+rather than the adapter's default).
+
+Examples:
+  the-themer generate -i palette.toml -o ./my-theme
+  the-themer generate --warehouse
+  the-themer generate --warehouse --adapters bat,delta
+  the-themer generate --warehouse --dry-run`,
 	RunE: runGenerate,
 }
 
@@ -32,12 +52,22 @@ func init() {
 	generateCmd.Flags().StringVarP(&inputFlag, "input", "i", "", "path to TOML palette file (required)")
 	generateCmd.Flags().StringVarP(&outputFlag, "output", "o", "", "output directory (default: ./{theme-name}-theme/)")
 	generateCmd.Flags().StringVar(&adaptersFlag, "adapters", "", "comma-separated list of adapters to run (default: all)")
-
-	generateCmd.MarkFlagRequired("input")
+	generateCmd.Flags().BoolVar(&lenientFlag, "lenient", false, "ignore unknown palette keys instead of reporting them")
+	generateCmd.Flags().BoolVar(&warehouseFlag, "warehouse", false, "regenerate every theme in --themes-dir in place")
+	generateCmd.Flags().StringVar(&generateThemesDir, "themes-dir", defaultThemesDir(), "path to the themes directory (with --warehouse)")
+	generateCmd.Flags().BoolVar(&generateDryRun, "dry-run", false, "print the planned writes as diffs without writing anything")
+
+	generateCmd.MarkFlagsOneRequired("input", "warehouse")
+	generateCmd.MarkFlagsMutuallyExclusive("input", "warehouse")
+	generateCmd.MarkFlagsMutuallyExclusive("output", "warehouse")
 }
 
 func runGenerate(cmd *cobra.Command, args []string) error {
-	cfg, err := palette.Load(inputFlag)
+	if warehouseFlag {
+		return runGenerateWarehouse(cmd)
+	}
+
+	cfg, err := palette.LoadWith(inputFlag, palette.LoadOpts{Lenient: lenientFlag})
 	if err != nil {
 		return err
 	}
@@ -47,51 +77,172 @@ func runGenerate(cmd *cobra.Command, args []string) error {
 		outDir = fmt.Sprintf("./%s-theme", cfg.Theme.Name)
 	}
 
-	var selected []adapter.Adapter
-	if adaptersFlag != "" {
-		names := strings.Split(adaptersFlag, ",")
-		selected = adapter.ByName(names)
-	} else {
-		selected = adapter.All()
-	}
-
+	selected := selectedAdapters()
 	if len(selected) == 0 {
 		fmt.Fprintln(cmd.OutOrStdout(), "No adapters registered. Nothing to generate.")
 		return nil
 	}
 
+	if generateDryRun {
+		plan, err := planAdapters(cfg, outDir, selected)
+		if err != nil {
+			return err
+		}
+		return printPlan(cmd.OutOrStdout(), plan)
+	}
+
+	if err := writeAdapters(cmd.OutOrStdout(), cfg, outDir, selected); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(cmd.OutOrStdout(), "Generated %d file(s) in %s\n", len(selected), outDir)
+	return nil
+}
+
+// writeAdapters renders cfg through each adapter into outDir/<adapter dir>/,
+// reporting each written file to w.
+func writeAdapters(w io.Writer, cfg palette.Config, outDir string, selected []adapter.Adapter) error {
+	plan, err := planAdapters(cfg, outDir, selected)
+	if err != nil {
+		return err
+	}
+	return applyWrites(w, plan)
+}
+
+// planAdapters renders cfg through each adapter, planning a write of each
+// output to outDir/<adapter dir>/.
+func planAdapters(cfg palette.Config, outDir string, selected []adapter.Adapter) (theme.Plan, error) {
+	var plan theme.Plan
 	for _, a := range selected {
-		// Use per-adapter palette override if present, otherwise use base config.
-		adapterCfg := cfg
-		if override, ok := cfg.Adapters[a.Name()]; ok {
-			adapterCfg = palette.Config{
-				Theme:   cfg.Theme,
-				Palette: override.Palette,
-			}
-			adapterCfg.ApplyDefaults()
-			if err := adapterCfg.Validate(); err != nil {
-				return fmt.Errorf("adapter %s override: %w", a.Name(), err)
-			}
+		// Layer the per-adapter palette override, if any, over the base config.
+		adapterCfg, err := cfg.ForAdapter(a.Name())
+		if err != nil {
+			return theme.Plan{}, err
 		}
 
 		content, err := a.Generate(adapterCfg)
 		if err != nil {
-			return fmt.Errorf("adapter %s: %w", a.Name(), err)
+			return theme.Plan{}, fmt.Errorf("adapter %s: %w", a.Name(), err)
+		}
+
+		filePath := filepath.Join(outDir, a.DirName(), a.FileName(cfg.Theme.Name))
+		plan.Apps = append(plan.Apps, writePlan(a.Name(), filePath, content))
+	}
+	return plan, nil
+}
+
+// writePlan is the plan for writing one adapter's output to path.
+func writePlan(adapterName, path string, content []byte) theme.AppPlan {
+	return theme.AppPlan{
+		App:     adapterName,
+		Message: path,
+		Actions: []theme.Action{{Kind: theme.ActionWrite, Path: path, Content: content}},
+	}
+}
+
+// applyWrites applies a generate plan, reporting each written file to w.
+func applyWrites(w io.Writer, plan theme.Plan) error {
+	for _, p := range plan.Apps {
+		msg, err := p.Apply()
+		if err != nil {
+			return err
 		}
+		fmt.Fprintf(w, "  %s -> %s\n", p.App, msg)
+	}
+	return nil
+}
+
+// selectedAdapters returns the adapters named by --adapters, or all of them.
+func selectedAdapters() []adapter.Adapter {
+	if adaptersFlag != "" {
+		return adapter.ByName(strings.Split(adaptersFlag, ","))
+	}
+	return adapter.All()
+}
+
+// runGenerateWarehouse regenerates every theme under --themes-dir in place.
+// Themes render in parallel; files are written and reported afterwards in
+// theme order so output is deterministic.
+func runGenerateWarehouse(cmd *cobra.Command) error {
+	out := cmd.OutOrStdout()
+
+	selected := selectedAdapters()
+	if len(selected) == 0 {
+		fmt.Fprintln(out, "No adapters registered. Nothing to generate.")
+		return nil
+	}
+
+	names, err := theme.ListThemes(generateThemesDir)
+	if err != nil {
+		return err
+	}
 
-		dir := filepath.Join(outDir, a.DirName())
-		if err := os.MkdirAll(dir, 0o755); err != nil {
-			return fmt.Errorf("creating directory %s: %w", dir, err)
+	type result struct {
+		theme     theme.Theme
+		generated []theme.Generated
+		err       error
+	}
+	results := make([]result, len(names))
+	var wg sync.WaitGroup
+	for i, name := range names {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			t, err := theme.LoadThemeWith(generateThemesDir, name, palette.LoadOpts{Lenient: lenientFlag})
+			if err != nil {
+				results[i].err = err
+				return
+			}
+			generated, err := theme.Generate(t, selected)
+			if err != nil {
+				err = fmt.Errorf("generating %s: %w", name, err)
+			}
+			results[i] = result{theme: t, generated: generated, err: err}
+		}()
+	}
+	wg.Wait()
+
+	// Refuse to write anything if any theme failed to render, so a bad
+	// palette doesn't leave the warehouse half regenerated.
+	var errs []error
+	for _, r := range results {
+		if r.err != nil {
+			errs = append(errs, r.err)
 		}
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
 
-		filePath := filepath.Join(dir, a.FileName(cfg.Theme.Name))
-		if err := os.WriteFile(filePath, content, 0o644); err != nil {
-			return fmt.Errorf("writing %s: %w", filePath, err)
+	files := 0
+	for i, r := range results {
+		fmt.Fprintf(out, "%s:\n", names[i])
+		var plan theme.Plan
+		for _, g := range r.generated {
+			rel, err := filepath.Rel(r.theme.Dir, g.Path)
+			if err != nil {
+				rel = g.Path
+			}
+			p := writePlan(g.Adapter, g.Path, g.Content)
+			p.Message = rel
+			plan.Apps = append(plan.Apps, p)
 		}
 
-		fmt.Fprintf(cmd.OutOrStdout(), "  %s -> %s\n", a.Name(), filePath)
+		if generateDryRun {
+			if err := printPlan(out, plan); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := applyWrites(out, plan); err != nil {
+			return err
+		}
+		files += len(plan.Apps)
+	}
+	if generateDryRun {
+		return nil
 	}
 
-	fmt.Fprintf(cmd.OutOrStdout(), "Generated %d file(s) in %s\n", len(selected), outDir)
+	fmt.Fprintf(out, "Generated %d file(s) across %d theme(s) in %s\n", files, len(names), generateThemesDir)
 	return nil
 }
@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kylesnowschwartz/the-themer/importer"
+	"github.com/kylesnowschwartz/the-themer/palette"
+)
+
+var (
+	importFromFlag      string
+	importNameFlag      string
+	importThemesDirFlag string
+	importForceFlag     bool
+)
+
+var importCmd = &cobra.Command{
+	Use:   "import --from <format> <file>",
+	Short: "Create a theme from another app's theme file",
+	Long: `Import parses a theme written for another terminal app and writes it as
+a palette.toml in a new theme directory. The variant (dark or light) is
+inferred from the background color. Keys the source format lacks are left
+out so they are derived at generate time.
+
+Formats: ` + strings.Join(importer.Formats(), ", ") + `
+
+Examples:
+  the-themer import --from ghostty ~/.config/ghostty/themes/dayfox
+  the-themer import --from itermcolors Nord.itermcolors --name nord`,
+	Args: cobra.ExactArgs(1),
+	RunE: runImport,
+}
+
+func init() {
+	rootCmd.AddCommand(importCmd)
+	importCmd.Flags().StringVar(&importFromFlag, "from", "", "source format: "+strings.Join(importer.Formats(), "|")+" (required)")
+	importCmd.Flags().StringVar(&importNameFlag, "name", "", "theme name (default: derived from the file name)")
+	importCmd.Flags().StringVar(&importThemesDirFlag, "themes-dir", defaultThemesDir(), "path to the themes directory")
+	importCmd.Flags().BoolVar(&importForceFlag, "force", false, "overwrite an existing theme's palette.toml")
+
+	importCmd.MarkFlagRequired("from")
+}
+
+func runImport(cmd *cobra.Command, args []string) error {
+	src := args[0]
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return fmt.Errorf("reading %s: %w", src, err)
+	}
+
+	name := importNameFlag
+	if name == "" {
+		name = themeNameFromFile(src)
+	}
+
+	cfg, err := importer.Import(importFromFlag, data, name)
+	if err != nil {
+		return err
+	}
+
+	// Refuse palettes that would not load, so the warehouse never holds a
+	// theme that generate rejects.
+	check := cfg
+	if err := check.ResolveExpressions(); err != nil {
+		return fmt.Errorf("imported palette is incomplete:\n%w", err)
+	}
+	check.ApplyDefaults()
+	if err := check.Validate(); err != nil {
+		return fmt.Errorf("imported palette is incomplete:\n%w", err)
+	}
+
+	dir := filepath.Join(importThemesDirFlag, name)
+	path := filepath.Join(dir, "palette.toml")
+	if _, err := os.Stat(path); err == nil && !importForceFlag {
+		return fmt.Errorf("%s already exists (use --force to overwrite)", path)
+	} else if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("creating directory %s: %w", dir, err)
+	}
+	header := fmt.Sprintf("# %s palette\n# Source: imported from %s (%s)\n\n", name, filepath.Base(src), importFromFlag)
+	content := append([]byte(header), palette.Encode(cfg)...)
+	if err := os.WriteFile(path, content, 0o644); err != nil {
+		return fmt.Errorf("writing %s: %w", path, err)
+	}
+
+	fmt.Fprintf(cmd.OutOrStdout(), "Imported %s (%s) -> %s\n", name, cfg.Theme.Variant, path)
+	fmt.Fprintf(cmd.OutOrStdout(), "Generate its configs with: the-themer generate -i %s -o %s\n", path, dir)
+	return nil
+}
+
+// themeNameFromFile derives a warehouse theme name from a source file name:
+// "Tokyo Night (Storm).itermcolors" becomes "tokyo-night-storm".
+func themeNameFromFile(path string) string {
+	base := filepath.Base(path)
+	base = strings.TrimSuffix(base, filepath.Ext(base))
+	words := strings.FieldsFunc(strings.ToLower(base), func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9')
+	})
+	return strings.Join(words, "-")
+}
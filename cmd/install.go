@@ -5,10 +5,15 @@ import (
 
 	"github.com/spf13/cobra"
 
+	"github.com/kylesnowschwartz/the-themer/palette"
 	"github.com/kylesnowschwartz/the-themer/theme"
 )
 
-var installThemesDir string
+var (
+	installThemesDir string
+	installLenient   bool
+	installDryRun    bool
+)
 
 var installCmd = &cobra.Command{
 	Use:   "install <theme-name>",
@@ -17,7 +22,10 @@ var installCmd = &cobra.Command{
 locations where each application expects them.
 
 Only apps with config directories in the theme are installed. Apps without
-config in the theme are skipped.`,
+config in the theme are skipped.
+
+Use --dry-run to print every planned copy and external command, with a
+diff against what is installed now, without changing anything.`,
 	Args: cobra.ExactArgs(1),
 	RunE: runInstall,
 }
@@ -25,17 +33,30 @@ config in the theme are skipped.`,
 func init() {
 	rootCmd.AddCommand(installCmd)
 	installCmd.Flags().StringVar(&installThemesDir, "themes-dir", defaultThemesDir(), "path to the themes directory")
+	installCmd.Flags().BoolVar(&installLenient, "lenient", false, "ignore unknown palette keys instead of reporting them")
+	installCmd.Flags().BoolVar(&installDryRun, "dry-run", false, "print the planned changes as diffs without making any")
 }
 
 func runInstall(cmd *cobra.Command, args []string) error {
 	themeName := args[0]
 
-	t, err := theme.LoadTheme(installThemesDir, themeName)
+	t, err := theme.LoadThemeWith(installThemesDir, themeName, palette.LoadOpts{Lenient: installLenient})
 	if err != nil {
 		return err
 	}
+	if err := migrateState(cmd, installDryRun); err != nil {
+		return err
+	}
+
+	if installDryRun {
+		plan, err := theme.PlanInstall(t, theme.InstallOpts{Config: userConfig})
+		if err != nil {
+			return err
+		}
+		return printPlan(cmd.OutOrStdout(), plan)
+	}
 
-	results := theme.Install(t, theme.InstallOpts{})
+	results := theme.Install(t, theme.InstallOpts{Config: userConfig})
 
 	var hasErrors bool
 	for _, r := range results {
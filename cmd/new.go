@@ -0,0 +1,152 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kylesnowschwartz/the-themer/adapter"
+	"github.com/kylesnowschwartz/the-themer/derive"
+	"github.com/kylesnowschwartz/the-themer/palette"
+	"github.com/kylesnowschwartz/the-themer/palette/color"
+)
+
+var (
+	newBGFlag        string
+	newFGFlag        string
+	newAccentFlag    string
+	newVariantFlag   string
+	newHueFlags      []string
+	newThemesDirFlag string
+	newForceFlag     bool
+)
+
+var newCmd = &cobra.Command{
+	Use:   "new <theme-name>",
+	Short: "Derive a complete theme from a few seed colors",
+	Long: `New derives a full palette from background, foreground, and accent
+seeds: chromatic normals and brights at shared OKLCH lightness levels solved
+to clear the audit's contrast thresholds, greys tinted by the background,
+and UI and syntax tokens. It writes themes/<name>/palette.toml and runs
+every adapter into the theme directory.
+
+Hue overrides take an ANSI slot number or family name and an OKLCH hue in
+degrees. Overriding a normal color also moves its bright partner.
+
+Examples:
+  the-themer new harbor --bg '#142838' --fg '#e8f4f8' --accent '#3ba5ff'
+  the-themer new paper --bg '#fdf6e3' --fg '#073642' --accent '#268bd2' --hue red=20 --hue bright-cyan=190`,
+	Args: cobra.ExactArgs(1),
+	RunE: runNew,
+}
+
+func init() {
+	rootCmd.AddCommand(newCmd)
+	newCmd.Flags().StringVar(&newBGFlag, "bg", "", "background color (required)")
+	newCmd.Flags().StringVar(&newFGFlag, "fg", "", "foreground color (required)")
+	newCmd.Flags().StringVar(&newAccentFlag, "accent", "", "accent color for cursor and UI highlights (required)")
+	newCmd.Flags().StringVar(&newVariantFlag, "variant", "", "dark or light (default: inferred from --bg)")
+	newCmd.Flags().StringArrayVar(&newHueFlags, "hue", nil, "OKLCH hue override as <slot>=<degrees>, e.g. 1=25 or bright-blue=250 (repeatable)")
+	newCmd.Flags().StringVar(&newThemesDirFlag, "themes-dir", defaultThemesDir(), "path to the themes directory")
+	newCmd.Flags().BoolVar(&newForceFlag, "force", false, "overwrite an existing theme directory's files")
+
+	newCmd.MarkFlagRequired("bg")
+	newCmd.MarkFlagRequired("fg")
+	newCmd.MarkFlagRequired("accent")
+}
+
+func runNew(cmd *cobra.Command, args []string) error {
+	name := args[0]
+
+	var s derive.Seeds
+	for _, seed := range []struct {
+		flag, value string
+		dst         *color.RGB
+	}{{"bg", newBGFlag, &s.BG}, {"fg", newFGFlag, &s.FG}, {"accent", newAccentFlag, &s.Accent}} {
+		c, err := color.ParseHex(seed.value)
+		if err != nil {
+			return fmt.Errorf("--%s: %w", seed.flag, err)
+		}
+		*seed.dst = c
+	}
+	s.Variant = newVariantFlag
+
+	hues, err := parseHueFlags(newHueFlags)
+	if err != nil {
+		return err
+	}
+	s.Hues = hues
+
+	cfg, err := derive.FromSeeds(name, s)
+	if err != nil {
+		return err
+	}
+
+	dir := filepath.Join(newThemesDirFlag, name)
+	path := filepath.Join(dir, "palette.toml")
+	if _, err := os.Stat(path); err == nil && !newForceFlag {
+		return fmt.Errorf("%s already exists (use --force to overwrite)", path)
+	} else if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("creating directory %s: %w", dir, err)
+	}
+
+	header := fmt.Sprintf("# %s palette\n# Derived by the-themer new from bg %s, fg %s, accent %s.\n\n",
+		name, s.BG.Hex(), s.FG.Hex(), s.Accent.Hex())
+	if err := os.WriteFile(path, append([]byte(header), palette.Encode(cfg)...), 0o644); err != nil {
+		return fmt.Errorf("writing %s: %w", path, err)
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "  palette -> %s\n", path)
+
+	// Generate from the file just written so the outputs match what a later
+	// "generate" run would produce.
+	loaded, err := palette.Load(path)
+	if err != nil {
+		return err
+	}
+	if err := writeAdapters(cmd.OutOrStdout(), loaded, dir, adapter.All()); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(cmd.OutOrStdout(), "Created %s theme %q in %s\n", cfg.Theme.Variant, name, dir)
+	return nil
+}
+
+// hueSlotNames maps family names to chromatic ANSI slots for --hue.
+var hueSlotNames = map[string]int{
+	"red": 1, "green": 2, "yellow": 3, "blue": 4, "magenta": 5, "cyan": 6,
+	"bright-red": 9, "bright-green": 10, "bright-yellow": 11,
+	"bright-blue": 12, "bright-magenta": 13, "bright-cyan": 14,
+}
+
+// parseHueFlags parses repeated "<slot>=<degrees>" values.
+func parseHueFlags(values []string) (map[int]float64, error) {
+	hues := map[int]float64{}
+	for _, v := range values {
+		key, deg, ok := strings.Cut(v, "=")
+		if !ok {
+			return nil, fmt.Errorf("--hue %q: want <slot>=<degrees>", v)
+		}
+		slot, known := hueSlotNames[strings.ToLower(key)]
+		if !known {
+			n, err := strconv.Atoi(key)
+			if err != nil {
+				return nil, fmt.Errorf("--hue %q: unknown slot %q", v, key)
+			}
+			slot = n
+		}
+		h, err := strconv.ParseFloat(deg, 64)
+		if err != nil {
+			return nil, fmt.Errorf("--hue %q: invalid hue %q", v, deg)
+		}
+		hues[slot] = h
+	}
+	return hues, nil
+}
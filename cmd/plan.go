@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/kylesnowschwartz/the-themer/theme"
+)
+
+// printPlan reports a dry run: each app's planned actions, with a unified
+// diff of what every write, copy and symlink would change. Apps that
+// couldn't be planned are reported and make the dry run fail.
+func printPlan(w io.Writer, plan theme.Plan) error {
+	actions, failed := 0, 0
+	for _, p := range plan.Apps {
+		switch {
+		case p.Err != nil:
+			fmt.Fprintf(w, "  %s: ERROR %v\n", p.App, p.Err)
+			failed++
+			continue
+		case p.Skipped:
+			fmt.Fprintf(w, "  %s: skipped (%s)\n", p.App, p.Message)
+			continue
+		}
+
+		fmt.Fprintf(w, "  %s: %s\n", p.App, p.Message)
+		for _, a := range p.Actions {
+			actions++
+			fmt.Fprintf(w, "    %s\n", a)
+			d, err := a.Diff()
+			if err != nil {
+				return err
+			}
+			fmt.Fprint(w, d)
+		}
+	}
+	fmt.Fprintf(w, "Dry run: %d planned action(s), nothing changed.\n", actions)
+	if failed > 0 {
+		return fmt.Errorf("%d app(s) could not be planned", failed)
+	}
+	return nil
+}
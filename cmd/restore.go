@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kylesnowschwartz/the-themer/theme"
+)
+
+var restoreListFlag bool
+
+var restoreCmd = &cobra.Command{
+	Use:   "restore [timestamp]",
+	Short: "Put back user files the themer replaced",
+	Long: `Before install or switch first replaces a file the themer didn't write
+itself (a hand-written ~/.config/gh-dash/config.yml, say), it saves the
+file under ~/.config/the-themer/backups/<timestamp>/ with a manifest.
+
+Restore puts every file in a backup back where it was: the latest backup,
+or the one named by its timestamp. Use --list to see the backups.
+
+Examples:
+  the-themer restore --list
+  the-themer restore
+  the-themer restore 20260101T120000Z`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runRestore,
+}
+
+func init() {
+	rootCmd.AddCommand(restoreCmd)
+	restoreCmd.Flags().BoolVar(&restoreListFlag, "list", false, "list backups instead of restoring one")
+}
+
+func runRestore(cmd *cobra.Command, args []string) error {
+	out := cmd.OutOrStdout()
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return fmt.Errorf("resolving home directory: %w", err)
+	}
+
+	if restoreListFlag {
+		backups, err := theme.ListBackups(home)
+		if err != nil {
+			return err
+		}
+		if len(backups) == 0 {
+			fmt.Fprintln(out, "No backups.")
+		}
+		for _, b := range backups {
+			fmt.Fprintf(out, "%s  %d file(s)\n", b.ID, len(b.Files))
+			for _, f := range b.Files {
+				fmt.Fprintf(out, "  %s\n", f.Path)
+			}
+		}
+		return nil
+	}
+
+	var id string
+	if len(args) == 1 {
+		id = args[0]
+	}
+	if err := migrateState(cmd, false); err != nil {
+		return err
+	}
+	b, err := theme.RestoreBackup(home, id)
+	if err != nil {
+		return err
+	}
+	for _, f := range b.Files {
+		fmt.Fprintf(out, "  restored %s\n", f.Path)
+	}
+	fmt.Fprintf(out, "Restored backup %s\n", b.ID)
+	return nil
+}
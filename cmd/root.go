@@ -2,6 +2,10 @@
 package cmd
 
 import (
+	"fmt"
+	"slices"
+	"strings"
+
 	"github.com/spf13/cobra"
 )
 
@@ -12,20 +16,71 @@ var rootCmd = &cobra.Command{
 bat, delta, fzf, starship, eza, gh-dash, neovim).
 
 Commands:
-  generate   Render per-app configs from a palette TOML
-  install    Deploy a theme's configs to the filesystem
-  switch     Activate a theme across all configured apps
-  set        Configure default themes for "dark" and "light" aliases
+{{commands}}
 
 Set your defaults once, then switch by variant:
   the-themer set dark cobalt-next-neon
   the-themer set light dayfox
-  the-themer switch dark`,
-	SilenceUsage:  true, // don't dump usage on every RunE error
-	SilenceErrors: true, // main.go handles error printing
+  the-themer switch dark
+
+Configure the-themer itself in $XDG_CONFIG_HOME/the-themer/config.toml
+(~/.config/the-themer/config.toml): the default themes directory, apps to
+leave alone, reload behaviour, and per-app destination paths.
+
+  themes_dir = "~/src/the-themer/themes"
+  disabled = ["neovim"]
+
+  [reload]
+  ghostty = false     # don't send a running Ghostty its reload keybind
+  bat_cache = false   # don't run "bat cache --build"
+
+  [apps.starship]
+  file = "~/.config/starship/starship.toml"
+
+Apps with a configurable dir (where install copies themes): ghostty, bat,
+tcm, eza. Apps with a configurable file (what switch writes or links):
+ghostty, bat, delta, fzf, tcm, starship, eza, gh-dash, hud, pi.`,
+	PersistentPreRunE: loadConfig,
+	SilenceUsage:      true, // don't dump usage on every RunE error
+	SilenceErrors:     true, // main.go handles error printing
+}
+
+// helpOrder is the order commands are listed in the root help, roughly
+// the order a new user needs them. Commands missing here are listed after.
+var helpOrder = []string{
+	"generate", "new", "derive-variant", "install", "uninstall", "switch",
+	"restore", "doctor", "set", "list", "audit", "check", "import", "schema",
+}
+
+// commandList formats the root command's subcommands, one per line.
+func commandList() string {
+	var ordered []*cobra.Command
+	for _, name := range helpOrder {
+		if c, _, err := rootCmd.Find([]string{name}); err == nil && c != rootCmd {
+			ordered = append(ordered, c)
+		}
+	}
+	for _, c := range rootCmd.Commands() {
+		if c.IsAvailableCommand() && c.Name() != "help" && c.Name() != "completion" && !slices.Contains(ordered, c) {
+			ordered = append(ordered, c)
+		}
+	}
+
+	var b strings.Builder
+	for _, c := range ordered {
+		if len(c.Name()) > 10 {
+			fmt.Fprintf(&b, "  %s\n  %-10s %s\n", c.Name(), "", c.Short)
+		} else {
+			fmt.Fprintf(&b, "  %-10s %s\n", c.Name(), c.Short)
+		}
+	}
+	return strings.TrimSuffix(b.String(), "\n")
 }
 
-// Execute runs the root command.
+// Execute runs the root command. The root help's command list is built
+// here, once every command has registered, from each command's Short so
+// the two can't drift.
 func Execute() error {
+	rootCmd.Long = strings.Replace(rootCmd.Long, "{{commands}}", commandList(), 1)
 	return rootCmd.Execute()
 }
@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kylesnowschwartz/the-themer/palette"
+)
+
+var schemaOutputFlag string
+
+var schemaCmd = &cobra.Command{
+	Use:   "schema",
+	Short: "Print the JSON Schema for palette.toml",
+	Long: `Schema prints a JSON Schema describing palette.toml: its tables and keys,
+which keys are required, the accepted color formats, and a description of
+each key. TOML language servers such as taplo use it to validate and
+complete palettes as you edit them.
+
+Examples:
+  the-themer schema -o palette.schema.json
+
+then point a palette at it with a directive on its first line:
+  #:schema ./palette.schema.json`,
+	Args: cobra.NoArgs,
+	RunE: runSchema,
+}
+
+func init() {
+	rootCmd.AddCommand(schemaCmd)
+	schemaCmd.Flags().StringVarP(&schemaOutputFlag, "output", "o", "", "write the schema to a file instead of stdout")
+}
+
+func runSchema(cmd *cobra.Command, args []string) error {
+	if schemaOutputFlag == "" {
+		_, err := cmd.OutOrStdout().Write(palette.JSONSchema())
+		return err
+	}
+	if err := os.WriteFile(schemaOutputFlag, palette.JSONSchema(), 0o644); err != nil {
+		return fmt.Errorf("writing schema: %w", err)
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "Wrote %s\n", schemaOutputFlag)
+	return nil
+}
@@ -49,6 +49,9 @@ func runSet(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("resolving home directory: %w", err)
 	}
 
+	if err := migrateState(cmd, false); err != nil {
+		return err
+	}
 	if err := theme.WriteDefault(home, variant, themeName); err != nil {
 		return err
 	}
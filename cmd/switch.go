@@ -6,10 +6,18 @@ import (
 
 	"github.com/spf13/cobra"
 
+	"github.com/kylesnowschwartz/the-themer/palette"
 	"github.com/kylesnowschwartz/the-themer/theme"
 )
 
-var switchThemesDir string
+var (
+	switchThemesDir  string
+	switchLenient    bool
+	switchDryRun     bool
+	switchBestEffort bool
+	switchForce      bool
+	switchNoInstall  bool
+)
 
 var switchCmd = &cobra.Command{
 	Use:   "switch <theme-name>",
@@ -21,6 +29,22 @@ swapping symlinks (starship, fzf, eza), and invoking external tools
 
 Only apps configured for the theme are switched. Others are skipped.
 
+Installed files that are missing or differ from the warehouse are
+installed first, so switching to a theme you never installed just works.
+Pass --no-install to switch with whatever is installed.
+
+Switching is all or nothing: if any app fails, every file and symlink
+already switched is restored to what it was before. Pass --best-effort to
+keep going past failures and leave the apps that did switch switched.
+
+Files the themer didn't write are backed up before they're first replaced
+(see "the-themer restore"). Switch won't replace a regular file where it
+keeps a symlink, like a hand-written ~/.config/starship.toml, unless you
+pass --force.
+
+Use --dry-run to print every planned write, symlink and external command,
+with a diff against what is there now, without changing anything.
+
 You can pass "dark" or "light" as the theme name to switch to the
 default theme for that variant. Set defaults with:
   the-themer set dark <theme-name>
@@ -32,6 +56,11 @@ default theme for that variant. Set defaults with:
 func init() {
 	rootCmd.AddCommand(switchCmd)
 	switchCmd.Flags().StringVar(&switchThemesDir, "themes-dir", defaultThemesDir(), "path to the themes directory")
+	switchCmd.Flags().BoolVar(&switchLenient, "lenient", false, "ignore unknown palette keys instead of reporting them")
+	switchCmd.Flags().BoolVar(&switchDryRun, "dry-run", false, "print the planned changes as diffs without making any")
+	switchCmd.Flags().BoolVar(&switchBestEffort, "best-effort", false, "keep going past failing apps instead of rolling back")
+	switchCmd.Flags().BoolVar(&switchForce, "force", false, "replace regular files where switch keeps a symlink (they're backed up first)")
+	switchCmd.Flags().BoolVar(&switchNoInstall, "no-install", false, "don't install missing or out-of-date theme files first")
 }
 
 func runSwitch(cmd *cobra.Command, args []string) error {
@@ -54,18 +83,40 @@ func runSwitch(cmd *cobra.Command, args []string) error {
 		themeName = resolved
 	}
 
-	t, err := theme.LoadTheme(switchThemesDir, themeName)
+	t, err := theme.LoadThemeWith(switchThemesDir, themeName, palette.LoadOpts{Lenient: switchLenient})
 	if err != nil {
 		return err
 	}
+	if err := migrateState(cmd, switchDryRun); err != nil {
+		return err
+	}
+
+	if switchDryRun {
+		plan, err := theme.PlanSwitch(t, theme.SwitchOpts{Config: userConfig, Force: switchForce, NoInstall: switchNoInstall})
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "  state: would record %q as the current theme\n", themeName)
+		return printPlan(cmd.OutOrStdout(), plan)
+	}
 
-	results := theme.Switch(t, theme.SwitchOpts{})
+	return applySwitch(cmd, t, theme.SwitchOpts{Config: userConfig, BestEffort: switchBestEffort, Force: switchForce, NoInstall: switchNoInstall})
+}
+
+// applySwitch switches to t, reports each app, and records t as the
+// current theme if every app switched.
+func applySwitch(cmd *cobra.Command, t theme.Theme, opts theme.SwitchOpts) error {
+	themeName := t.Name
+	results := theme.Switch(t, opts)
 
-	var hasErrors bool
+	var hasErrors, rolledBack bool
 	for _, r := range results {
 		if r.Err != nil {
 			fmt.Fprintf(cmd.ErrOrStderr(), "  %s: ERROR %v\n", r.App, r.Err)
 			hasErrors = true
+		} else if r.RolledBack {
+			fmt.Fprintf(cmd.OutOrStdout(), "  %s: rolled back (%s)\n", r.App, r.Message)
+			rolledBack = true
 		} else if r.Skipped {
 			fmt.Fprintf(cmd.OutOrStdout(), "  %s: skipped (%s)\n", r.App, r.Message)
 		} else {
@@ -73,6 +124,12 @@ func runSwitch(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	if hasErrors && !opts.BestEffort {
+		if rolledBack {
+			return fmt.Errorf("switch to %q failed; switched apps were rolled back (use --best-effort to keep them)", themeName)
+		}
+		return fmt.Errorf("switch to %q failed", themeName)
+	}
 	if hasErrors {
 		return fmt.Errorf("some apps failed to switch")
 	}
@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kylesnowschwartz/the-themer/palette"
+	"github.com/kylesnowschwartz/the-themer/theme"
+)
+
+var (
+	uninstallThemesDir string
+	uninstallLenient   bool
+	uninstallDryRun    bool
+	uninstallFallback  bool
+)
+
+var uninstallCmd = &cobra.Command{
+	Use:   "uninstall <theme-name>",
+	Short: "Remove a theme's installed configs",
+	Long: `Uninstall reverses install: it removes the files install copied for
+each app (ghostty and eza themes, bat themes, the-themer's own copies),
+drops any per-theme git include.path entry an older install added, and
+rebuilds bat's cache.
+
+Uninstall refuses to remove the active theme, since that would leave apps
+pointing at missing files. Pass --fallback to switch to your default theme
+for the same variant (see "the-themer set") first.
+
+Use --dry-run to print every planned removal and external command without
+changing anything.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runUninstall,
+}
+
+func init() {
+	rootCmd.AddCommand(uninstallCmd)
+	uninstallCmd.Flags().StringVar(&uninstallThemesDir, "themes-dir", defaultThemesDir(), "path to the themes directory")
+	uninstallCmd.Flags().BoolVar(&uninstallLenient, "lenient", false, "ignore unknown palette keys instead of reporting them")
+	uninstallCmd.Flags().BoolVar(&uninstallDryRun, "dry-run", false, "print the planned changes without making any")
+	uninstallCmd.Flags().BoolVar(&uninstallFallback, "fallback", false, "if the theme is active, switch to the default theme for its variant first")
+}
+
+func runUninstall(cmd *cobra.Command, args []string) error {
+	themeName := args[0]
+
+	t, err := theme.LoadThemeWith(uninstallThemesDir, themeName, palette.LoadOpts{Lenient: uninstallLenient})
+	if err != nil {
+		return err
+	}
+	if err := migrateState(cmd, uninstallDryRun); err != nil {
+		return err
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return fmt.Errorf("resolving home directory: %w", err)
+	}
+
+	current, err := theme.ReadState(home)
+	if err != nil {
+		return err
+	}
+	if current == themeName {
+		variant := t.Config.Theme.Variant
+		if !uninstallFallback {
+			return fmt.Errorf("theme %q is active; switch to another theme first, or pass --fallback to switch to the %s default", themeName, variant)
+		}
+		fallback, err := theme.ReadDefault(home, variant)
+		if err != nil {
+			return err
+		}
+		if fallback == "" || fallback == themeName {
+			return fmt.Errorf("theme %q is active and there is no other %s default to fall back to — use \"the-themer set %s <theme-name>\" first", themeName, variant, variant)
+		}
+
+		fb, err := theme.LoadThemeWith(uninstallThemesDir, fallback, palette.LoadOpts{Lenient: uninstallLenient})
+		if err != nil {
+			return err
+		}
+		if uninstallDryRun {
+			fmt.Fprintf(cmd.OutOrStdout(), "  state: would switch to %q first\n", fallback)
+		} else {
+			fmt.Fprintf(cmd.OutOrStdout(), "Falling back to %q\n", fallback)
+			if err := applySwitch(cmd, fb, theme.SwitchOpts{Config: userConfig}); err != nil {
+				return err
+			}
+		}
+	}
+
+	if uninstallDryRun {
+		plan, err := theme.PlanUninstall(t, theme.UninstallOpts{Config: userConfig})
+		if err != nil {
+			return err
+		}
+		return printPlan(cmd.OutOrStdout(), plan)
+	}
+
+	results := theme.Uninstall(t, theme.UninstallOpts{Config: userConfig})
+
+	var hasErrors bool
+	for _, r := range results {
+		if r.Err != nil {
+			fmt.Fprintf(cmd.ErrOrStderr(), "  %s: ERROR %v\n", r.App, r.Err)
+			hasErrors = true
+		} else if r.Skipped {
+			fmt.Fprintf(cmd.OutOrStdout(), "  %s: skipped (%s)\n", r.App, r.Message)
+		} else {
+			fmt.Fprintf(cmd.OutOrStdout(), "  %s: %s\n", r.App, r.Message)
+		}
+	}
+
+	if hasErrors {
+		return fmt.Errorf("some apps failed to uninstall")
+	}
+
+	for _, variant := range []string{"dark", "light"} {
+		if d, err := theme.ReadDefault(home, variant); err == nil && d == themeName {
+			fmt.Fprintf(cmd.ErrOrStderr(), "  default-%s: WARNING %q is still the %s default\n", variant, themeName, variant)
+		}
+	}
+
+	fmt.Fprintf(cmd.OutOrStdout(), "Uninstalled theme %q\n", themeName)
+	return nil
+}
@@ -0,0 +1,345 @@
+// Package derive builds complete palettes from a few seed colors. Every
+// derived color is placed in OKLCH: hue from the audit's hue families (or
+// an override), lightness solved against the background so APCA contrast
+// clears the audit's warn thresholds with a margin.
+package derive
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/kylesnowschwartz/the-themer/audit"
+	"github.com/kylesnowschwartz/the-themer/palette"
+	"github.com/kylesnowschwartz/the-themer/palette/color"
+)
+
+// Seeds are the inputs to FromSeeds.
+type Seeds struct {
+	BG     color.RGB
+	FG     color.RGB
+	Accent color.RGB
+
+	// Variant is "dark" or "light". When empty it is inferred from BG.
+	Variant string
+
+	// Hues overrides the OKLCH hue (degrees) of chromatic ANSI slots 1-6
+	// and 9-14. Overriding a normal slot also moves its bright partner
+	// unless the bright slot has its own override.
+	Hues map[int]float64
+}
+
+// Tuning. Targets sit above each category's warn floor so small rounding
+// to 8-bit sRGB never drops a color into WARN.
+const (
+	contrastMargin = 5
+
+	normalChroma = 0.14
+	brightChroma = 0.13
+
+	// brightOffset is the minimum lightness step from normal to bright
+	// (lighter in dark themes, darker in light themes).
+	brightOffset = 0.08
+
+	// neutralChroma caps the bg tint carried into the greys.
+	neutralChroma = 0.02
+
+	// Background-adjacent surfaces, as OKLCH lightness steps away from bg.
+	bgMatchStep      = 0.06
+	highlightStep    = 0.04
+	selectionStep    = 0.12
+	minSelectionStep = 0.02
+
+	// Syntax accents not tied to an ANSI family.
+	numberHue = 55 // orange
+	errorHue  = 5  // pink-red, distinct from color1
+)
+
+// target returns the |Lc| a derived color in category should reach.
+func target(category string) float64 {
+	t := audit.ContrastThresholds[category]
+	if t.WarnBelow != 0 {
+		return t.WarnBelow + contrastMargin
+	}
+	return t.FailBelow + contrastMargin
+}
+
+// deriver carries the per-palette context the solvers need.
+type deriver struct {
+	bg      color.RGB
+	bgL     float64
+	dark    bool
+	neutral color.OKLCH // bg-tinted grey: L is ignored
+}
+
+// FromSeeds derives a complete, explicit palette for a theme called name.
+// It fails when the seeds themselves miss the audit's fail thresholds
+// (fg as body text, accent as a UI element) or when the background leaves
+// no room to reach the required contrast.
+func FromSeeds(name string, s Seeds) (palette.Config, error) {
+	variant := s.Variant
+	if variant == "" {
+		variant = "dark"
+		if s.BG.IsLight() {
+			variant = "light"
+		}
+	}
+	if variant != "dark" && variant != "light" {
+		return palette.Config{}, fmt.Errorf("variant must be dark or light, got %q", variant)
+	}
+
+	bg := s.BG.OKLCH()
+	d := deriver{
+		bg:      s.BG,
+		bgL:     bg.L,
+		dark:    variant == "dark",
+		neutral: color.OKLCH{C: math.Min(bg.C, neutralChroma), H: bg.H},
+	}
+
+	if err := checkSeed("fg", s.FG, s.BG, audit.CategoryBody); err != nil {
+		return palette.Config{}, err
+	}
+	if err := checkSeed("accent", s.Accent, s.BG, audit.CategoryUIElement); err != nil {
+		return palette.Config{}, err
+	}
+
+	hues, err := slotHues(s)
+	if err != nil {
+		return palette.Config{}, err
+	}
+
+	var ansi [16]color.RGB
+
+	// Chromatic normals share one lightness, brights another, so the
+	// palette reads as an even set rather than per-hue patches.
+	normalL, err := d.commonL(hues[1:7], normalChroma, target(audit.CategoryChromaticNormal), d.bgL)
+	if err != nil {
+		return palette.Config{}, fmt.Errorf("deriving normal colors: %w", err)
+	}
+	brightL, err := d.commonL(hues[9:15], brightChroma, target(audit.CategoryChromaticBright), d.step(normalL, brightOffset))
+	if err != nil {
+		return palette.Config{}, fmt.Errorf("deriving bright colors: %w", err)
+	}
+	for i := 1; i <= 6; i++ {
+		ansi[i] = color.OKLCH{L: normalL, C: normalChroma, H: hues[i]}.RGB()
+		ansi[i+8] = color.OKLCH{L: brightL, C: brightChroma, H: hues[i+8]}.RGB()
+	}
+
+	// Achromatic slots: the text end of the pair is fg, the bg end sits just
+	// off bg, and 7/8 are greys at comment contrast (7 the stronger).
+	comment := target(audit.CategoryComment)
+	grey8, err := d.neutralAt(comment)
+	if err != nil {
+		return palette.Config{}, fmt.Errorf("deriving color8: %w", err)
+	}
+	grey7, err := d.neutralAt(comment + 15)
+	if err != nil {
+		return palette.Config{}, fmt.Errorf("deriving color7: %w", err)
+	}
+	bgMatch := d.surface(bgMatchStep)
+	if d.dark {
+		ansi[0], ansi[15] = bgMatch, s.FG
+	} else {
+		ansi[0], ansi[15] = s.FG, bgMatch
+	}
+	ansi[7], ansi[8] = grey7, grey8
+
+	structural := target(audit.CategoryUIStructural)
+	border, err := d.neutralAt(structural)
+	if err != nil {
+		return palette.Config{}, fmt.Errorf("deriving ui.border: %w", err)
+	}
+	dimmed, err := d.neutralAt((structural + comment) / 2)
+	if err != nil {
+		return palette.Config{}, fmt.Errorf("deriving ui.dimmed: %w", err)
+	}
+
+	number, err := d.solve(color.OKLCH{C: normalChroma, H: numberHue}, target(audit.CategoryChromaticNormal), d.bgL)
+	if err != nil {
+		return palette.Config{}, fmt.Errorf("deriving syntax.number: %w", err)
+	}
+	synErr, err := d.solve(color.OKLCH{C: brightChroma, H: errorHue}, target(audit.CategoryChromaticBright), d.bgL)
+	if err != nil {
+		return palette.Config{}, fmt.Errorf("deriving syntax.error: %w", err)
+	}
+
+	hex := func(c color.RGB) palette.Color { return palette.Color(c.Hex()) }
+	p := palette.PaletteColors{
+		BG:          hex(s.BG),
+		FG:          hex(s.FG),
+		Cursor:      hex(s.Accent),
+		CursorText:  hex(s.BG),
+		SelectionBG: hex(d.selection(s.FG, s.Accent)),
+		SelectionFG: hex(s.FG),
+		UI: palette.UI{
+			Border: hex(border),
+			Dimmed: hex(dimmed),
+			Accent: hex(s.Accent),
+			// UI elements need bright-level contrast.
+			Success: hex(ansi[10]),
+			Warning: hex(ansi[11]),
+			Error:   hex(ansi[9]),
+			Info:    hex(ansi[12]),
+		},
+		Syntax: palette.Syntax{
+			Number:        hex(number),
+			Error:         hex(synErr),
+			LineHighlight: hex(d.surface(highlightStep)),
+		},
+	}
+	for i, slot := range []*palette.Color{
+		&p.Color0, &p.Color1, &p.Color2, &p.Color3, &p.Color4, &p.Color5, &p.Color6, &p.Color7,
+		&p.Color8, &p.Color9, &p.Color10, &p.Color11, &p.Color12, &p.Color13, &p.Color14, &p.Color15,
+	} {
+		*slot = hex(ansi[i])
+	}
+
+	return palette.Config{
+		Theme:   palette.Theme{Name: name, Variant: variant},
+		Palette: p,
+	}, nil
+}
+
+// checkSeed rejects a seed color that fails its category against bg.
+func checkSeed(name string, c, bg color.RGB, category string) error {
+	lc := color.APCA(c, bg)
+	if audit.Evaluate(lc, category) == audit.Fail {
+		return fmt.Errorf("%s %s has APCA Lc %.1f against bg %s; %s needs at least %.0f",
+			name, c.Hex(), lc, bg.Hex(), category, audit.ContrastThresholds[category].FailBelow)
+	}
+	return nil
+}
+
+// slotHues returns the hue for every ANSI slot (achromatic slots unused).
+// Families default to their audit centroid; the family nearest the accent
+// adopts the accent's hue when the accent sits well inside the family, so
+// the accent never drags a family toward its neighbour.
+func slotHues(s Seeds) ([16]float64, error) {
+	var hues [16]float64
+	for _, f := range audit.HueFamilies {
+		hues[f.NormalSlot], hues[f.BrightSlot] = f.Centroid, f.Centroid
+	}
+
+	if a := s.Accent.OKLCH(); !a.HueUnreliable() {
+		if f, dist := audit.NearestFamily(a.H); dist <= f.MaxDistance/2 {
+			hues[f.NormalSlot], hues[f.BrightSlot] = a.H, a.H
+		}
+	}
+
+	for slot, h := range s.Hues {
+		f, ok := audit.FamilyForSlot(slot)
+		if !ok {
+			return hues, fmt.Errorf("hue override for slot %d: only chromatic slots 1-6 and 9-14 have a hue", slot)
+		}
+		hues[slot] = math.Mod(math.Mod(h, 360)+360, 360)
+		if slot == f.NormalSlot {
+			if _, own := s.Hues[f.BrightSlot]; !own {
+				hues[f.BrightSlot] = hues[slot]
+			}
+		}
+	}
+	return hues, nil
+}
+
+// step moves lightness l by amount away from bg.
+func (d deriver) step(l, amount float64) float64 {
+	if d.dark {
+		return l + amount
+	}
+	return l - amount
+}
+
+// surface returns a bg-tinted color amount lightness steps off bg, toward
+// the text.
+func (d deriver) surface(amount float64) color.RGB {
+	c := d.neutral
+	c.L = d.step(d.bgL, amount)
+	return c.RGB()
+}
+
+// selection returns a selection background tinted with the accent hue,
+// backing off toward bg until fg on it reads as body text. When fg is
+// already close to the limit on bg itself, the selection keeps most of it.
+func (d deriver) selection(fg, accent color.RGB) color.RGB {
+	// Visibility matters more than margin here: aim just past the warn floor.
+	floor := audit.ContrastThresholds[audit.CategoryBody].WarnBelow
+	want := math.Max(math.Min(floor+1, math.Abs(color.APCA(fg, d.bg))-2), floor)
+
+	c := color.OKLCH{C: 0.05, H: accent.OKLCH().H}
+	for amount := selectionStep; amount >= minSelectionStep; amount -= 0.005 {
+		c.L = d.step(d.bgL, amount)
+		if math.Abs(color.APCA(fg, c.RGB())) >= want {
+			return c.RGB()
+		}
+	}
+	c.L = d.step(d.bgL, minSelectionStep)
+	return c.RGB()
+}
+
+// neutralAt returns the bg-tinted grey closest to bg that reaches |Lc|.
+func (d deriver) neutralAt(lc float64) (color.RGB, error) {
+	return d.solve(d.neutral, lc, d.bgL)
+}
+
+// commonL returns the single lightness at which every hue (at chroma c)
+// reaches |Lc| >= lc, starting no nearer to bg than from.
+func (d deriver) commonL(hues []float64, c, lc, from float64) (float64, error) {
+	l := from
+	for _, h := range hues {
+		rgb, err := d.solve(color.OKLCH{C: c, H: h}, lc, from)
+		if err != nil {
+			return 0, err
+		}
+		hl := rgb.OKLCH().L
+		if d.dark {
+			l = math.Max(l, hl)
+		} else {
+			l = math.Min(l, hl)
+		}
+	}
+	// Solving per hue lands on the boundary; re-check every hue at the
+	// shared lightness in case gamut mapping moved one below target.
+	for _, h := range hues {
+		rgb := color.OKLCH{L: l, C: c, H: h}.RGB()
+		if math.Abs(color.APCA(rgb, d.bg)) < lc {
+			fixed, err := d.solve(color.OKLCH{C: c, H: h}, lc, l)
+			if err != nil {
+				return 0, err
+			}
+			l = fixed.OKLCH().L
+		}
+	}
+	return l, nil
+}
+
+// solve bisects for the lightness, between from and the far end of the
+// scale (white for dark themes, black for light), nearest from at which c
+// reaches |Lc| >= lc against bg.
+func (d deriver) solve(c color.OKLCH, lc, from float64) (color.RGB, error) {
+	near, far := from, 0.0
+	if d.dark {
+		far = 1
+	}
+
+	at := func(l float64) (color.RGB, bool) {
+		c.L = l
+		rgb := c.RGB()
+		return rgb, math.Abs(color.APCA(rgb, d.bg)) >= lc
+	}
+
+	if rgb, ok := at(near); ok {
+		return rgb, nil
+	}
+	if _, ok := at(far); !ok {
+		return color.RGB{}, fmt.Errorf("no color at hue %.0f reaches Lc %.0f against bg %s", c.H, lc, d.bg.Hex())
+	}
+	for i := 0; i < 40; i++ {
+		mid := (near + far) / 2
+		if _, ok := at(mid); ok {
+			far = mid
+		} else {
+			near = mid
+		}
+	}
+	rgb, _ := at(far)
+	return rgb, nil
+}
@@ -0,0 +1,156 @@
+package derive_test
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/kylesnowschwartz/the-themer/audit"
+	"github.com/kylesnowschwartz/the-themer/derive"
+	"github.com/kylesnowschwartz/the-themer/palette/color"
+)
+
+func seeds(bg, fg, accent string) derive.Seeds {
+	return derive.Seeds{
+		BG:     color.MustParseHex(bg),
+		FG:     color.MustParseHex(fg),
+		Accent: color.MustParseHex(accent),
+	}
+}
+
+// seedSlots are contrast rows that measure the caller's own seed colors
+// rather than derived ones.
+var seedSlots = map[string]bool{
+	"fg": true, "selection_fg": true, "color0": true, "color15": true, "ui.accent": true,
+	"cursor on bg": true, "ui.accent on bg": true, "cursor_text on cursor (block)": true,
+	"selection_fg on selection_bg": true,
+}
+
+// TestFromSeeds_PassesAudit derives palettes across dark, light, and
+// extreme backgrounds and requires every derived color to pass the audit
+// outright (no WARN), not just avoid failures.
+func TestFromSeeds_PassesAudit(t *testing.T) {
+	tests := map[string]derive.Seeds{
+		"navy":     seeds("#142838", "#e8f4f8", "#3ba5ff"),
+		"nord":     seeds("#2e3440", "#eceff4", "#88c0d0"),
+		"black":    seeds("#000000", "#ffffff", "#ff8800"),
+		"cream":    seeds("#f6f2ee", "#3d2b5a", "#2848a9"),
+		"white":    seeds("#ffffff", "#000000", "#8800ff"),
+		"solarish": seeds("#fdf6e3", "#073642", "#268bd2"),
+	}
+	for name, s := range tests {
+		t.Run(name, func(t *testing.T) {
+			cfg, err := derive.FromSeeds(name, s)
+			if err != nil {
+				t.Fatalf("FromSeeds: %v", err)
+			}
+			if err := cfg.Validate(); err != nil {
+				t.Fatalf("derived palette invalid: %v", err)
+			}
+			r, err := audit.Run(cfg)
+			if err != nil {
+				t.Fatalf("audit: %v", err)
+			}
+
+			if n := r.Failures(false); n != 0 {
+				t.Errorf("%d audit failure(s)", n)
+			}
+			for _, c := range append(r.Contrast, r.CrossContext...) {
+				if !seedSlots[c.Slot] && c.Severity != audit.Pass && c.Severity != audit.Exempt {
+					t.Errorf("%s %s: Lc %.1f %s (%s)", c.Slot, c.Hex, c.Lc, c.Severity, c.Category)
+				}
+			}
+			for _, h := range r.Hue {
+				if h.Severity != audit.Pass {
+					t.Errorf("hue color%d %s: %s %s", h.Slot, h.Hex, h.Severity, h.Note)
+				}
+			}
+			for _, p := range r.Pairs {
+				if p.Severity != audit.Pass {
+					t.Errorf("pair %s: %s %s", p.Family, p.Severity, p.Note)
+				}
+			}
+			for _, d := range r.Distinguish {
+				if d.Severity == audit.Fail {
+					t.Errorf("color%d/color%d confusable (dE %.3f)", d.SlotA, d.SlotB, d.DeltaE)
+				}
+			}
+		})
+	}
+}
+
+func TestFromSeeds_ConsistentLightness(t *testing.T) {
+	cfg, err := derive.FromSeeds("navy", seeds("#142838", "#e8f4f8", "#3ba5ff"))
+	if err != nil {
+		t.Fatalf("FromSeeds: %v", err)
+	}
+	colors := cfg.Palette.Colors()
+
+	for _, slots := range [][]int{{1, 2, 3, 4, 5, 6}, {9, 10, 11, 12, 13, 14}} {
+		first := colors[slots[0]].RGB().OKLCH().L
+		for _, s := range slots[1:] {
+			if l := colors[s].RGB().OKLCH().L; math.Abs(l-first) > 0.01 {
+				t.Errorf("color%d L = %.3f, want %.3f like color%d", s, l, first, slots[0])
+			}
+		}
+	}
+	normal := colors[1].RGB().OKLCH().L
+	bright := colors[9].RGB().OKLCH().L
+	if bright-normal < 0.075 {
+		t.Errorf("bright L %.3f is not offset from normal L %.3f", bright, normal)
+	}
+
+	if cfg.Theme.Variant != "dark" {
+		t.Errorf("variant = %s, want dark inferred from bg", cfg.Theme.Variant)
+	}
+	if cfg.Palette.UI.Accent != "#3ba5ff" || cfg.Palette.Cursor != "#3ba5ff" {
+		t.Errorf("accent seed not carried: ui.accent=%s cursor=%s", cfg.Palette.UI.Accent, cfg.Palette.Cursor)
+	}
+	// The accent sits well inside the blue family, so blue adopts its hue.
+	if h := colors[4].RGB().OKLCH().H; color.HueDistance(h, color.MustParseHex("#3ba5ff").OKLCH().H) > 3 {
+		t.Errorf("color4 hue = %.1f, want the accent's hue", h)
+	}
+}
+
+func TestFromSeeds_HueOverrides(t *testing.T) {
+	s := seeds("#142838", "#e8f4f8", "#3ba5ff")
+	s.Hues = map[int]float64{1: 15, 14: 190}
+
+	cfg, err := derive.FromSeeds("navy", s)
+	if err != nil {
+		t.Fatalf("FromSeeds: %v", err)
+	}
+	hue := func(slot int) float64 { return cfg.Palette.Colors()[slot].RGB().OKLCH().H }
+
+	for slot, want := range map[int]float64{1: 15, 9: 15, 6: 205, 14: 190} {
+		if d := color.HueDistance(hue(slot), want); d > 3 {
+			t.Errorf("color%d hue = %.1f, want %.0f", slot, hue(slot), want)
+		}
+	}
+
+	s.Hues = map[int]float64{7: 100}
+	if _, err := derive.FromSeeds("navy", s); err == nil || !strings.Contains(err.Error(), "slot 7") {
+		t.Errorf("override on achromatic slot: error = %v", err)
+	}
+}
+
+func TestFromSeeds_RejectsWeakSeeds(t *testing.T) {
+	tests := []struct {
+		seeds derive.Seeds
+		want  string
+	}{
+		{seeds("#142838", "#5a6a78", "#3ba5ff"), "fg #5a6a78 has APCA Lc"},
+		{seeds("#142838", "#e8f4f8", "#1c3850"), "accent #1c3850 has APCA Lc"},
+	}
+	for _, tc := range tests {
+		if _, err := derive.FromSeeds("x", tc.seeds); err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("error = %v, want containing %q", err, tc.want)
+		}
+	}
+
+	s := seeds("#142838", "#e8f4f8", "#3ba5ff")
+	s.Variant = "dim"
+	if _, err := derive.FromSeeds("x", s); err == nil {
+		t.Error("expected error for unknown variant")
+	}
+}
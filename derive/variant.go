@@ -0,0 +1,220 @@
+package derive
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/kylesnowschwartz/the-themer/audit"
+	"github.com/kylesnowschwartz/the-themer/palette"
+	"github.com/kylesnowschwartz/the-themer/palette/color"
+)
+
+// Lightness ranges the opposite variant's bg and fg are pinned into, so a
+// theme with an unusually dim bg still produces a readable counterpart.
+var (
+	lightBG = [2]float64{0.94, 0.98}
+	lightFG = [2]float64{0.22, 0.40}
+	darkBG  = [2]float64{0.16, 0.26}
+	darkFG  = [2]float64{0.86, 0.95}
+)
+
+// OppositeName returns the conventional name for a theme's counterpart:
+// a "-dark"/"-light" suffix is swapped, otherwise the new variant is
+// appended.
+func OppositeName(name, variant string) string {
+	switch {
+	case strings.HasSuffix(name, "-dark"):
+		return strings.TrimSuffix(name, "-dark") + "-light"
+	case strings.HasSuffix(name, "-light"):
+		return strings.TrimSuffix(name, "-light") + "-dark"
+	}
+	return name + "-" + oppositeVariant(variant)
+}
+
+func oppositeVariant(variant string) string {
+	if variant == "light" {
+		return "dark"
+	}
+	return "light"
+}
+
+// Opposite derives cfg's counterpart in the other variant, named name.
+// cfg must be loaded (expressions resolved, defaults applied).
+//
+// Every color keeps its OKLCH hue and chroma while lightness is remapped
+// linearly so bg and fg land in the opposite variant's ranges; the rest of
+// the palette keeps its position between them. Translucent colors are
+// composited over the source bg first. Colors whose contrast then
+// falls below their audit warn floor are pushed away from bg until they
+// clear it. The ANSI black/white anchors (color0, color15) swap so black
+// stays the dark one. Roles the source doesn't set, other than those the
+// contrast passes adjust, are left for defaults to derive. The result
+// links back to cfg as its sibling; references are dropped because they
+// name variant-specific upstream themes.
+func Opposite(cfg palette.Config, name string) (palette.Config, error) {
+	cfg = cfg.Opaque()
+	variant := oppositeVariant(cfg.Theme.Variant)
+
+	bg := cfg.Palette.BG.OKLCH()
+	fg := cfg.Palette.FG.OKLCH()
+	if math.Abs(fg.L-bg.L) < 0.05 {
+		return palette.Config{}, fmt.Errorf("bg and fg are too close in lightness to remap")
+	}
+
+	bgRange, fgRange := lightBG, lightFG
+	if variant == "dark" {
+		bgRange, fgRange = darkBG, darkFG
+	}
+	newBG := clamp(1-bg.L, bgRange)
+	newFG := clamp(1-fg.L, fgRange)
+	remapL := func(l float64) float64 {
+		return newBG + (l-bg.L)*(newFG-newBG)/(fg.L-bg.L)
+	}
+	// Adapter overrides are unresolved, so values that aren't literal colors
+	// (expressions) are kept; they follow the remapped keys they reference.
+	remap := func(v *palette.Color) {
+		rgb, alpha, err := color.ParseHexAlpha(string(*v))
+		if err != nil {
+			return
+		}
+		c := rgb.OKLCH()
+		c.L = math.Max(0, math.Min(1, remapL(c.L)))
+		*v = palette.Color(c.RGB().HexAlpha(alpha))
+	}
+
+	out := palette.Config{
+		Theme: palette.Theme{
+			Name:    name,
+			Author:  cfg.Theme.Author,
+			Variant: variant,
+			Sibling: cfg.Theme.Name,
+		},
+		Palette: cfg.Palette,
+	}
+	out.Palette.Walk(func(_ string, v *palette.Color) { remap(v) })
+	p := &out.Palette
+	p.Color0, p.Color15 = p.Color15, p.Color0
+
+	if len(cfg.Adapters) > 0 {
+		out.Adapters = make(map[string]palette.AdapterConfig, len(cfg.Adapters))
+		for n, a := range cfg.Adapters {
+			a.Palette.Walk(func(_ string, v *palette.Color) { remap(v) })
+			out.Adapters[n] = a
+		}
+	}
+
+	newBGColor := p.BG.RGB()
+	nb := newBGColor.OKLCH()
+	d := deriver{bg: newBGColor, bgL: nb.L, dark: variant == "dark"}
+
+	for _, c := range contrastChecks(p, variant) {
+		if err := d.ensure(c.value, c.category); err != nil {
+			return palette.Config{}, fmt.Errorf("%s: %w", c.key, err)
+		}
+	}
+
+	// Surfaces under text: back the selection off toward bg until
+	// selection_fg reads as body text on it.
+	sel := p.SelectionBG.OKLCH()
+	fgRGB := p.SelectionFG.RGB()
+	floor := audit.ContrastThresholds[audit.CategoryBody].WarnBelow
+	for i := 0; i < 20 && math.Abs(color.APCA(fgRGB, sel.RGB())) < floor; i++ {
+		sel.L += (nb.L - sel.L) * 0.25
+	}
+	p.SelectionBG = palette.Color(sel.RGB().Hex())
+
+	// A block cursor draws cursor_text on cursor; when the remap leaves the
+	// two too close, fall back to whichever of bg and fg reads best.
+	cursor := p.Cursor.RGB()
+	if audit.Evaluate(color.APCA(p.CursorText.RGB(), cursor), audit.CategoryUIElement) == audit.Fail {
+		p.CursorText = p.BG
+		if math.Abs(color.APCA(p.FG.RGB(), cursor)) > math.Abs(color.APCA(newBGColor, cursor)) {
+			p.CursorText = p.FG
+		}
+	}
+
+	// Roles the source left to their defaults stay derived, so they follow
+	// the remapped colors they default to. Colors the passes above may have
+	// adjusted are kept.
+	keep := map[string]bool{"selection_bg": true, "cursor_text": true}
+	for _, c := range contrastChecks(p, variant) {
+		keep[c.key] = true
+	}
+	cfg.RawPalette.Walk(func(key string, v *palette.Color) {
+		if *v != "" {
+			keep[key] = true
+		}
+	})
+	p.Walk(func(key string, v *palette.Color) {
+		if !keep[key] {
+			*v = ""
+		}
+	})
+
+	return out, nil
+}
+
+func clamp(v float64, r [2]float64) float64 {
+	return math.Max(r[0], math.Min(r[1], v))
+}
+
+// contrastCheck names a palette color and the audit category it must clear.
+type contrastCheck struct {
+	key      string
+	value    *palette.Color
+	category string
+}
+
+// contrastChecks lists the text-like colors of p with their categories,
+// mirroring the audit's classification.
+func contrastChecks(p *palette.PaletteColors, variant string) []contrastCheck {
+	checks := []contrastCheck{
+		{"fg", &p.FG, audit.CategoryBody},
+		{"selection_fg", &p.SelectionFG, audit.CategoryBody},
+		{"cursor", &p.Cursor, audit.CategoryUIElement},
+		{"ui.accent", &p.UI.Accent, audit.CategoryUIElement},
+		{"ui.success", &p.UI.Success, audit.CategoryUIElement},
+		{"ui.warning", &p.UI.Warning, audit.CategoryUIElement},
+		{"ui.error", &p.UI.Error, audit.CategoryUIElement},
+		{"ui.info", &p.UI.Info, audit.CategoryUIElement},
+		{"ui.border", &p.UI.Border, audit.CategoryUIStructural},
+		{"ui.dimmed", &p.UI.Dimmed, audit.CategoryUIStructural},
+		{"syntax.number", &p.Syntax.Number, audit.CategoryChromaticNormal},
+		{"syntax.error", &p.Syntax.Error, audit.CategoryChromaticNormal},
+	}
+	ansi := []*palette.Color{
+		&p.Color0, &p.Color1, &p.Color2, &p.Color3, &p.Color4, &p.Color5, &p.Color6, &p.Color7,
+		&p.Color8, &p.Color9, &p.Color10, &p.Color11, &p.Color12, &p.Color13, &p.Color14, &p.Color15,
+	}
+	for i, v := range ansi {
+		checks = append(checks, contrastCheck{fmt.Sprintf("color%d", i), v, audit.ClassifySlot(i, variant)})
+	}
+	return checks
+}
+
+// ensure pushes the color at v away from bg, keeping hue and chroma, until
+// it clears category's warn floor (or fail floor when there is no warn
+// tier). Colors already clear are left untouched.
+func (d deriver) ensure(v *palette.Color, category string) error {
+	t, ok := audit.ContrastThresholds[category]
+	if !ok || *v == "" || category == audit.CategoryBGMatch {
+		return nil
+	}
+	floor := t.WarnBelow
+	if floor == 0 {
+		floor = t.FailBelow
+	}
+
+	c := v.RGB()
+	if math.Abs(color.APCA(c, d.bg)) >= floor {
+		return nil
+	}
+	o := c.OKLCH()
+	fixed, err := d.solve(o, floor+1, o.L)
+	if err != nil {
+		return err
+	}
+	*v = palette.Color(fixed.Hex())
+	return nil
+}
@@ -0,0 +1,122 @@
+package derive_test
+
+import (
+	"testing"
+
+	"github.com/kylesnowschwartz/the-themer/audit"
+	"github.com/kylesnowschwartz/the-themer/derive"
+	"github.com/kylesnowschwartz/the-themer/palette"
+	"github.com/kylesnowschwartz/the-themer/palette/color"
+)
+
+// TestOpposite_WarehouseThemes derives the counterpart of every bundled
+// theme and checks it audits clean and keeps each color's hue.
+func TestOpposite_WarehouseThemes(t *testing.T) {
+	for _, name := range []string{"belafonte-day", "catppuccin-latte", "cobalt-next-neon-v2", "dayfox", "tekapo-sunset-dark", "tekapo-sunset-light"} {
+		t.Run(name, func(t *testing.T) {
+			src, err := palette.Load("../themes/" + name + "/palette.toml")
+			if err != nil {
+				t.Fatalf("Load: %v", err)
+			}
+			newName := derive.OppositeName(name, src.Theme.Variant)
+
+			cfg, err := derive.Opposite(src, newName)
+			if err != nil {
+				t.Fatalf("Opposite: %v", err)
+			}
+			if cfg.Theme.Variant == src.Theme.Variant {
+				t.Errorf("variant not flipped: %s", cfg.Theme.Variant)
+			}
+			if cfg.Theme.Name != newName || cfg.Theme.Sibling != name {
+				t.Errorf("theme = %+v, want name %s with sibling %s", cfg.Theme, newName, name)
+			}
+
+			cfg.ApplyDefaults()
+			if err := cfg.Validate(); err != nil {
+				t.Fatalf("derived palette invalid: %v", err)
+			}
+			r, err := audit.Run(cfg)
+			if err != nil {
+				t.Fatalf("audit: %v", err)
+			}
+			if n := r.Failures(false); n != 0 {
+				t.Errorf("%d audit failure(s)", n)
+			}
+
+			for slot := 1; slot <= 14; slot++ {
+				if slot == 7 || slot == 8 {
+					continue
+				}
+				a := src.Palette.Colors()[slot].RGB().OKLCH()
+				b := cfg.Palette.Colors()[slot].RGB().OKLCH()
+				if a.HueUnreliable() || b.HueUnreliable() {
+					continue
+				}
+				if d := color.HueDistance(a.H, b.H); d > 5 {
+					t.Errorf("color%d hue moved %.1f° (%.0f -> %.0f)", slot, d, a.H, b.H)
+				}
+			}
+		})
+	}
+}
+
+func TestOpposite_AnchorsSwap(t *testing.T) {
+	src, err := palette.Load("../themes/tekapo-sunset-dark/palette.toml")
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	cfg, err := derive.Opposite(src, "tekapo-sunset-light")
+	if err != nil {
+		t.Fatalf("Opposite: %v", err)
+	}
+
+	black := cfg.Palette.Color0.RGB().OKLCH().L
+	white := cfg.Palette.Color15.RGB().OKLCH().L
+	if black >= white {
+		t.Errorf("color0 L %.2f should stay darker than color15 L %.2f", black, white)
+	}
+	if !cfg.Palette.BG.RGB().IsLight() {
+		t.Errorf("bg %s is not light", cfg.Palette.BG)
+	}
+	if cfg.References != nil {
+		t.Errorf("references = %v, want none", cfg.References)
+	}
+}
+
+func TestOppositeName(t *testing.T) {
+	tests := []struct{ name, variant, want string }{
+		{"tekapo-sunset-dark", "dark", "tekapo-sunset-light"},
+		{"tekapo-sunset-light", "light", "tekapo-sunset-dark"},
+		{"dayfox", "light", "dayfox-dark"},
+		{"cobalt-next-neon-v2", "dark", "cobalt-next-neon-v2-light"},
+	}
+	for _, tc := range tests {
+		if got := derive.OppositeName(tc.name, tc.variant); got != tc.want {
+			t.Errorf("OppositeName(%s, %s) = %s, want %s", tc.name, tc.variant, got, tc.want)
+		}
+	}
+}
+
+func TestOpposite_DefaultsStayDerived(t *testing.T) {
+	src, err := palette.Load("../themes/dayfox/palette.toml")
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	src.RawPalette.Syntax.String = src.Palette.Syntax.String
+
+	cfg, err := derive.Opposite(src, "dayfox-dark")
+	if err != nil {
+		t.Fatalf("Opposite: %v", err)
+	}
+	if cfg.Palette.Syntax.Keyword != "" || cfg.Palette.Syntax.Function != "" || cfg.Palette.UI.Surface0 != "" {
+		t.Errorf("defaulted roles written out: %+v", cfg.Palette)
+	}
+	if cfg.Palette.Syntax.String == "" {
+		t.Error("explicit syntax.string dropped")
+	}
+
+	cfg.ApplyDefaults()
+	if cfg.Palette.Syntax.Function != cfg.Palette.Color15 {
+		t.Errorf("syntax.function = %s, want the dark variant's color15 %s", cfg.Palette.Syntax.Function, cfg.Palette.Color15)
+	}
+}
@@ -0,0 +1,175 @@
+// Package diff renders line-based unified diffs, in the format of
+// diff -u and git diff, for reporting changes to generated files.
+package diff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// context is the number of unchanged lines shown around each change.
+const context = 3
+
+// op is one line of an edit script: ' ' kept, '-' deleted, '+' inserted.
+type op struct {
+	kind byte
+	line string
+}
+
+// Unified returns a unified diff turning from into to, with fromName and
+// toName in the file headers. It returns "" when the two are equal.
+func Unified(fromName, toName string, from, to []byte) string {
+	if string(from) == string(to) {
+		return ""
+	}
+	a, b := splitLines(string(from)), splitLines(string(to))
+	ops := editScript(a, b)
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "--- %s\n+++ %s\n", fromName, toName)
+	for _, h := range hunks(ops) {
+		out.WriteString(h)
+	}
+	return out.String()
+}
+
+// splitLines splits s into lines that keep their "\n". A missing final
+// newline is marked the way diff does, so it shows up as a change.
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(s, "\n")
+	if last := len(lines) - 1; lines[last] == "" {
+		lines = lines[:last]
+	} else {
+		lines[last] += "\n\\ No newline at end of file\n"
+	}
+	return lines
+}
+
+// editScript computes a shortest edit script from a to b by longest common
+// subsequence, after trimming the common prefix and suffix. Generated
+// files are small, so the quadratic table is cheap.
+func editScript(a, b []string) []op {
+	pre := 0
+	for pre < len(a) && pre < len(b) && a[pre] == b[pre] {
+		pre++
+	}
+	suf := 0
+	for suf < len(a)-pre && suf < len(b)-pre && a[len(a)-1-suf] == b[len(b)-1-suf] {
+		suf++
+	}
+	ma, mb := a[pre:len(a)-suf], b[pre:len(b)-suf]
+
+	// lcs[i][j] is the LCS length of ma[i:] and mb[j:].
+	lcs := make([][]int, len(ma)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(mb)+1)
+	}
+	for i := len(ma) - 1; i >= 0; i-- {
+		for j := len(mb) - 1; j >= 0; j-- {
+			if ma[i] == mb[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	ops := make([]op, 0, len(a)+len(b))
+	for _, l := range a[:pre] {
+		ops = append(ops, op{' ', l})
+	}
+	i, j := 0, 0
+	for i < len(ma) || j < len(mb) {
+		switch {
+		case i < len(ma) && j < len(mb) && ma[i] == mb[j]:
+			ops = append(ops, op{' ', ma[i]})
+			i++
+			j++
+		case j == len(mb) || (i < len(ma) && lcs[i+1][j] >= lcs[i][j+1]):
+			ops = append(ops, op{'-', ma[i]})
+			i++
+		default:
+			ops = append(ops, op{'+', mb[j]})
+			j++
+		}
+	}
+	for _, l := range a[len(a)-suf:] {
+		ops = append(ops, op{' ', l})
+	}
+	return ops
+}
+
+// hunks groups an edit script into "@@" hunks, merging changes whose
+// context would overlap.
+func hunks(ops []op) []string {
+	var out []string
+	for start := 0; start < len(ops); {
+		// Find the next change.
+		first := start
+		for first < len(ops) && ops[first].kind == ' ' {
+			first++
+		}
+		if first == len(ops) {
+			break
+		}
+		// Extend while the gap to the following change is within 2*context.
+		last := first
+		for k := first + 1; k < len(ops); k++ {
+			if ops[k].kind == ' ' {
+				continue
+			}
+			if k-last > 2*context {
+				break
+			}
+			last = k
+		}
+		lo := max(first-context, start)
+		hi := min(last+context+1, len(ops))
+		out = append(out, hunk(ops, lo, hi))
+		start = hi
+	}
+	return out
+}
+
+// hunk renders ops[lo:hi] with its header.
+func hunk(ops []op, lo, hi int) string {
+	// Line numbers of ops[lo] in each file.
+	fromLine, toLine := 1, 1
+	for _, o := range ops[:lo] {
+		if o.kind != '+' {
+			fromLine++
+		}
+		if o.kind != '-' {
+			toLine++
+		}
+	}
+
+	var body strings.Builder
+	fromCount, toCount := 0, 0
+	for _, o := range ops[lo:hi] {
+		body.WriteByte(o.kind)
+		body.WriteString(o.line)
+		if o.kind != '+' {
+			fromCount++
+		}
+		if o.kind != '-' {
+			toCount++
+		}
+	}
+	return fmt.Sprintf("@@ -%s +%s @@\n", span(fromLine, fromCount), span(toLine, toCount)) + body.String()
+}
+
+// span formats a hunk range. An empty range names the line before it, and
+// a count of one is left implicit, as diff -u does.
+func span(line, count int) string {
+	switch count {
+	case 0:
+		return fmt.Sprintf("%d,0", line-1)
+	case 1:
+		return fmt.Sprintf("%d", line)
+	}
+	return fmt.Sprintf("%d,%d", line, count)
+}
@@ -0,0 +1,60 @@
+package diff_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kylesnowschwartz/the-themer/diff"
+)
+
+func TestUnified_Equal(t *testing.T) {
+	if got := diff.Unified("a", "b", []byte("x\ny\n"), []byte("x\ny\n")); got != "" {
+		t.Errorf("equal inputs: got %q, want empty", got)
+	}
+}
+
+func TestUnified(t *testing.T) {
+	from := "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n13\n14\n15\n"
+	to := "1\n2\ntwo and a half\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\nthirteen\n14\n15\n"
+
+	want := `--- disk
++++ generated
+@@ -1,5 +1,6 @@
+ 1
+ 2
++two and a half
+ 3
+ 4
+ 5
+@@ -10,6 +11,6 @@
+ 10
+ 11
+ 12
+-13
++thirteen
+ 14
+ 15
+`
+	if got := diff.Unified("disk", "generated", []byte(from), []byte(to)); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+// TestUnified_MergesNearbyChanges checks that changes whose context
+// overlaps share one hunk.
+func TestUnified_MergesNearbyChanges(t *testing.T) {
+	got := diff.Unified("a", "b", []byte("1\n2\n3\n4\n5\n6\n7\n8\n"), []byte("1\nX\n3\n4\n5\n6\nY\n8\n"))
+	if n := strings.Count(got, "@@ -"); n != 1 {
+		t.Errorf("got %d hunks, want 1:\n%s", n, got)
+	}
+	if !strings.Contains(got, "@@ -1,8 +1,8 @@\n") {
+		t.Errorf("unexpected hunk header:\n%s", got)
+	}
+}
+
+func TestUnified_EmptyAndNoNewline(t *testing.T) {
+	want := "--- a\n+++ b\n@@ -0,0 +1,2 @@\n+x\n+y\n\\ No newline at end of file\n"
+	if got := diff.Unified("a", "b", nil, []byte("x\ny")); got != want {
+		t.Errorf("got:\n%q\nwant:\n%q", got, want)
+	}
+}
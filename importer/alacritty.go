@@ -0,0 +1,101 @@
+package importer
+
+import (
+	"fmt"
+
+	"github.com/BurntSushi/toml"
+
+	"github.com/kylesnowschwartz/the-themer/palette"
+)
+
+// alacrittyTheme is the subset of an Alacritty TOML config that holds colors.
+type alacrittyTheme struct {
+	Colors struct {
+		Primary struct {
+			Background string `toml:"background"`
+			Foreground string `toml:"foreground"`
+		} `toml:"primary"`
+		Cursor struct {
+			Text   string `toml:"text"`
+			Cursor string `toml:"cursor"`
+		} `toml:"cursor"`
+		Selection struct {
+			Text       string `toml:"text"`
+			Background string `toml:"background"`
+		} `toml:"selection"`
+		Normal alacrittyANSI `toml:"normal"`
+		Bright alacrittyANSI `toml:"bright"`
+	} `toml:"colors"`
+}
+
+type alacrittyANSI struct {
+	Black   string `toml:"black"`
+	Red     string `toml:"red"`
+	Green   string `toml:"green"`
+	Yellow  string `toml:"yellow"`
+	Blue    string `toml:"blue"`
+	Magenta string `toml:"magenta"`
+	Cyan    string `toml:"cyan"`
+	White   string `toml:"white"`
+}
+
+func (a alacrittyANSI) colors() []string {
+	return []string{a.Black, a.Red, a.Green, a.Yellow, a.Blue, a.Magenta, a.Cyan, a.White}
+}
+
+// alacrittyKeywords maps Alacritty's cell-relative colors to palette
+// references.
+var alacrittyKeywords = map[string]palette.Color{
+	"CellBackground": "$bg",
+	"CellForeground": "$fg",
+}
+
+// alacrittyField pairs a [colors] key with its value and palette destination.
+type alacrittyField struct {
+	key   string
+	value string
+	dst   *palette.Color
+}
+
+// parseAlacritty reads an Alacritty TOML theme ([colors.*] tables).
+func parseAlacritty(data []byte) (palette.PaletteColors, error) {
+	var t alacrittyTheme
+	if err := toml.Unmarshal(data, &t); err != nil {
+		return palette.PaletteColors{}, err
+	}
+	c := t.Colors
+
+	var p palette.PaletteColors
+	fields := []alacrittyField{
+		{"primary.background", c.Primary.Background, &p.BG},
+		{"primary.foreground", c.Primary.Foreground, &p.FG},
+		{"cursor.cursor", c.Cursor.Cursor, &p.Cursor},
+		{"cursor.text", c.Cursor.Text, &p.CursorText},
+		{"selection.background", c.Selection.Background, &p.SelectionBG},
+		{"selection.text", c.Selection.Text, &p.SelectionFG},
+	}
+	slots := ansiSlots(&p)
+	names := [8]string{"black", "red", "green", "yellow", "blue", "magenta", "cyan", "white"}
+	for i, v := range c.Normal.colors() {
+		fields = append(fields, alacrittyField{"normal." + names[i], v, slots[i]})
+	}
+	for i, v := range c.Bright.colors() {
+		fields = append(fields, alacrittyField{"bright." + names[i], v, slots[i+8]})
+	}
+
+	for _, f := range fields {
+		if f.value == "" {
+			continue
+		}
+		if ref, ok := alacrittyKeywords[f.value]; ok {
+			*f.dst = ref
+			continue
+		}
+		hex, err := normalizeHex(f.value)
+		if err != nil {
+			return p, fmt.Errorf("colors.%s: %w", f.key, err)
+		}
+		*f.dst = hex
+	}
+	return p, nil
+}
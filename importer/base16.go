@@ -0,0 +1,16 @@
+package importer
+
+import (
+	"github.com/kylesnowschwartz/the-themer/palette"
+	"github.com/kylesnowschwartz/the-themer/palette/base16"
+)
+
+// parseBase16 reads a base16 or base24 scheme YAML. Either format name
+// accepts either system; the scheme's own slots decide the mapping.
+func parseBase16(data []byte) (palette.PaletteColors, error) {
+	s, err := base16.Parse(data)
+	if err != nil {
+		return palette.PaletteColors{}, err
+	}
+	return s.Palette(), nil
+}
@@ -0,0 +1,63 @@
+package importer
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/kylesnowschwartz/the-themer/palette"
+)
+
+// parseGhostty reads a Ghostty theme: "key = value" lines with the ANSI
+// colors given as "palette = N=#rrggbb".
+func parseGhostty(data []byte) (palette.PaletteColors, error) {
+	var p palette.PaletteColors
+	slots := ansiSlots(&p)
+
+	fields := map[string]*palette.Color{
+		"background":           &p.BG,
+		"foreground":           &p.FG,
+		"cursor-color":         &p.Cursor,
+		"cursor-text":          &p.CursorText,
+		"selection-background": &p.SelectionBG,
+		"selection-foreground": &p.SelectionFG,
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		key, value, ok := strings.Cut(text, "=")
+		if !ok {
+			continue
+		}
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+
+		dst, known := fields[key]
+		if key == "palette" {
+			idx, hex, ok := strings.Cut(value, "=")
+			n, err := strconv.Atoi(strings.TrimSpace(idx))
+			if !ok || err != nil {
+				return p, fmt.Errorf("line %d: invalid palette entry %q", line, value)
+			}
+			if n < 0 || n > 15 {
+				continue // 256-color extensions have no palette slot
+			}
+			dst, known, value = slots[n], true, hex
+		}
+		if !known {
+			continue
+		}
+
+		hex, err := normalizeHex(value)
+		if err != nil {
+			return p, fmt.Errorf("line %d: %s: %w", line, key, err)
+		}
+		*dst = hex
+	}
+	return p, scanner.Err()
+}
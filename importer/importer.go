@@ -0,0 +1,100 @@
+// Package importer converts terminal themes written for other applications
+// (Ghostty, kitty, Alacritty, iTerm2, Xresources) and base16/base24
+// schemes into palette configs.
+//
+// Importers only fill the keys their source format carries; everything else
+// is left empty for palette.ApplyDefaults to derive, so an imported palette
+// encodes to a minimal palette.toml.
+package importer
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/kylesnowschwartz/the-themer/palette"
+	"github.com/kylesnowschwartz/the-themer/palette/color"
+)
+
+// parseFunc extracts palette colors from one source format.
+type parseFunc func(data []byte) (palette.PaletteColors, error)
+
+var parsers = map[string]parseFunc{
+	"base16":      parseBase16,
+	"base24":      parseBase16,
+	"ghostty":     parseGhostty,
+	"kitty":       parseKitty,
+	"alacritty":   parseAlacritty,
+	"itermcolors": parseITermColors,
+	"xresources":  parseXresources,
+}
+
+// Formats returns the supported source format names, sorted.
+func Formats() []string {
+	names := make([]string, 0, len(parsers))
+	for name := range parsers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Import parses data in the named source format into a palette config for
+// a theme called name. The variant is inferred from the background's
+// luminance. The result has no defaults applied.
+func Import(format string, data []byte, name string) (palette.Config, error) {
+	parse, ok := parsers[format]
+	if !ok {
+		return palette.Config{}, fmt.Errorf("unknown import format %q (supported: %s)", format, strings.Join(Formats(), ", "))
+	}
+
+	p, err := parse(data)
+	if err != nil {
+		return palette.Config{}, fmt.Errorf("parsing %s theme: %w", format, err)
+	}
+	if p.BG == "" {
+		return palette.Config{}, fmt.Errorf("parsing %s theme: no background color found", format)
+	}
+
+	return palette.Config{
+		Theme: palette.Theme{
+			Name:    name,
+			Variant: InferVariant(p.BG.RGB()),
+		},
+		Palette: p,
+	}, nil
+}
+
+// InferVariant returns "light" or "dark" for a theme with the given
+// background color.
+func InferVariant(bg color.RGB) string {
+	if bg.IsLight() {
+		return "light"
+	}
+	return "dark"
+}
+
+// normalizeHex converts the hex spellings found in theme files ("#RRGGBB",
+// "RRGGBB", "0xRRGGBB", "#RGB") to lowercase "#rrggbb".
+func normalizeHex(s string) (palette.Color, error) {
+	h := strings.Trim(strings.TrimSpace(s), `"'`)
+	h = strings.TrimPrefix(strings.TrimPrefix(h, "0x"), "#")
+	if len(h) == 3 {
+		h = string([]byte{h[0], h[0], h[1], h[1], h[2], h[2]})
+	}
+	c, err := color.ParseHex("#" + h)
+	if err != nil {
+		return "", fmt.Errorf("invalid color %q", s)
+	}
+	return palette.Color(c.Hex()), nil
+}
+
+// ansiSlots returns pointers to color0..color15 for index-based assignment.
+func ansiSlots(p *palette.PaletteColors) [16]*palette.Color {
+	return [16]*palette.Color{
+		&p.Color0, &p.Color1, &p.Color2, &p.Color3,
+		&p.Color4, &p.Color5, &p.Color6, &p.Color7,
+		&p.Color8, &p.Color9, &p.Color10, &p.Color11,
+		&p.Color12, &p.Color13, &p.Color14, &p.Color15,
+	}
+}
@@ -0,0 +1,318 @@
+package importer_test
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/kylesnowschwartz/the-themer/adapter"
+	_ "github.com/kylesnowschwartz/the-themer/adapter/ghostty"
+	"github.com/kylesnowschwartz/the-themer/importer"
+	"github.com/kylesnowschwartz/the-themer/palette"
+	"github.com/kylesnowschwartz/the-themer/palette/color"
+)
+
+// dayfox is the expected import of every fixture below: the dayfox
+// warehouse palette, which fills the core keys and the ANSI 16.
+var dayfox = palette.PaletteColors{
+	BG: "#f6f2ee", FG: "#3d2b5a", Cursor: "#3d2b5a",
+	SelectionBG: "#e7d2be", SelectionFG: "#3d2b5a",
+	Color0: "#352c24", Color1: "#a5222f", Color2: "#396847", Color3: "#ac5402",
+	Color4: "#2848a9", Color5: "#6e33ce", Color6: "#287980", Color7: "#f2e9e1",
+	Color8: "#534c45", Color9: "#b3434e", Color10: "#577f63", Color11: "#b86e28",
+	Color12: "#4863b6", Color13: "#8452d5", Color14: "#488d93", Color15: "#f4ece6",
+}
+
+func importOK(t *testing.T, format, data string) palette.Config {
+	t.Helper()
+	cfg, err := importer.Import(format, []byte(data), "dayfox")
+	if err != nil {
+		t.Fatalf("Import(%s): %v", format, err)
+	}
+	return cfg
+}
+
+// TestImport_GhosttyRoundTrip feeds every warehouse theme's generated
+// Ghostty file back through the importer and expects the colors Ghostty
+// carries to survive, and regenerating to reproduce the file.
+func TestImport_GhosttyRoundTrip(t *testing.T) {
+	ghostty := adapter.ByName([]string{"ghostty"})[0]
+
+	paths := []string{"../testdata/bleu.toml"}
+	for _, name := range []string{"belafonte-day", "catppuccin-latte", "cobalt-next-neon-v2", "dayfox", "tekapo-sunset-dark", "tekapo-sunset-light"} {
+		paths = append(paths, "../themes/"+name+"/palette.toml")
+	}
+
+	for _, path := range paths {
+		t.Run(path, func(t *testing.T) {
+			want, err := palette.Load(path)
+			if err != nil {
+				t.Fatalf("Load: %v", err)
+			}
+			out, err := ghostty.Generate(want)
+			if err != nil {
+				t.Fatalf("Generate: %v", err)
+			}
+
+			got, err := importer.Import("ghostty", out, want.Theme.Name)
+			if err != nil {
+				t.Fatalf("Import: %v", err)
+			}
+			if got.Theme.Variant != want.Theme.Variant {
+				t.Errorf("variant = %s, want %s", got.Theme.Variant, want.Theme.Variant)
+			}
+
+			w, g := want.Palette, got.Palette
+			pairs := [][2]palette.Color{
+				{w.BG, g.BG}, {w.FG, g.FG}, {w.Cursor, g.Cursor}, {w.CursorText, g.CursorText},
+				{w.SelectionBG, g.SelectionBG}, {w.SelectionFG, g.SelectionFG},
+			}
+			for i, c := range w.Colors() {
+				pairs = append(pairs, [2]palette.Color{c, g.Colors()[i]})
+			}
+			for _, p := range pairs {
+				if !strings.EqualFold(string(p[0]), string(p[1])) {
+					t.Errorf("imported %s, want %s", p[1], p[0])
+				}
+			}
+
+			got.ApplyDefaults()
+			again, err := ghostty.Generate(got)
+			if err != nil {
+				t.Fatalf("Generate imported: %v", err)
+			}
+			if !strings.EqualFold(string(again), string(out)) {
+				t.Errorf("regenerated Ghostty file differs\n--- got ---\n%s\n--- want ---\n%s", again, out)
+			}
+		})
+	}
+}
+
+func TestImport_Kitty(t *testing.T) {
+	cfg := importOK(t, "kitty", `
+# vim:ft=kitty
+background            #f6f2ee
+foreground            #3d2b5a
+cursor                #3d2b5a
+selection_background  #e7d2be
+selection_foreground  #3d2b5a
+url_color             #2848a9
+color0  #352c24
+color1  #a5222f
+color2  #396847
+color3  #ac5402
+color4  #2848a9
+color5  #6e33ce
+color6  #287980
+color7  #f2e9e1
+color8  #534c45
+color9  #b3434e
+color10 #577f63
+color11 #b86e28
+color12 #4863b6
+color13 #8452d5
+color14 #488d93
+color15 #f4ece6
+`)
+	if cfg.Palette != dayfox {
+		t.Errorf("palette = %+v\nwant %+v", cfg.Palette, dayfox)
+	}
+	if cfg.Theme.Variant != "light" {
+		t.Errorf("variant = %s, want light", cfg.Theme.Variant)
+	}
+}
+
+func TestImport_KittyKeywords(t *testing.T) {
+	cfg := importOK(t, "kitty", "background #000000\ncursor_text_color background\nselection_foreground none\n")
+	if cfg.Palette.CursorText != "$bg" {
+		t.Errorf("cursor_text = %q, want $bg", cfg.Palette.CursorText)
+	}
+	if cfg.Palette.SelectionFG != "" {
+		t.Errorf("selection_fg = %q, want unset", cfg.Palette.SelectionFG)
+	}
+}
+
+func TestImport_Alacritty(t *testing.T) {
+	cfg := importOK(t, "alacritty", `
+[colors.primary]
+background = "#f6f2ee"
+foreground = "#3d2b5a"
+
+[colors.cursor]
+cursor = "#3d2b5a"
+text = "CellBackground"
+
+[colors.selection]
+background = "0xe7d2be"
+text = "#3d2b5a"
+
+[colors.normal]
+black = "#352c24"
+red = "#a5222f"
+green = "#396847"
+yellow = "#ac5402"
+blue = "#2848a9"
+magenta = "#6e33ce"
+cyan = "#287980"
+white = "#f2e9e1"
+
+[colors.bright]
+black = "#534c45"
+red = "#b3434e"
+green = "#577f63"
+yellow = "#b86e28"
+blue = "#4863b6"
+magenta = "#8452d5"
+cyan = "#488d93"
+white = "#f4ece6"
+`)
+	want := dayfox
+	want.CursorText = "$bg"
+	if cfg.Palette != want {
+		t.Errorf("palette = %+v\nwant %+v", cfg.Palette, want)
+	}
+}
+
+func TestImport_ITermColors(t *testing.T) {
+	entry := func(key string, c color.RGB) string {
+		return "\t<key>" + key + "</key>\n\t<dict>\n" +
+			"\t\t<key>Color Space</key>\n\t\t<string>sRGB</string>\n" +
+			"\t\t<key>Blue Component</key>\n\t\t<real>" + component(c.B) + "</real>\n" +
+			"\t\t<key>Green Component</key>\n\t\t<real>" + component(c.G) + "</real>\n" +
+			"\t\t<key>Red Component</key>\n\t\t<real>" + component(c.R) + "</real>\n" +
+			"\t</dict>\n"
+	}
+
+	var b strings.Builder
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+`)
+	for i, c := range dayfox.Colors() {
+		b.WriteString(entry("Ansi "+strconv.Itoa(i)+" Color", c.RGB()))
+	}
+	b.WriteString(entry("Background Color", dayfox.BG.RGB()))
+	b.WriteString(entry("Foreground Color", dayfox.FG.RGB()))
+	b.WriteString(entry("Cursor Color", dayfox.Cursor.RGB()))
+	b.WriteString(entry("Selection Color", dayfox.SelectionBG.RGB()))
+	b.WriteString(entry("Selected Text Color", dayfox.SelectionFG.RGB()))
+	b.WriteString("</dict>\n</plist>\n")
+
+	cfg := importOK(t, "itermcolors", b.String())
+	if cfg.Palette != dayfox {
+		t.Errorf("palette = %+v\nwant %+v", cfg.Palette, dayfox)
+	}
+}
+
+func TestImport_Xresources(t *testing.T) {
+	cfg := importOK(t, "xresources", `
+! dayfox
+#define fox_bg #f6f2ee
+#define fox_fg #3d2b5a
+
+*.background: fox_bg
+*.foreground: fox_fg
+*.cursorColor: fox_fg
+URxvt.highlightColor: #e7d2be
+URxvt.highlightTextColor: #3d2b5a
+*.color0:  #352c24
+*.color1:  #a5222f
+*.color2:  #396847
+*.color3:  #ac5402
+*.color4:  #2848a9
+*.color5:  #6e33ce
+*.color6:  #287980
+*.color7:  #f2e9e1
+*color8:   #534c45
+*color9:   #b3434e
+*color10:  #577f63
+*color11:  #b86e28
+*color12:  #4863b6
+*color13:  #8452d5
+*color14:  #488d93
+*color15:  #f4ece6
+`)
+	if cfg.Palette != dayfox {
+		t.Errorf("palette = %+v\nwant %+v", cfg.Palette, dayfox)
+	}
+}
+
+func TestImport_Base24(t *testing.T) {
+	cfg := importOK(t, "base24", `system: "base24"
+name: "Dayfox"
+author: "EdenEast"
+variant: "light"
+palette:
+  base00: "#f6f2ee"
+  base01: "#dbd1dd"
+  base02: "#e7d2be"
+  base03: "#534c45"
+  base04: "#837a72"
+  base05: "#3d2b5a"
+  base06: "#f2e9e1"
+  base07: "#f4ece6"
+  base08: "#a5222f"
+  base09: "#955f61"
+  base0A: "#ac5402"
+  base0B: "#396847"
+  base0C: "#287980"
+  base0D: "#2848a9"
+  base0E: "#6e33ce"
+  base0F: "#a440b5"
+  base10: "#352c24"
+  base11: "#e4dcd4"
+  base12: "#b3434e"
+  base13: "#b86e28"
+  base14: "#577f63"
+  base15: "#488d93"
+  base16: "#4863b6"
+  base17: "#8452d5"
+`)
+	want := dayfox
+	want.Cursor, want.SelectionFG = "", ""
+	want.UI.Dimmed = "#837a72"
+	want.Syntax = palette.Syntax{Number: "#955f61", Error: "#a440b5", LineHighlight: "#dbd1dd"}
+	if cfg.Palette != want {
+		t.Errorf("palette = %+v\nwant %+v", cfg.Palette, want)
+	}
+	if cfg.Theme.Variant != "light" {
+		t.Errorf("variant = %s, want light", cfg.Theme.Variant)
+	}
+}
+
+func TestImport_Errors(t *testing.T) {
+	tests := []struct {
+		format, data, want string
+	}{
+		{"wezterm", "", `unknown import format "wezterm"`},
+		{"kitty", "color1 #ff0000\n", "no background color found"},
+		{"ghostty", "background = #12345\n", `line 1: background: invalid color "#12345"`},
+	}
+	for _, tc := range tests {
+		_, err := importer.Import(tc.format, []byte(tc.data), "x")
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("Import(%s) error = %v, want containing %q", tc.format, err, tc.want)
+		}
+	}
+}
+
+func TestInferVariant(t *testing.T) {
+	tests := map[string]string{
+		"#050a14": "dark",
+		"#142838": "dark",
+		"#f6f2ee": "light",
+		"#eff1f5": "light",
+		"#808080": "light",
+		"#6a6a6a": "dark",
+	}
+	for hex, want := range tests {
+		if got := importer.InferVariant(color.MustParseHex(hex)); got != want {
+			t.Errorf("InferVariant(%s) = %s, want %s", hex, got, want)
+		}
+	}
+}
+
+func component(v uint8) string {
+	return strconv.FormatFloat(float64(v)/255, 'f', -1, 64)
+}
@@ -0,0 +1,87 @@
+package importer
+
+import (
+	"encoding/xml"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/kylesnowschwartz/the-themer/palette"
+	"github.com/kylesnowschwartz/the-themer/palette/color"
+)
+
+// plistNode is a generic XML element of an Apple property list.
+type plistNode struct {
+	XMLName  xml.Name
+	Text     string      `xml:",chardata"`
+	Children []plistNode `xml:",any"`
+}
+
+// dict returns the key/value pairs of a <dict> node.
+func (n plistNode) dict() map[string]plistNode {
+	out := map[string]plistNode{}
+	for i := 0; i+1 < len(n.Children); i += 2 {
+		if n.Children[i].XMLName.Local == "key" {
+			out[strings.TrimSpace(n.Children[i].Text)] = n.Children[i+1]
+		}
+	}
+	return out
+}
+
+// parseITermColors reads an iTerm2 .itermcolors property list. Components
+// are taken as sRGB; profiles exported in P3 come out slightly desaturated.
+func parseITermColors(data []byte) (palette.PaletteColors, error) {
+	var root plistNode
+	if err := xml.Unmarshal(data, &root); err != nil {
+		return palette.PaletteColors{}, err
+	}
+	if len(root.Children) == 0 || root.Children[0].XMLName.Local != "dict" {
+		return palette.PaletteColors{}, fmt.Errorf("expected a top-level <dict>")
+	}
+	entries := root.Children[0].dict()
+
+	var p palette.PaletteColors
+	fields := map[string]*palette.Color{
+		"Background Color":    &p.BG,
+		"Foreground Color":    &p.FG,
+		"Cursor Color":        &p.Cursor,
+		"Cursor Text Color":   &p.CursorText,
+		"Selection Color":     &p.SelectionBG,
+		"Selected Text Color": &p.SelectionFG,
+	}
+	for i, slot := range ansiSlots(&p) {
+		fields[fmt.Sprintf("Ansi %d Color", i)] = slot
+	}
+
+	for key, dst := range fields {
+		node, ok := entries[key]
+		if !ok {
+			continue
+		}
+		c, err := itermColor(node)
+		if err != nil {
+			return p, fmt.Errorf("%s: %w", key, err)
+		}
+		*dst = palette.Color(c.Hex())
+	}
+	return p, nil
+}
+
+// itermColor decodes a color dict of "Red/Green/Blue Component" reals (0-1).
+func itermColor(n plistNode) (color.RGB, error) {
+	entries := n.dict()
+	var ch [3]uint8
+	for i, name := range []string{"Red Component", "Green Component", "Blue Component"} {
+		node, ok := entries[name]
+		if !ok {
+			return color.RGB{}, fmt.Errorf("missing %s", name)
+		}
+		v, err := strconv.ParseFloat(strings.TrimSpace(node.Text), 64)
+		if err != nil {
+			return color.RGB{}, fmt.Errorf("invalid %s %q", name, node.Text)
+		}
+		ch[i] = uint8(math.Round(math.Max(0, math.Min(1, v)) * 255))
+	}
+	return color.RGB{R: ch[0], G: ch[1], B: ch[2]}, nil
+}
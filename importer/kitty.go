@@ -0,0 +1,58 @@
+package importer
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/kylesnowschwartz/the-themer/palette"
+)
+
+// kittyKeywords maps kitty's color keywords to palette references.
+// "none" means "use the cell's own color", which has no palette equivalent.
+var kittyKeywords = map[string]palette.Color{
+	"background": "$bg",
+	"foreground": "$fg",
+	"none":       "",
+}
+
+// parseKitty reads a kitty theme: whitespace-separated "key value" lines.
+func parseKitty(data []byte) (palette.PaletteColors, error) {
+	var p palette.PaletteColors
+
+	fields := map[string]*palette.Color{
+		"background":           &p.BG,
+		"foreground":           &p.FG,
+		"cursor":               &p.Cursor,
+		"cursor_text_color":    &p.CursorText,
+		"selection_background": &p.SelectionBG,
+		"selection_foreground": &p.SelectionFG,
+	}
+	for i, slot := range ansiSlots(&p) {
+		fields[fmt.Sprintf("color%d", i)] = slot
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for line := 1; scanner.Scan(); line++ {
+		parts := strings.Fields(scanner.Text())
+		if len(parts) < 2 || strings.HasPrefix(parts[0], "#") {
+			continue
+		}
+		dst, ok := fields[parts[0]]
+		if !ok {
+			continue
+		}
+
+		if ref, ok := kittyKeywords[parts[1]]; ok {
+			*dst = ref
+			continue
+		}
+		hex, err := normalizeHex(parts[1])
+		if err != nil {
+			return p, fmt.Errorf("line %d: %s: %w", line, parts[0], err)
+		}
+		*dst = hex
+	}
+	return p, scanner.Err()
+}
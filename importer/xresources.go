@@ -0,0 +1,68 @@
+package importer
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/kylesnowschwartz/the-themer/palette"
+)
+
+// parseXresources reads X resources ("*.color0: #rrggbb", "URxvt.background:
+// ..."). Resource class prefixes are ignored and #define macros are expanded.
+func parseXresources(data []byte) (palette.PaletteColors, error) {
+	var p palette.PaletteColors
+
+	fields := map[string]*palette.Color{
+		"background":         &p.BG,
+		"foreground":         &p.FG,
+		"cursorColor":        &p.Cursor,
+		"cursorColor2":       &p.CursorText,
+		"highlightColor":     &p.SelectionBG,
+		"highlightTextColor": &p.SelectionFG,
+	}
+	for i, slot := range ansiSlots(&p) {
+		fields[fmt.Sprintf("color%d", i)] = slot
+	}
+
+	defines := map[string]string{}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "!") {
+			continue
+		}
+		if rest, ok := strings.CutPrefix(text, "#define"); ok {
+			parts := strings.Fields(rest)
+			if len(parts) >= 2 {
+				defines[parts[0]] = parts[1]
+			}
+			continue
+		}
+		if strings.HasPrefix(text, "#") {
+			continue // other preprocessor directives
+		}
+
+		resource, value, ok := strings.Cut(text, ":")
+		if !ok {
+			continue
+		}
+		name := resource[strings.LastIndexAny(resource, ".*")+1:]
+		dst, ok := fields[strings.TrimSpace(name)]
+		if !ok {
+			continue
+		}
+
+		value = strings.TrimSpace(value)
+		if v, ok := defines[value]; ok {
+			value = v
+		}
+		hex, err := normalizeHex(value)
+		if err != nil {
+			return p, fmt.Errorf("line %d: %s: %w", line, strings.TrimSpace(resource), err)
+		}
+		*dst = hex
+	}
+	return p, scanner.Err()
+}
@@ -6,7 +6,8 @@
  *   /dark    — switch to built-in dark theme
  *   /theme   — toggle (uses last value set by this extension)
  *
- * Plus auto-sync: watches ~/.config/the-themer/pi-variant and calls
+ * Plus auto-sync: watches $XDG_CONFIG_HOME/the-themer/pi-variant
+ * (~/.config/the-themer/pi-variant when XDG_CONFIG_HOME is unset) and calls
  * setTheme(contents) when the file changes. The-themer's `pi` switch
  * handler atomically writes "light" or "dark" to that file, so running
  * `the-themer switch <name>` from your shell propagates light/dark into
@@ -20,10 +21,16 @@
 import { readFile } from "node:fs/promises";
 import { existsSync, watch } from "node:fs";
 import { homedir } from "node:os";
-import { join } from "node:path";
+import { isAbsolute, join } from "node:path";
 import type { ExtensionAPI } from "@mariozechner/pi-coding-agent";
 
-const VARIANT_FILE = join(homedir(), ".config", "the-themer", "pi-variant");
+// Same rule as the-themer's configHome: XDG_CONFIG_HOME counts only when it
+// is an absolute path.
+const xdgConfigHome = process.env.XDG_CONFIG_HOME;
+const CONFIG_HOME =
+	xdgConfigHome && isAbsolute(xdgConfigHome) ? xdgConfigHome : join(homedir(), ".config");
+const STATE_DIR = join(CONFIG_HOME, "the-themer");
+const VARIANT_FILE = join(STATE_DIR, "pi-variant");
 
 async function readVariant(): Promise<"light" | "dark" | null> {
 	try {
@@ -75,11 +82,10 @@ export default function (pi: ExtensionAPI) {
 
 		// Watch the directory (not the file) so atomic-rename writes from the-themer
 		// trigger reliably on macOS — same reason the tcm/ghostty paths use rename.
-		const dir = join(homedir(), ".config", "the-themer");
-		if (!existsSync(dir)) return;
+		if (!existsSync(STATE_DIR)) return;
 
 		try {
-			watcher = watch(dir, async (_event, filename) => {
+			watcher = watch(STATE_DIR, async (_event, filename) => {
 				if (filename !== "pi-variant") return;
 				const v = await readVariant();
 				if (!v || v === lastSet) return;
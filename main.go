@@ -7,9 +7,11 @@ import (
 	"github.com/kylesnowschwartz/the-themer/cmd"
 
 	// Adapter packages register themselves via init().
+	_ "github.com/kylesnowschwartz/the-themer/adapter/base16"
 	_ "github.com/kylesnowschwartz/the-themer/adapter/bat"
 	_ "github.com/kylesnowschwartz/the-themer/adapter/delta"
 	_ "github.com/kylesnowschwartz/the-themer/adapter/fzf"
+	_ "github.com/kylesnowschwartz/the-themer/adapter/ghdash"
 	_ "github.com/kylesnowschwartz/the-themer/adapter/ghostty"
 	_ "github.com/kylesnowschwartz/the-themer/adapter/hud"
 	_ "github.com/kylesnowschwartz/the-themer/adapter/tcm"
@@ -0,0 +1,238 @@
+// Package base16 maps palettes to and from base16 and base24 schemes
+// (https://github.com/tinted-theming/home), in both the current
+// "system/palette" YAML layout and the legacy flat "scheme/baseXX" layout.
+package base16
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/kylesnowschwartz/the-themer/palette"
+	"github.com/kylesnowschwartz/the-themer/palette/color"
+)
+
+// Slot mapping between base16/base24 and palette keys. base00-base0F follow
+// the base16 styling guide's roles; base10-base17 are base24's extra
+// backgrounds and bright ANSI colors.
+//
+//	base00  default background       bg
+//	base01  lighter background       syntax.line_highlight
+//	base02  selection background     selection_bg
+//	base03  comments, invisibles     color8
+//	base04  dark foreground          ui.dimmed
+//	base05  default foreground       fg
+//	base06  light foreground         color7
+//	base07  lightest foreground      color15
+//	base08  red: variables, tags     color1
+//	base09  orange: numbers          syntax.number
+//	base0A  yellow: classes          color3
+//	base0B  green: strings           color2
+//	base0C  cyan: escapes, regex     color6
+//	base0D  blue: functions          color4
+//	base0E  magenta: keywords        color5
+//	base0F  brown: deprecated        syntax.error
+//	base10  darker background        color0
+//	base11  darkest background       color0 (export only)
+//	base12  bright red               color9
+//	base13  bright yellow            color11
+//	base14  bright green             color10
+//	base15  bright cyan              color14
+//	base16  bright blue              color12
+//	base17  bright magenta           color13
+//
+// When importing plain base16, color0 falls back to base00 and the bright
+// ANSI colors repeat their normal counterparts, as base16-shell does.
+var slots = []struct {
+	base  string
+	field func(p *palette.PaletteColors) *palette.Color
+}{
+	{"base00", func(p *palette.PaletteColors) *palette.Color { return &p.BG }},
+	{"base01", func(p *palette.PaletteColors) *palette.Color { return &p.Syntax.LineHighlight }},
+	{"base02", func(p *palette.PaletteColors) *palette.Color { return &p.SelectionBG }},
+	{"base03", func(p *palette.PaletteColors) *palette.Color { return &p.Color8 }},
+	{"base04", func(p *palette.PaletteColors) *palette.Color { return &p.UI.Dimmed }},
+	{"base05", func(p *palette.PaletteColors) *palette.Color { return &p.FG }},
+	{"base06", func(p *palette.PaletteColors) *palette.Color { return &p.Color7 }},
+	{"base07", func(p *palette.PaletteColors) *palette.Color { return &p.Color15 }},
+	{"base08", func(p *palette.PaletteColors) *palette.Color { return &p.Color1 }},
+	{"base09", func(p *palette.PaletteColors) *palette.Color { return &p.Syntax.Number }},
+	{"base0A", func(p *palette.PaletteColors) *palette.Color { return &p.Color3 }},
+	{"base0B", func(p *palette.PaletteColors) *palette.Color { return &p.Color2 }},
+	{"base0C", func(p *palette.PaletteColors) *palette.Color { return &p.Color6 }},
+	{"base0D", func(p *palette.PaletteColors) *palette.Color { return &p.Color4 }},
+	{"base0E", func(p *palette.PaletteColors) *palette.Color { return &p.Color5 }},
+	{"base0F", func(p *palette.PaletteColors) *palette.Color { return &p.Syntax.Error }},
+	{"base10", func(p *palette.PaletteColors) *palette.Color { return &p.Color0 }},
+	{"base11", func(p *palette.PaletteColors) *palette.Color { return &p.Color0 }},
+	{"base12", func(p *palette.PaletteColors) *palette.Color { return &p.Color9 }},
+	{"base13", func(p *palette.PaletteColors) *palette.Color { return &p.Color11 }},
+	{"base14", func(p *palette.PaletteColors) *palette.Color { return &p.Color10 }},
+	{"base15", func(p *palette.PaletteColors) *palette.Color { return &p.Color14 }},
+	{"base16", func(p *palette.PaletteColors) *palette.Color { return &p.Color12 }},
+	{"base17", func(p *palette.PaletteColors) *palette.Color { return &p.Color13 }},
+}
+
+// Slot counts per system.
+const (
+	Base16Slots = 16
+	Base24Slots = 24
+)
+
+// Scheme is a base16 or base24 color scheme.
+type Scheme struct {
+	System  string // "base16" or "base24"
+	Name    string
+	Author  string
+	Variant string            // "dark" or "light"; may be empty in legacy schemes
+	Colors  map[string]string // "base00".."base17" -> "#rrggbb"
+}
+
+// slotCount returns how many slots the scheme's system defines.
+func (s Scheme) slotCount() int {
+	if s.System == "base24" {
+		return Base24Slots
+	}
+	return Base16Slots
+}
+
+// FromConfig builds a scheme of the given system ("base16" or "base24")
+// from cfg. cfg must have defaults applied so every mapped key is set.
+func FromConfig(cfg palette.Config, system string) Scheme {
+	s := Scheme{
+		System:  system,
+		Name:    cfg.Theme.Name,
+		Author:  cfg.Theme.Author,
+		Variant: cfg.Theme.Variant,
+		Colors:  map[string]string{},
+	}
+	p := cfg.Palette
+	for _, slot := range slots[:s.slotCount()] {
+		s.Colors[slot.base] = string(*slot.field(&p))
+	}
+	return s
+}
+
+// Palette maps the scheme's slots onto palette keys. Keys with no slot are
+// left empty for palette.ApplyDefaults.
+func (s Scheme) Palette() palette.PaletteColors {
+	var p palette.PaletteColors
+	for _, slot := range slots {
+		dst := slot.field(&p)
+		// base10 and base11 share color0; the first one present wins.
+		if v, ok := s.Colors[slot.base]; ok && *dst == "" {
+			*dst = palette.Color(v)
+		}
+	}
+
+	if p.Color0 == "" {
+		p.Color0 = p.BG
+	}
+	normals := []*palette.Color{&p.Color1, &p.Color2, &p.Color3, &p.Color4, &p.Color5, &p.Color6}
+	brights := []*palette.Color{&p.Color9, &p.Color10, &p.Color11, &p.Color12, &p.Color13, &p.Color14}
+	for i, b := range brights {
+		if *b == "" {
+			*b = *normals[i]
+		}
+	}
+	return p
+}
+
+// Parse reads a scheme YAML file. Both the current layout (system, name,
+// author, variant, and a nested palette map) and the legacy flat layout
+// (scheme, author, base00...) are accepted. The system is inferred from the
+// slots present when not declared.
+func Parse(data []byte) (Scheme, error) {
+	s := Scheme{Colors: map[string]string{}}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") || text == "---" {
+			continue
+		}
+		key, value, ok := strings.Cut(text, ":")
+		if !ok {
+			continue
+		}
+		key, value = strings.TrimSpace(key), yamlScalar(value)
+
+		switch {
+		case key == "system":
+			s.System = value
+		case key == "name" || key == "scheme":
+			s.Name = value
+		case key == "author":
+			s.Author = value
+		case key == "variant":
+			s.Variant = value
+		case len(key) == 6 && strings.HasPrefix(key, "base"):
+			c, err := color.ParseHex("#" + strings.TrimPrefix(value, "#"))
+			if err != nil {
+				return Scheme{}, fmt.Errorf("line %d: %s: %w", line, key, err)
+			}
+			s.Colors[canonicalSlot(key)] = c.Hex()
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return Scheme{}, err
+	}
+
+	if s.System == "" {
+		s.System = "base16"
+		if _, ok := s.Colors["base10"]; ok {
+			s.System = "base24"
+		}
+	}
+	if s.System != "base16" && s.System != "base24" {
+		return Scheme{}, fmt.Errorf("unsupported scheme system %q", s.System)
+	}
+
+	var missing []string
+	for _, slot := range slots[:s.slotCount()] {
+		if _, ok := s.Colors[slot.base]; !ok {
+			missing = append(missing, slot.base)
+		}
+	}
+	if len(missing) > 0 {
+		return Scheme{}, fmt.Errorf("%s scheme is missing %s", s.System, strings.Join(missing, ", "))
+	}
+	return s, nil
+}
+
+// canonicalSlot spells a slot name the way the spec does ("base0a" ->
+// "base0A").
+func canonicalSlot(key string) string {
+	return "base" + strings.ToUpper(key[4:])
+}
+
+// yamlScalar extracts a scalar value, dropping quotes and trailing comments.
+func yamlScalar(s string) string {
+	s = strings.TrimSpace(s)
+	if len(s) > 0 && (s[0] == '"' || s[0] == '\'') {
+		if end := strings.IndexByte(s[1:], s[0]); end >= 0 {
+			return s[1 : end+1]
+		}
+	}
+	if i := strings.Index(s, " #"); i >= 0 {
+		s = s[:i]
+	}
+	return strings.TrimSpace(s)
+}
+
+// Encode renders the scheme in the current tinted-theming YAML layout.
+func (s Scheme) Encode() []byte {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "system: %q\n", s.System)
+	fmt.Fprintf(&b, "name: %q\n", s.Name)
+	fmt.Fprintf(&b, "author: %q\n", s.Author)
+	if s.Variant != "" {
+		fmt.Fprintf(&b, "variant: %q\n", s.Variant)
+	}
+	b.WriteString("palette:\n")
+	for _, slot := range slots[:s.slotCount()] {
+		fmt.Fprintf(&b, "  %s: %q\n", slot.base, s.Colors[slot.base])
+	}
+	return b.Bytes()
+}
@@ -0,0 +1,122 @@
+package base16_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/kylesnowschwartz/the-themer/palette"
+	"github.com/kylesnowschwartz/the-themer/palette/base16"
+)
+
+func loadBleu(t *testing.T) palette.Config {
+	t.Helper()
+	cfg, err := palette.Load("../../testdata/bleu.toml")
+	if err != nil {
+		t.Fatalf("Load bleu.toml: %v", err)
+	}
+	return cfg
+}
+
+// TestRoundTrip_Base24 exports a palette, parses the YAML back, and checks
+// every mapped key survives.
+func TestRoundTrip_Base24(t *testing.T) {
+	cfg := loadBleu(t)
+
+	s, err := base16.Parse(base16.FromConfig(cfg, "base24").Encode())
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if s.System != "base24" || s.Name != "bleu" || s.Author != "bnema" || s.Variant != "dark" {
+		t.Errorf("metadata = %+v", s)
+	}
+
+	got, want := s.Palette(), cfg.Palette
+	pairs := map[string][2]palette.Color{
+		"bg":                    {got.BG, want.BG},
+		"fg":                    {got.FG, want.FG},
+		"selection_bg":          {got.SelectionBG, want.SelectionBG},
+		"ui.dimmed":             {got.UI.Dimmed, want.UI.Dimmed},
+		"syntax.number":         {got.Syntax.Number, want.Syntax.Number},
+		"syntax.error":          {got.Syntax.Error, want.Syntax.Error},
+		"syntax.line_highlight": {got.Syntax.LineHighlight, want.Syntax.LineHighlight},
+	}
+	for i, c := range want.Colors() {
+		pairs[fmt.Sprintf("color%d", i)] = [2]palette.Color{got.Colors()[i], c}
+	}
+	for key, p := range pairs {
+		if !strings.EqualFold(string(p[0]), string(p[1])) {
+			t.Errorf("%s: got %s, want %s", key, p[0], p[1])
+		}
+	}
+
+	// Keys without a slot are left for ApplyDefaults.
+	if got.Cursor != "" || got.UI.Accent != "" {
+		t.Errorf("unmapped keys set: cursor=%q ui.accent=%q", got.Cursor, got.UI.Accent)
+	}
+}
+
+func TestPalette_Base16Fallbacks(t *testing.T) {
+	s, err := base16.Parse(base16.FromConfig(loadBleu(t), "base16").Encode())
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if s.System != "base16" || len(s.Colors) != base16.Base16Slots {
+		t.Fatalf("scheme = %s with %d slots, want base16 with 16", s.System, len(s.Colors))
+	}
+
+	p := s.Palette()
+	if p.Color0 != p.BG {
+		t.Errorf("color0 = %s, want base00 %s", p.Color0, p.BG)
+	}
+	if p.Color9 != p.Color1 || p.Color14 != p.Color6 {
+		t.Errorf("brights should repeat normals: color9=%s color1=%s color14=%s color6=%s", p.Color9, p.Color1, p.Color14, p.Color6)
+	}
+}
+
+func TestParse_Legacy(t *testing.T) {
+	s, err := base16.Parse([]byte(`scheme: "Tomorrow Night"
+author: "Chris Kempson (http://chriskempson.com)"
+base00: "1d1f21"
+base01: "282a2e"
+base02: "373b41"
+base03: "969896"
+base04: "b4b7b4"
+base05: "c5c8c6"
+base06: "e0e0e0"
+base07: "ffffff"
+base08: "cc6666"
+base09: "de935f"
+base0a: "f0c674" # yellow
+base0B: "b5bd68"
+base0C: "8abeb7"
+base0D: "81a2be"
+base0E: "b294bb"
+base0F: "a3685a"
+`))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if s.System != "base16" || s.Name != "Tomorrow Night" || s.Author != "Chris Kempson (http://chriskempson.com)" {
+		t.Errorf("metadata = %+v", s)
+	}
+	if s.Colors["base0A"] != "#f0c674" || s.Colors["base00"] != "#1d1f21" {
+		t.Errorf("colors = %v", s.Colors)
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	tests := []struct {
+		yaml, want string
+	}{
+		{"system: \"base16\"\npalette:\n  base00: \"#000000\"\n", "base16 scheme is missing base01"},
+		{"system: \"base8\"\n", `unsupported scheme system "base8"`},
+		{"palette:\n  base00: \"#12345\"\n", "line 2: base00"},
+	}
+	for _, tc := range tests {
+		_, err := base16.Parse([]byte(tc.yaml))
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("Parse(%q) error = %v, want containing %q", tc.yaml, err, tc.want)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package palette
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kylesnowschwartz/the-themer/palette/color"
+)
+
+// Color is a palette color value. In palette.toml it may be written as
+// "#rgb", "#rrggbb", "#rrggbbaa", or in rgb(), hsl(), or oklch() notation
+// (see color.Parse); decoding normalizes these to hex. Six- and eight-digit
+// hex is kept exactly as written, so generated files reproduce the palette's
+// own spelling. Values that aren't color literals (expressions, or typos for
+// Validate to report) are kept verbatim.
+//
+// The accessors below are for validated palettes, such as those returned by
+// Load; they panic on a value that isn't hex. Adapter templates can call
+// them directly, e.g. {{.Palette.BG.Bare}} or {{.Palette.FG.RGB.R}}.
+type Color string
+
+// UnmarshalText implements encoding.TextUnmarshaler for TOML decoding.
+// Malformed rgb(), hsl(), and oklch() values are decoding errors, since
+// they would otherwise be mistaken for expressions.
+func (c *Color) UnmarshalText(text []byte) error {
+	s := string(text)
+	if isNotation(s) {
+		rgb, alpha, err := color.Parse(s)
+		if err != nil {
+			return err
+		}
+		s = rgb.HexAlpha(alpha)
+	} else if t := strings.TrimSpace(s); len(t) == 4 && t[0] == '#' {
+		if rgb, alpha, err := color.Parse(t); err == nil {
+			s = rgb.HexAlpha(alpha)
+		}
+	}
+	*c = Color(s)
+	return nil
+}
+
+// isNotation reports whether s is written in a CSS functional notation
+// rather than as hex or an expression.
+func isNotation(s string) bool {
+	name, _, ok := strings.Cut(strings.TrimSpace(s), "(")
+	if !ok {
+		return false
+	}
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "rgb", "rgba", "hsl", "hsla", "oklch":
+		return true
+	}
+	return false
+}
+
+// String returns the color as written (after normalization).
+func (c Color) String() string { return string(c) }
+
+// HasAlpha reports whether the color carries an alpha channel.
+func (c Color) HasAlpha() bool { return HasAlpha(string(c)) }
+
+// RGB returns the color's sRGB channels, ignoring any alpha.
+func (c Color) RGB() color.RGB {
+	rgb, _ := c.parse()
+	return rgb
+}
+
+// Alpha returns the color's opacity, 0-1.
+func (c Color) Alpha() float64 {
+	_, alpha := c.parse()
+	return alpha
+}
+
+// HSL returns the color in HSL.
+func (c Color) HSL() color.HSL { return c.RGB().HSL() }
+
+// OKLCH returns the color in OKLCH.
+func (c Color) OKLCH() color.OKLCH { return c.RGB().OKLCH() }
+
+// Hex returns the color as lowercase "#rrggbb", dropping any alpha.
+func (c Color) Hex() string { return c.RGB().Hex() }
+
+// Bare returns the color as lowercase "rrggbb", without the leading "#".
+func (c Color) Bare() string { return c.Hex()[1:] }
+
+// Floats returns the red, green, and blue channels scaled to 0-1.
+func (c Color) Floats() [3]float64 {
+	rgb := c.RGB()
+	return [3]float64{float64(rgb.R) / 255, float64(rgb.G) / 255, float64(rgb.B) / 255}
+}
+
+func (c Color) parse() (color.RGB, float64) {
+	rgb, alpha, err := color.ParseHexAlpha(string(c))
+	if err != nil {
+		panic(fmt.Sprintf("palette: color accessor on invalid value: %v", err))
+	}
+	return rgb, alpha
+}
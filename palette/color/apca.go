@@ -0,0 +1,83 @@
+package color
+
+import "math"
+
+// APCA-W3 0.0.98G-4g constants, vendored from Myndex/apca-w3
+// (src/apca-w3.js, W3C Software and Document License).
+const (
+	// sRGB coefficients for luminance.
+	apcaSR = 0.2126729
+	apcaSG = 0.7151522
+	apcaSB = 0.0721750
+
+	// Exponents: normal polarity (dark text on light bg).
+	apcaNormBG  = 0.56
+	apcaNormTxt = 0.57
+
+	// Exponents: reverse polarity (light text on dark bg).
+	apcaRevBG  = 0.65
+	apcaRevTxt = 0.62
+
+	// Soft-clamp for near-black.
+	apcaBlkThrs = 0.022
+	apcaBlkClmp = 1.414
+
+	// Output scaling.
+	apcaScaleBoW    = 1.14
+	apcaScaleWoB    = 1.14
+	apcaLoBoWOffset = 0.027
+	apcaLoWoBOffset = 0.027
+	apcaLoClip      = 0.1
+
+	// Minimum luminance delta.
+	apcaDeltaYMin = 0.0005
+
+	// TRC (gamma). APCA uses a simple 2.4 power, not the piecewise sRGB curve.
+	apcaTRC = 2.4
+)
+
+// apcaY converts an sRGB color to APCA screen luminance.
+func apcaY(c RGB) float64 {
+	r := math.Pow(float64(c.R)/255, apcaTRC)
+	g := math.Pow(float64(c.G)/255, apcaTRC)
+	b := math.Pow(float64(c.B)/255, apcaTRC)
+	return apcaSR*r + apcaSG*g + apcaSB*b
+}
+
+// APCA computes the APCA lightness contrast (Lc) of text on bg.
+//
+// The result is signed Lc: positive for dark text on a light background
+// (BoW), negative for light text on a dark background (WoB).
+func APCA(text, bg RGB) float64 {
+	txtY := apcaY(text)
+	bgY := apcaY(bg)
+
+	// Soft-clamp near-black.
+	if txtY <= apcaBlkThrs {
+		txtY += math.Pow(apcaBlkThrs-txtY, apcaBlkClmp)
+	}
+	if bgY <= apcaBlkThrs {
+		bgY += math.Pow(apcaBlkThrs-bgY, apcaBlkClmp)
+	}
+
+	// Bail on negligible difference.
+	if math.Abs(bgY-txtY) < apcaDeltaYMin {
+		return 0
+	}
+
+	// Normal polarity: dark text on light bg.
+	if bgY > txtY {
+		sapc := (math.Pow(bgY, apcaNormBG) - math.Pow(txtY, apcaNormTxt)) * apcaScaleBoW
+		if sapc < apcaLoClip {
+			return 0
+		}
+		return (sapc - apcaLoBoWOffset) * 100
+	}
+
+	// Reverse polarity: light text on dark bg.
+	sapc := (math.Pow(bgY, apcaRevBG) - math.Pow(txtY, apcaRevTxt)) * apcaScaleWoB
+	if sapc > -apcaLoClip {
+		return 0
+	}
+	return (sapc + apcaLoWoBOffset) * 100
+}
@@ -0,0 +1,196 @@
+// Package color implements the perceptual color math the-themer uses to
+// reason about palettes: sRGB hex parsing, OKLab/OKLCH conversion, APCA-W3
+// lightness contrast, and deltaE_OK distance.
+//
+// The conversions follow the CSS Color 4 reference matrices (the same ones
+// coloraide uses), so numbers agree with the original Python audit script.
+package color
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// RGB is an sRGB color with 8-bit channels.
+type RGB struct {
+	R, G, B uint8
+}
+
+// ParseHex parses a "#RRGGBB" string into an RGB value.
+func ParseHex(s string) (RGB, error) {
+	if len(s) != 7 || s[0] != '#' {
+		return RGB{}, fmt.Errorf("invalid hex color %q (expected #RRGGBB)", s)
+	}
+	v, err := strconv.ParseUint(s[1:], 16, 32)
+	if err != nil {
+		return RGB{}, fmt.Errorf("invalid hex color %q (expected #RRGGBB)", s)
+	}
+	return RGB{R: uint8(v >> 16), G: uint8(v >> 8), B: uint8(v)}, nil
+}
+
+// MustParseHex is like ParseHex but panics on malformed input. Intended for
+// callers that have already validated the palette.
+func MustParseHex(s string) RGB {
+	c, err := ParseHex(s)
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
+// Hex formats the color as lowercase "#rrggbb".
+func (c RGB) Hex() string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
+
+// ParseHexAlpha parses "#RRGGBB" or "#RRGGBBAA" into a color and its
+// opacity (0-1). Six-digit colors are fully opaque.
+func ParseHexAlpha(s string) (RGB, float64, error) {
+	if len(s) != 9 {
+		c, err := ParseHex(s)
+		if err != nil {
+			return RGB{}, 0, fmt.Errorf("invalid hex color %q (expected #RRGGBB or #RRGGBBAA)", s)
+		}
+		return c, 1, nil
+	}
+	c, err := ParseHex(s[:7])
+	a, aerr := strconv.ParseUint(s[7:], 16, 8)
+	if err != nil || aerr != nil {
+		return RGB{}, 0, fmt.Errorf("invalid hex color %q (expected #RRGGBB or #RRGGBBAA)", s)
+	}
+	return c, float64(a) / 255, nil
+}
+
+// HexAlpha formats the color with the given opacity as lowercase
+// "#rrggbbaa", or "#rrggbb" when the opacity rounds to fully opaque.
+func (c RGB) HexAlpha(alpha float64) string {
+	a := toByte(alpha)
+	if a == 255 {
+		return c.Hex()
+	}
+	return fmt.Sprintf("%s%02x", c.Hex(), a)
+}
+
+// OKLab is a color in the OKLab perceptual space.
+type OKLab struct {
+	L, A, B float64
+}
+
+// OKLCH is the cylindrical form of OKLab. L is 0-1, C is 0 to roughly 0.4,
+// and H is a hue angle in degrees (0 for achromatic colors).
+type OKLCH struct {
+	L, C, H float64
+}
+
+// Chroma thresholds used by the audit to decide how much to trust a hue.
+const (
+	// AchromaticChroma is the chroma below which a color reads as grey.
+	AchromaticChroma = 0.02
+	// UnreliableHueChroma is the chroma below which the hue angle is noisy.
+	UnreliableHueChroma = 0.04
+)
+
+// IsAchromatic reports whether the color is effectively grey.
+func (c OKLCH) IsAchromatic() bool { return c.C < AchromaticChroma }
+
+// HueUnreliable reports whether the chroma is too low for the hue to be
+// perceptually meaningful.
+func (c OKLCH) HueUnreliable() bool { return c.C < UnreliableHueChroma }
+
+// Matrices from the CSS Color 4 sample code (linear sRGB -> XYZ D65 -> LMS
+// -> OKLab).
+var (
+	rgbToXYZ = [3][3]float64{
+		{0.4123907992659595, 0.357584339383878, 0.1804807884018343},
+		{0.21263900587151036, 0.715168678767756, 0.07219231536073371},
+		{0.01933081871559185, 0.11919477979462598, 0.9505321522496606},
+	}
+	xyzToLMS = [3][3]float64{
+		{0.819022437996703, 0.3619062600528904, -0.1288737815209879},
+		{0.0329836539323885, 0.9292868615863434, 0.0361446663506424},
+		{0.0481771893596242, 0.2642395317527308, 0.6335478284694309},
+	}
+	lmsToOKLab = [3][3]float64{
+		{0.210454268309314, 0.7936177747023054, -0.0040720430116193},
+		{1.9779985324311684, -2.4285922420485799, 0.450593709617411},
+		{0.0259040424655478, 0.7827717124575296, -0.8086757549230774},
+	}
+)
+
+// achromaticHueThreshold is the chroma below which the hue is undefined and
+// reported as 0, matching coloraide's NaN-hue handling.
+const achromaticHueThreshold = 0.000002
+
+// srgbToLinear applies the piecewise sRGB transfer function.
+func srgbToLinear(c float64) float64 {
+	if math.Abs(c) <= 0.04045 {
+		return c / 12.92
+	}
+	return math.Copysign(math.Pow((math.Abs(c)+0.055)/1.055, 2.4), c)
+}
+
+func mul(m [3][3]float64, v [3]float64) [3]float64 {
+	return [3]float64{
+		m[0][0]*v[0] + m[0][1]*v[1] + m[0][2]*v[2],
+		m[1][0]*v[0] + m[1][1]*v[1] + m[1][2]*v[2],
+		m[2][0]*v[0] + m[2][1]*v[1] + m[2][2]*v[2],
+	}
+}
+
+// OKLab converts the color to OKLab.
+func (c RGB) OKLab() OKLab {
+	lin := [3]float64{
+		srgbToLinear(float64(c.R) / 255),
+		srgbToLinear(float64(c.G) / 255),
+		srgbToLinear(float64(c.B) / 255),
+	}
+	lms := mul(xyzToLMS, mul(rgbToXYZ, lin))
+	for i := range lms {
+		lms[i] = math.Cbrt(lms[i])
+	}
+	lab := mul(lmsToOKLab, lms)
+	return OKLab{L: lab[0], A: lab[1], B: lab[2]}
+}
+
+// OKLCH converts the color to OKLCH.
+func (c RGB) OKLCH() OKLCH {
+	return c.OKLab().LCH()
+}
+
+// Luminance returns the WCAG relative luminance (0 for black, 1 for white).
+func (c RGB) Luminance() float64 {
+	return 0.2126*srgbToLinear(float64(c.R)/255) +
+		0.7152*srgbToLinear(float64(c.G)/255) +
+		0.0722*srgbToLinear(float64(c.B)/255)
+}
+
+// IsLight reports whether the color reads as a light background: dark text
+// has more WCAG contrast against it than light text does.
+func (c RGB) IsLight() bool {
+	// Black and white text have equal contrast at L = sqrt(1.05*0.05) - 0.05.
+	return c.Luminance() > 0.1791287847
+}
+
+// LCH converts OKLab to its cylindrical OKLCH form.
+func (c OKLab) LCH() OKLCH {
+	chroma := math.Hypot(c.A, c.B)
+	hue := 0.0
+	if chroma >= achromaticHueThreshold {
+		hue = math.Mod(math.Atan2(c.B, c.A)*180/math.Pi+360, 360)
+	}
+	return OKLCH{L: c.L, C: chroma, H: hue}
+}
+
+// DeltaEOK returns the Euclidean distance between two colors in OKLab.
+func DeltaEOK(a, b RGB) float64 {
+	la, lb := a.OKLab(), b.OKLab()
+	dl, da, db := la.L-lb.L, la.A-lb.A, la.B-lb.B
+	return math.Sqrt(dl*dl + da*da + db*db)
+}
+
+// HueDistance returns the circular distance between two hue angles in degrees.
+func HueDistance(h1, h2 float64) float64 {
+	d := math.Mod(math.Abs(h1-h2), 360)
+	return math.Min(d, 360-d)
+}
@@ -0,0 +1,238 @@
+package color_test
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/kylesnowschwartz/the-themer/palette/color"
+)
+
+// Reference values below were produced by scripts/contrast-audit.py
+// (coloraide for OKLCH/deltaE, vendored apca-w3 for APCA) before it was
+// replaced by the Go audit.
+
+func TestParseHex(t *testing.T) {
+	c, err := color.ParseHex("#3BA5ff")
+	if err != nil {
+		t.Fatalf("ParseHex: %v", err)
+	}
+	if c != (color.RGB{R: 0x3b, G: 0xa5, B: 0xff}) {
+		t.Errorf("ParseHex = %+v", c)
+	}
+	if got := c.Hex(); got != "#3ba5ff" {
+		t.Errorf("Hex() = %q, want %q", got, "#3ba5ff")
+	}
+
+	for _, bad := range []string{"3ba5ff", "#fff", "#3ba5ff00", "#gggggg", ""} {
+		if _, err := color.ParseHex(bad); err == nil {
+			t.Errorf("ParseHex(%q): expected error", bad)
+		}
+	}
+}
+
+func TestAPCA(t *testing.T) {
+	tests := []struct {
+		text, bg string
+		want     float64
+	}{
+		{"#8ff586", "#142838", -83.53600912766724},
+		{"#3d2b5a", "#f6f2ee", 90.69552025636264},
+		{"#000000", "#ffffff", 106.04067321268862},
+		{"#ffffff", "#000000", -107.88473318309848},
+		{"#777777", "#787878", 0},
+	}
+	for _, tc := range tests {
+		got := color.APCA(color.MustParseHex(tc.text), color.MustParseHex(tc.bg))
+		if math.Abs(got-tc.want) > 1e-9 {
+			t.Errorf("APCA(%s on %s) = %v, want %v", tc.text, tc.bg, got, tc.want)
+		}
+	}
+}
+
+func TestOKLCH(t *testing.T) {
+	tests := []struct {
+		hex     string
+		l, c, h float64
+	}{
+		{"#3ba5ff", 0.703722991457277, 0.162686487935086, 248.40395036841255},
+		{"#a5222f", 0.47433517059374253, 0.16589156728228727, 21.570688390753972},
+		{"#555555", 0.4495331968941366, 0, 0},
+		{"#ffffff", 1, 0, 0},
+	}
+	for _, tc := range tests {
+		got := color.MustParseHex(tc.hex).OKLCH()
+		if math.Abs(got.L-tc.l) > 1e-6 || math.Abs(got.C-tc.c) > 1e-6 || math.Abs(got.H-tc.h) > 1e-4 {
+			t.Errorf("OKLCH(%s) = %+v, want {L:%v C:%v H:%v}", tc.hex, got, tc.l, tc.c, tc.h)
+		}
+	}
+
+	if !color.MustParseHex("#555555").OKLCH().IsAchromatic() {
+		t.Error("#555555 should be achromatic")
+	}
+	if color.MustParseHex("#3ba5ff").OKLCH().HueUnreliable() {
+		t.Error("#3ba5ff hue should be reliable")
+	}
+}
+
+func TestDeltaEOK(t *testing.T) {
+	got := color.DeltaEOK(color.MustParseHex("#a5222f"), color.MustParseHex("#b3434e"))
+	if want := 0.06868184556564758; math.Abs(got-want) > 1e-6 {
+		t.Errorf("DeltaEOK = %v, want %v", got, want)
+	}
+}
+
+func TestHueDistance(t *testing.T) {
+	tests := []struct{ a, b, want float64 }{
+		{10, 350, 20},
+		{350, 10, 20},
+		{0, 180, 180},
+		{90, 90, 0},
+	}
+	for _, tc := range tests {
+		if got := color.HueDistance(tc.a, tc.b); got != tc.want {
+			t.Errorf("HueDistance(%v, %v) = %v, want %v", tc.a, tc.b, got, tc.want)
+		}
+	}
+}
+
+func TestOKLCH_RGBRoundTrip(t *testing.T) {
+	for _, hex := range []string{"#3ba5ff", "#a5222f", "#555555", "#000000", "#ffffff", "#8ff586"} {
+		c := color.MustParseHex(hex)
+		if got := c.OKLCH().RGB(); got != c {
+			t.Errorf("%s round-trips to %s", hex, got.Hex())
+		}
+	}
+}
+
+func TestOKLCH_RGBGamutMaps(t *testing.T) {
+	// A chroma no sRGB color reaches: lightness and hue must survive.
+	in := color.OKLCH{L: 0.7, C: 0.5, H: 250}
+	got := in.RGB().OKLCH()
+	if math.Abs(got.L-in.L) > 0.01 || color.HueDistance(got.H, in.H) > 2 {
+		t.Errorf("gamut-mapped %+v = %+v, want L and H preserved", in, got)
+	}
+}
+
+func TestLightenDarken(t *testing.T) {
+	c := color.MustParseHex("#3ba5ff")
+	base := c.OKLCH()
+
+	lighter := color.Lighten(c, 0.08).OKLCH()
+	if math.Abs(lighter.L-(base.L+0.08)) > 0.005 || color.HueDistance(lighter.H, base.H) > 2 {
+		t.Errorf("Lighten(0.08) = %+v, from %+v", lighter, base)
+	}
+	darker := color.Darken(c, 0.08).OKLCH()
+	if math.Abs(darker.L-(base.L-0.08)) > 0.005 || color.HueDistance(darker.H, base.H) > 2 {
+		t.Errorf("Darken(0.08) = %+v, from %+v", darker, base)
+	}
+	if got := color.Lighten(color.MustParseHex("#ffffff"), 0.1); got.Hex() != "#ffffff" {
+		t.Errorf("Lighten(white) = %s, want white", got.Hex())
+	}
+}
+
+func TestMix(t *testing.T) {
+	a, b := color.MustParseHex("#142838"), color.MustParseHex("#e8f4f8")
+	if got := color.Mix(a, b, 0); got != a {
+		t.Errorf("Mix(t=0) = %s, want %s", got.Hex(), a.Hex())
+	}
+	if got := color.Mix(a, b, 1); got != b {
+		t.Errorf("Mix(t=1) = %s, want %s", got.Hex(), b.Hex())
+	}
+	mid := color.Mix(a, b, 0.5).OKLCH()
+	if want := (a.OKLCH().L + b.OKLCH().L) / 2; math.Abs(mid.L-want) > 0.005 {
+		t.Errorf("Mix(t=0.5).L = %v, want %v", mid.L, want)
+	}
+
+	// Mixing toward grey keeps the chromatic side's hue.
+	red := color.MustParseHex("#a5222f")
+	if got := color.Mix(red, color.MustParseHex("#808080"), 0.5).OKLCH(); color.HueDistance(got.H, red.OKLCH().H) > 2 {
+		t.Errorf("Mix(red, grey) hue = %v, want near %v", got.H, red.OKLCH().H)
+	}
+}
+
+func TestComposite(t *testing.T) {
+	fg, bg := color.MustParseHex("#ffffff"), color.MustParseHex("#000000")
+	if got := color.Composite(fg, 0.5, bg).Hex(); got != "#808080" {
+		t.Errorf("Composite(50%%) = %s, want #808080", got)
+	}
+	if got := color.Composite(fg, 1, bg); got != fg {
+		t.Errorf("Composite(100%%) = %s, want fg", got.Hex())
+	}
+	if got := color.Composite(fg, 0, bg); got != bg {
+		t.Errorf("Composite(0%%) = %s, want bg", got.Hex())
+	}
+}
+
+func TestParseHexAlpha(t *testing.T) {
+	tests := []struct {
+		in    string
+		want  string
+		alpha float64
+	}{
+		{"#3344ff", "#3344ff", 1},
+		{"#3344ff80", "#3344ff", 128.0 / 255},
+		{"#3344FF00", "#3344ff", 0},
+	}
+	for _, tc := range tests {
+		c, a, err := color.ParseHexAlpha(tc.in)
+		if err != nil {
+			t.Fatalf("ParseHexAlpha(%s): %v", tc.in, err)
+		}
+		if c.Hex() != tc.want || a != tc.alpha {
+			t.Errorf("ParseHexAlpha(%s) = %s, %v; want %s, %v", tc.in, c.Hex(), a, tc.want, tc.alpha)
+		}
+		if got := c.HexAlpha(a); got != strings.ToLower(tc.in) {
+			t.Errorf("HexAlpha round trip of %s = %s", tc.in, got)
+		}
+	}
+
+	for _, bad := range []string{"#3344f", "#3344ff8", "#3344ffgg", "3344ff80"} {
+		if _, _, err := color.ParseHexAlpha(bad); err == nil {
+			t.Errorf("ParseHexAlpha(%s): expected error", bad)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in    string
+		want  string
+		alpha float64
+	}{
+		{"#f80", "#ff8800", 1},
+		{"#ff8800cc", "#ff8800", 0.8},
+		{"rgb(255 136 0)", "#ff8800", 1},
+		{"rgb(255, 136, 0)", "#ff8800", 1},
+		{"RGBA(100% 0% 0% / 25%)", "#ff0000", 0.25},
+		{"hsl(120 100% 25%)", "#008000", 1},
+		{"hsla(0, 0%, 100%, 0.5)", "#ffffff", 0.5},
+		{"oklch(62.8% 0.2577 29.23)", "#ff0000", 1},
+		{"oklch(1 0 0)", "#ffffff", 1},
+	}
+	for _, tc := range tests {
+		c, a, err := color.Parse(tc.in)
+		if err != nil {
+			t.Errorf("Parse(%s): %v", tc.in, err)
+			continue
+		}
+		if c.Hex() != tc.want || math.Abs(a-tc.alpha) > 0.002 {
+			t.Errorf("Parse(%s) = %s, %v; want %s, %v", tc.in, c.Hex(), a, tc.want, tc.alpha)
+		}
+	}
+
+	for _, bad := range []string{"rgb(1, 2)", "hsl(a, 1%, 1%)", "lab(50 0 0)", "rgb(1 2 3", "red"} {
+		if _, _, err := color.Parse(bad); err == nil {
+			t.Errorf("Parse(%s): expected error", bad)
+		}
+	}
+}
+
+func TestHSLRoundTrip(t *testing.T) {
+	for _, hex := range []string{"#000000", "#ffffff", "#ff8800", "#2d4a6b", "#a167a5", "#99ffe4"} {
+		c := color.MustParseHex(hex)
+		if got := c.HSL().RGB(); got != c {
+			t.Errorf("%s -> %+v -> %s", hex, c.HSL(), got.Hex())
+		}
+	}
+}
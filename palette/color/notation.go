@@ -0,0 +1,164 @@
+package color
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// HSL is a color in the sRGB-based HSL space. H is a hue angle in degrees,
+// S and L are 0-1.
+type HSL struct {
+	H, S, L float64
+}
+
+// HSL converts the color to HSL. Greys report a hue and saturation of 0.
+func (c RGB) HSL() HSL {
+	r, g, b := float64(c.R)/255, float64(c.G)/255, float64(c.B)/255
+	hi, lo := math.Max(r, math.Max(g, b)), math.Min(r, math.Min(g, b))
+	l := (hi + lo) / 2
+	d := hi - lo
+	if d == 0 {
+		return HSL{L: l}
+	}
+
+	s := d / (1 - math.Abs(2*l-1))
+	var h float64
+	switch hi {
+	case r:
+		h = math.Mod((g-b)/d+6, 6)
+	case g:
+		h = (b-r)/d + 2
+	default:
+		h = (r-g)/d + 4
+	}
+	return HSL{H: h * 60, S: s, L: l}
+}
+
+// RGB converts an HSL color to 8-bit sRGB.
+func (c HSL) RGB() RGB {
+	ch := (1 - math.Abs(2*c.L-1)) * c.S
+	h := math.Mod(math.Mod(c.H, 360)+360, 360) / 60
+	x := ch * (1 - math.Abs(math.Mod(h, 2)-1))
+
+	var r, g, b float64
+	switch {
+	case h < 1:
+		r, g = ch, x
+	case h < 2:
+		r, g = x, ch
+	case h < 3:
+		g, b = ch, x
+	case h < 4:
+		g, b = x, ch
+	case h < 5:
+		r, b = x, ch
+	default:
+		r, b = ch, x
+	}
+	m := c.L - ch/2
+	return RGB{R: toByte(r + m), G: toByte(g + m), B: toByte(b + m)}
+}
+
+// Parse parses a color written in any notation palettes accept and returns
+// it with its opacity (0-1):
+//
+//	#rgb #rrggbb #rrggbbaa
+//	rgb(255 128 0)  rgb(255, 128, 0)  rgb(100% 50% 0% / 0.5)
+//	hsl(30 100% 50%)  hsl(30, 100%, 50%, 50%)
+//	oklch(0.7 0.15 50)  oklch(70% 0.15 50 / 80%)
+//
+// Arguments may be separated by commas or spaces; an alpha argument follows
+// "/" or a fourth comma. rgba() and hsla() are accepted as aliases.
+// oklch() colors outside sRGB are gamut-mapped by reducing chroma.
+func Parse(s string) (RGB, float64, error) {
+	s = strings.TrimSpace(s)
+	if strings.HasPrefix(s, "#") {
+		if len(s) == 4 {
+			return ParseHexAlpha(string([]byte{'#', s[1], s[1], s[2], s[2], s[3], s[3]}))
+		}
+		return ParseHexAlpha(s)
+	}
+
+	name, body, ok := strings.Cut(s, "(")
+	if !ok || !strings.HasSuffix(body, ")") {
+		return RGB{}, 0, fmt.Errorf("invalid color %q", s)
+	}
+	name = strings.ToLower(strings.TrimSpace(name))
+	args, alpha, err := splitArgs(strings.TrimSuffix(body, ")"))
+	if err != nil {
+		return RGB{}, 0, fmt.Errorf("invalid %s() color %q: %w", name, s, err)
+	}
+
+	var c RGB
+	switch name {
+	case "rgb", "rgba":
+		var ch [3]float64
+		for i, a := range args {
+			if ch[i], err = component(a, 255); err != nil {
+				break
+			}
+		}
+		c = RGB{R: toByte(ch[0] / 255), G: toByte(ch[1] / 255), B: toByte(ch[2] / 255)}
+	case "hsl", "hsla":
+		var h, sat, l float64
+		if h, err = strconv.ParseFloat(strings.TrimSuffix(args[0], "deg"), 64); err == nil {
+			if sat, err = component(args[1], 100); err == nil {
+				l, err = component(args[2], 100)
+			}
+		}
+		c = HSL{H: h, S: sat / 100, L: l / 100}.RGB()
+	case "oklch":
+		var l, ch, h float64
+		if l, err = component(args[0], 1); err == nil {
+			if ch, err = component(args[1], 0.4); err == nil {
+				h, err = strconv.ParseFloat(strings.TrimSuffix(args[2], "deg"), 64)
+			}
+		}
+		c = OKLCH{L: l, C: ch, H: h}.RGB()
+	default:
+		return RGB{}, 0, fmt.Errorf("invalid color %q: unknown notation %s()", s, name)
+	}
+	if err != nil {
+		return RGB{}, 0, fmt.Errorf("invalid %s() color %q: %w", name, s, err)
+	}
+
+	a := 1.0
+	if alpha != "" {
+		if a, err = component(alpha, 1); err != nil {
+			return RGB{}, 0, fmt.Errorf("invalid %s() color %q: alpha: %w", name, s, err)
+		}
+	}
+	return c, math.Max(0, math.Min(1, a)), nil
+}
+
+// splitArgs splits a functional notation's arguments into three channel
+// values and an optional alpha.
+func splitArgs(body string) ([]string, string, error) {
+	var alpha string
+	if main, a, ok := strings.Cut(body, "/"); ok {
+		body, alpha = main, strings.TrimSpace(a)
+	}
+	args := strings.FieldsFunc(body, func(r rune) bool { return r == ',' || r == ' ' || r == '\t' })
+	if len(args) == 4 && alpha == "" {
+		args, alpha = args[:3], args[3]
+	}
+	if len(args) != 3 {
+		return nil, "", fmt.Errorf("want 3 channels, got %d", len(args))
+	}
+	return args, alpha, nil
+}
+
+// component parses a number, or a percentage of full.
+func component(s string, full float64) (float64, error) {
+	pct := strings.HasSuffix(s, "%")
+	v, err := strconv.ParseFloat(strings.TrimSuffix(s, "%"), 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid number %q", s)
+	}
+	if pct {
+		v = v / 100 * full
+	}
+	return v, nil
+}
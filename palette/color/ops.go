@@ -0,0 +1,148 @@
+package color
+
+import "math"
+
+// Inverse conversion matrices, derived from the forward ones at init so the
+// round trip is exact to floating-point precision.
+var (
+	oklabToLMS = invert(lmsToOKLab)
+	lmsToXYZ   = invert(xyzToLMS)
+	xyzToRGB   = invert(rgbToXYZ)
+)
+
+// invert returns the inverse of a 3x3 matrix.
+func invert(m [3][3]float64) [3][3]float64 {
+	a, b, c := m[0][0], m[0][1], m[0][2]
+	d, e, f := m[1][0], m[1][1], m[1][2]
+	g, h, i := m[2][0], m[2][1], m[2][2]
+	det := a*(e*i-f*h) - b*(d*i-f*g) + c*(d*h-e*g)
+	return [3][3]float64{
+		{(e*i - f*h) / det, (c*h - b*i) / det, (b*f - c*e) / det},
+		{(f*g - d*i) / det, (a*i - c*g) / det, (c*d - a*f) / det},
+		{(d*h - e*g) / det, (b*g - a*h) / det, (a*e - b*d) / det},
+	}
+}
+
+// linearToSRGB applies the inverse sRGB transfer function.
+func linearToSRGB(c float64) float64 {
+	if math.Abs(c) <= 0.0031308 {
+		return c * 12.92
+	}
+	return math.Copysign(1.055*math.Pow(math.Abs(c), 1/2.4)-0.055, c)
+}
+
+// gamma returns the unclamped gamma-encoded sRGB channels (0-1 in gamut).
+func (c OKLab) gamma() [3]float64 {
+	lms := mul(oklabToLMS, [3]float64{c.L, c.A, c.B})
+	for i := range lms {
+		lms[i] = lms[i] * lms[i] * lms[i]
+	}
+	rgb := mul(xyzToRGB, mul(lmsToXYZ, lms))
+	for i := range rgb {
+		rgb[i] = linearToSRGB(rgb[i])
+	}
+	return rgb
+}
+
+// inGamut reports whether every channel lies in [0, 1], with a small
+// tolerance for rounding noise.
+func inGamut(rgb [3]float64) bool {
+	const eps = 1e-6
+	for _, v := range rgb {
+		if v < -eps || v > 1+eps {
+			return false
+		}
+	}
+	return true
+}
+
+func toByte(v float64) uint8 {
+	return uint8(math.Round(math.Max(0, math.Min(1, v)) * 255))
+}
+
+// RGB converts an OKLab color to 8-bit sRGB, clamping out-of-gamut channels.
+// Prefer OKLCH.RGB, which gamut-maps by reducing chroma instead.
+func (c OKLab) RGB() RGB {
+	rgb := c.gamma()
+	return RGB{R: toByte(rgb[0]), G: toByte(rgb[1]), B: toByte(rgb[2])}
+}
+
+// Lab converts OKLCH back to its rectangular OKLab form.
+func (c OKLCH) Lab() OKLab {
+	rad := c.H * math.Pi / 180
+	return OKLab{L: c.L, A: c.C * math.Cos(rad), B: c.C * math.Sin(rad)}
+}
+
+// RGB converts the color to 8-bit sRGB. Colors outside the sRGB gamut keep
+// their lightness and hue while chroma is reduced until they fit.
+func (c OKLCH) RGB() RGB {
+	c.L = math.Max(0, math.Min(1, c.L))
+	if c.C < 0 {
+		c.C = 0
+	}
+	if inGamut(c.Lab().gamma()) {
+		return c.Lab().RGB()
+	}
+
+	lo, hi := 0.0, c.C
+	for hi-lo > 1e-5 {
+		mid := (lo + hi) / 2
+		c.C = mid
+		if inGamut(c.Lab().gamma()) {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	c.C = lo
+	return c.Lab().RGB()
+}
+
+// Lighten raises OKLCH lightness by amount (0-1), keeping chroma and hue.
+func Lighten(c RGB, amount float64) RGB {
+	o := c.OKLCH()
+	o.L += amount
+	return o.RGB()
+}
+
+// Darken lowers OKLCH lightness by amount (0-1), keeping chroma and hue.
+func Darken(c RGB, amount float64) RGB {
+	return Lighten(c, -amount)
+}
+
+// Mix interpolates from a to b in OKLCH. t=0 yields a, t=1 yields b. Hue
+// travels the shorter way around the circle; when one side is a neutral
+// grey its hue is meaningless, so the other side's hue is used instead.
+func Mix(a, b RGB, t float64) RGB {
+	oa, ob := a.OKLCH(), b.OKLCH()
+
+	const neutral = 1e-4
+	ha, hb := oa.H, ob.H
+	switch {
+	case oa.C < neutral && ob.C >= neutral:
+		ha = hb
+	case ob.C < neutral && oa.C >= neutral:
+		hb = ha
+	}
+	dh := hb - ha
+	if dh > 180 {
+		dh -= 360
+	} else if dh < -180 {
+		dh += 360
+	}
+
+	return OKLCH{
+		L: oa.L + (ob.L-oa.L)*t,
+		C: oa.C + (ob.C-oa.C)*t,
+		H: math.Mod(ha+dh*t+360, 360),
+	}.RGB()
+}
+
+// Composite blends fg at the given opacity (0-1) over an opaque bg in
+// gamma-encoded sRGB, the way terminals and most UIs draw translucent color.
+func Composite(fg RGB, alpha float64, bg RGB) RGB {
+	blend := func(f, b uint8) uint8 {
+		return uint8(math.Round(float64(f)*alpha + float64(b)*(1-alpha)))
+	}
+	return RGB{R: blend(fg.R, bg.R), G: blend(fg.G, bg.G), B: blend(fg.B, bg.B)}
+}
@@ -0,0 +1,56 @@
+package palette
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// SetKey sets key to a string value inside [table] of a palette.toml,
+// editing the text in place so comments and layout survive. An existing
+// assignment is rewritten (keeping any trailing comment); otherwise the key
+// is inserted after the table's last assignment.
+func SetKey(data []byte, table, key, value string) ([]byte, error) {
+	lines := strings.SplitAfter(string(data), "\n")
+	assign := regexp.MustCompile(`^(\s*` + regexp.QuoteMeta(key) + `\s*=\s*)("[^"]*"|'[^']*'|[^\s#]+)(.*)$`)
+	quoted := strconv.Quote(value)
+
+	inTable, found := false, false
+	last := -1 // index of the last assignment line in the table
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "[") {
+			name := strings.TrimSpace(strings.Trim(strings.SplitN(trimmed, "#", 2)[0], "[] \t"))
+			if inTable {
+				break
+			}
+			inTable = name == table
+			found = found || inTable
+			if inTable {
+				last = i
+			}
+			continue
+		}
+		if !inTable || trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+
+		body := strings.TrimRight(line, "\r\n")
+		if m := assign.FindStringSubmatch(body); m != nil {
+			lines[i] = m[1] + quoted + m[3] + line[len(body):]
+			return []byte(strings.Join(lines, "")), nil
+		}
+		last = i
+	}
+	if !found {
+		return nil, fmt.Errorf("no [%s] table", table)
+	}
+
+	if !strings.HasSuffix(lines[last], "\n") {
+		lines[last] += "\n"
+	}
+	insert := key + " = " + quoted + "\n"
+	lines = append(lines[:last+1], append([]string{insert}, lines[last+1:]...)...)
+	return []byte(strings.Join(lines, "")), nil
+}
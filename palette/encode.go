@@ -0,0 +1,133 @@
+package palette
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ansiNames labels color0..color15 in encoded palettes.
+var ansiNames = [16]string{
+	"black", "red", "green", "yellow", "blue", "magenta", "cyan", "white",
+	"bright black", "bright red", "bright green", "bright yellow",
+	"bright blue", "bright magenta", "bright cyan", "bright white",
+}
+
+// Encode renders cfg as a palette.toml in the warehouse's canonical layout:
+// [theme], the core [palette] keys, the ANSI 16 with name comments, then
+// [palette.ui], [palette.syntax], adapter overrides, and [references].
+// Empty values are omitted, so encoding a config before ApplyDefaults keeps
+// derived colors derived.
+func Encode(cfg Config) []byte {
+	var b strings.Builder
+
+	b.WriteString("[theme]\n")
+	writeKey(&b, "name", cfg.Theme.Name)
+	writeKey(&b, "author", cfg.Theme.Author)
+	writeKey(&b, "variant", cfg.Theme.Variant)
+	writeKey(&b, "extends", cfg.Theme.Extends)
+	writeKey(&b, "sibling", cfg.Theme.Sibling)
+
+	writePalette(&b, "palette", cfg.Palette, true)
+
+	names := make([]string, 0, len(cfg.Adapters))
+	for name := range cfg.Adapters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		ac := cfg.Adapters[name]
+		if ac.Replace {
+			fmt.Fprintf(&b, "\n[adapters.%s]\nreplace = true\n", name)
+		}
+		writePalette(&b, "adapters."+name+".palette", ac.Palette, false)
+	}
+
+	if len(cfg.References) > 0 {
+		b.WriteString("\n[references]\n")
+		keys := make([]string, 0, len(cfg.References))
+		for k := range cfg.References {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			writeKey(&b, k, cfg.References[k])
+		}
+	}
+
+	return []byte(b.String())
+}
+
+// writePalette writes p under table, with [table.ui] and [table.syntax]
+// subtables for any semantic keys that are set. The base table header is
+// written only when it has keys of its own.
+func writePalette(b *strings.Builder, table string, p PaletteColors, ansiComments bool) {
+	var core, ui, syntax []paletteField
+	for _, f := range paletteFields(&p) {
+		if f.value.String() == "" {
+			continue
+		}
+		switch {
+		case strings.HasPrefix(f.key, "ui."):
+			ui = append(ui, f)
+		case strings.HasPrefix(f.key, "syntax."):
+			syntax = append(syntax, f)
+		default:
+			core = append(core, f)
+		}
+	}
+
+	if len(core) > 0 {
+		fmt.Fprintf(b, "\n[%s]\n", table)
+		section := ""
+		for _, f := range core {
+			idx, isANSI := ansiIndex(f.key)
+			if !isANSI || !ansiComments {
+				writeKey(b, f.key, f.value.String())
+				continue
+			}
+			if s := ansiSection(idx); s != section {
+				section = s
+				fmt.Fprintf(b, "\n# ANSI 16 colors (%s)\n", s)
+			}
+			line := f.key + " = " + strconv.Quote(f.value.String())
+			fmt.Fprintf(b, "%-21s# %s\n", line, ansiNames[idx])
+		}
+	}
+
+	for _, sub := range []struct {
+		name   string
+		fields []paletteField
+	}{{"ui", ui}, {"syntax", syntax}} {
+		if len(sub.fields) == 0 {
+			continue
+		}
+		fmt.Fprintf(b, "\n[%s.%s]\n", table, sub.name)
+		for _, f := range sub.fields {
+			writeKey(b, strings.TrimPrefix(f.key, sub.name+"."), f.value.String())
+		}
+	}
+}
+
+// ansiIndex returns N for a "colorN" key.
+func ansiIndex(key string) (int, bool) {
+	n, err := strconv.Atoi(strings.TrimPrefix(key, "color"))
+	if !strings.HasPrefix(key, "color") || err != nil {
+		return 0, false
+	}
+	return n, true
+}
+
+func ansiSection(idx int) string {
+	if idx < 8 {
+		return "normal"
+	}
+	return "bright"
+}
+
+func writeKey(b *strings.Builder, key, value string) {
+	if value != "" {
+		fmt.Fprintf(b, "%s = %s\n", key, strconv.Quote(value))
+	}
+}
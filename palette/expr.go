@@ -0,0 +1,385 @@
+package palette
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/kylesnowschwartz/the-themer/palette/color"
+)
+
+// Palette values may be written as expressions instead of literal hex:
+//
+//	cursor_text = "$bg"                       # reference another key
+//	dimmed      = "mix($bg, $fg, 0.35)"        # OKLCH interpolation
+//	border      = "lighten($color8, 8%)"       # OKLCH lightness shift
+//	line_highlight = "alpha($color4, 15%)"     # translucent color (#RRGGBBAA)
+//
+// References use the key's path relative to [palette] ("$ui.accent",
+// "$syntax.number"). Amounts accept fractions (0.08) or percentages (8%).
+// Functions:
+//
+//	lighten(color, amount)  raise OKLCH lightness by amount
+//	darken(color, amount)   lower OKLCH lightness by amount
+//	mix(a, b, t)            interpolate a -> b in OKLCH (t=0 is a)
+//	alpha(color, opacity)   scale the color's opacity
+//
+// Translucent inputs keep their alpha through lighten and darken, and mix
+// interpolates it.
+// Expressions are resolved by ResolveExpressions before ApplyDefaults, so a
+// reference must point at a key that is set (explicitly or by extends).
+
+// paletteField is one color value in PaletteColors, addressed by its dotted
+// TOML key relative to [palette] (e.g. "bg", "ui.accent").
+type paletteField struct {
+	key   string
+	value reflect.Value // settable string
+}
+
+// paletteFields lists every color field of p in declaration order.
+func paletteFields(p *PaletteColors) []paletteField {
+	var fields []paletteField
+	walkFields(reflect.ValueOf(p).Elem(), "", &fields)
+	return fields
+}
+
+func walkFields(v reflect.Value, prefix string, out *[]paletteField) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		key := prefix + t.Field(i).Tag.Get("toml")
+		switch v.Field(i).Kind() {
+		case reflect.Struct:
+			walkFields(v.Field(i), key+".", out)
+		case reflect.String:
+			*out = append(*out, paletteField{key: key, value: v.Field(i)})
+		}
+	}
+}
+
+// isExpression reports whether a palette value needs evaluation rather than
+// being a literal color.
+func isExpression(s string) bool {
+	return strings.HasPrefix(strings.TrimSpace(s), "$") || strings.Contains(s, "(")
+}
+
+// ResolveExpressions evaluates every palette value written as a reference or
+// color function and replaces it with the resulting hex color. Literal
+// values are left untouched for Validate to check. Failures, including
+// reference cycles, are reported as ValidationErrors naming the key and the
+// expression that failed.
+func (c *Config) ResolveExpressions() error {
+	r := &resolver{
+		cfg:    c,
+		fields: map[string]reflect.Value{},
+		state:  map[string]resolveState{},
+	}
+	fields := paletteFields(&c.Palette)
+	for _, f := range fields {
+		r.fields[f.key] = f.value
+	}
+	for _, f := range fields {
+		r.resolve(f.key, nil)
+	}
+
+	if len(r.errs) > 0 {
+		return r.errs
+	}
+	return nil
+}
+
+type resolveState int
+
+const (
+	unresolved resolveState = iota
+	resolving
+	resolved
+	failed
+)
+
+type resolver struct {
+	cfg    *Config
+	fields map[string]reflect.Value
+	state  map[string]resolveState
+	errs   ValidationErrors
+}
+
+// errDependency marks an expression that failed only because a key it
+// references failed; that key reports its own error.
+type errDependency struct{ key string }
+
+func (e errDependency) Error() string { return fmt.Sprintf("$%s could not be resolved", e.key) }
+
+// resolve evaluates key (and, recursively, anything it references) and
+// returns its final value. stack holds the keys currently being evaluated.
+func (r *resolver) resolve(key string, stack []string) (string, error) {
+	v := r.fields[key]
+	switch r.state[key] {
+	case resolved:
+		return v.String(), nil
+	case failed:
+		return "", errDependency{key}
+	case resolving:
+		var path []string
+		for i, k := range stack {
+			if k == key {
+				for _, k := range stack[i:] {
+					path = append(path, "$"+k)
+				}
+				break
+			}
+		}
+		return "", fmt.Errorf("reference cycle: %s -> $%s", strings.Join(path, " -> "), key)
+	}
+
+	raw := v.String()
+	if !isExpression(raw) {
+		r.state[key] = resolved
+		return raw, nil
+	}
+
+	r.state[key] = resolving
+	stack = append(stack, key)
+
+	node, err := parseExpr(raw)
+	var out color.RGB
+	var alpha float64
+	if err == nil {
+		var val exprValue
+		val, err = r.eval(node, stack)
+		if err == nil && !val.isColor {
+			err = fmt.Errorf("expression evaluates to a number, not a color")
+		}
+		out, alpha = val.color, val.alpha
+	}
+
+	if err != nil {
+		r.state[key] = failed
+		if _, dep := err.(errDependency); !dep {
+			r.errs = append(r.errs, r.cfg.errorAt("palette."+key, "palette.%s has invalid expression %q: %v", key, raw, err))
+		}
+		return "", errDependency{key}
+	}
+
+	v.SetString(out.HexAlpha(alpha))
+	r.state[key] = resolved
+	return v.String(), nil
+}
+
+// exprValue is the result of evaluating an expression node.
+type exprValue struct {
+	isColor bool
+	color   color.RGB
+	alpha   float64 // opacity of color, 0-1
+	number  float64
+}
+
+func (r *resolver) eval(n exprNode, stack []string) (exprValue, error) {
+	switch n := n.(type) {
+	case refNode:
+		if _, ok := r.fields[n.key]; !ok {
+			return exprValue{}, fmt.Errorf("unknown reference $%s", n.key)
+		}
+		s, err := r.resolve(n.key, stack)
+		if err != nil {
+			return exprValue{}, err
+		}
+		if s == "" {
+			return exprValue{}, fmt.Errorf("reference $%s is not set", n.key)
+		}
+		c, a, err := color.ParseHexAlpha(s)
+		if err != nil {
+			return exprValue{}, fmt.Errorf("reference $%s: %w", n.key, err)
+		}
+		return exprValue{isColor: true, color: c, alpha: a}, nil
+
+	case hexNode:
+		c, a, err := color.Parse(n.hex)
+		if err != nil {
+			return exprValue{}, err
+		}
+		return exprValue{isColor: true, color: c, alpha: a}, nil
+
+	case numNode:
+		return exprValue{number: n.value}, nil
+
+	case callNode:
+		return r.call(n, stack)
+	}
+	return exprValue{}, fmt.Errorf("unexpected expression")
+}
+
+// signature lists a function's argument kinds: 'c' for color, 'n' for number.
+var signatures = map[string]string{
+	"lighten": "cn",
+	"darken":  "cn",
+	"mix":     "ccn",
+	"alpha":   "cn",
+}
+
+func (r *resolver) call(n callNode, stack []string) (exprValue, error) {
+	sig, ok := signatures[n.name]
+	if !ok {
+		return exprValue{}, fmt.Errorf("unknown function %s()", n.name)
+	}
+	if len(n.args) != len(sig) {
+		return exprValue{}, fmt.Errorf("%s() takes %d arguments, got %d", n.name, len(sig), len(n.args))
+	}
+
+	args := make([]exprValue, len(n.args))
+	for i, a := range n.args {
+		v, err := r.eval(a, stack)
+		if err != nil {
+			return exprValue{}, err
+		}
+		if (sig[i] == 'c') != v.isColor {
+			want := "a color"
+			if sig[i] == 'n' {
+				want = "a number"
+			}
+			return exprValue{}, fmt.Errorf("%s() argument %d must be %s", n.name, i+1, want)
+		}
+		args[i] = v
+	}
+
+	out := exprValue{isColor: true, alpha: args[0].alpha}
+	switch n.name {
+	case "lighten":
+		out.color = color.Lighten(args[0].color, args[1].number)
+	case "darken":
+		out.color = color.Darken(args[0].color, args[1].number)
+	case "mix":
+		t := args[2].number
+		out.color = color.Mix(args[0].color, args[1].color, t)
+		out.alpha = args[0].alpha + (args[1].alpha-args[0].alpha)*t
+	case "alpha":
+		if o := args[1].number; o < 0 || o > 1 {
+			return exprValue{}, fmt.Errorf("alpha() opacity must be between 0 and 1, got %g", o)
+		}
+		out.color = args[0].color
+		out.alpha = args[0].alpha * args[1].number
+	}
+	return out, nil
+}
+
+// Expression syntax tree.
+type (
+	exprNode interface{}
+	refNode  struct{ key string }
+	hexNode  struct{ hex string }
+	numNode  struct{ value float64 }
+	callNode struct {
+		name string
+		args []exprNode
+	}
+)
+
+// parseExpr parses a palette expression into a syntax tree.
+func parseExpr(s string) (exprNode, error) {
+	p := &exprParser{src: s}
+	n, err := p.expr()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpace()
+	if p.pos < len(p.src) {
+		return nil, fmt.Errorf("unexpected %q at offset %d", p.src[p.pos:], p.pos)
+	}
+	return n, nil
+}
+
+type exprParser struct {
+	src string
+	pos int
+}
+
+func (p *exprParser) skipSpace() {
+	for p.pos < len(p.src) && (p.src[p.pos] == ' ' || p.src[p.pos] == '\t') {
+		p.pos++
+	}
+}
+
+// take consumes the longest run of bytes satisfying ok.
+func (p *exprParser) take(ok func(byte) bool) string {
+	start := p.pos
+	for p.pos < len(p.src) && ok(p.src[p.pos]) {
+		p.pos++
+	}
+	return p.src[start:p.pos]
+}
+
+func isIdent(b byte) bool {
+	return b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z' || b >= '0' && b <= '9' || b == '_' || b == '.'
+}
+
+func isHexDigit(b byte) bool {
+	return b >= '0' && b <= '9' || b >= 'a' && b <= 'f' || b >= 'A' && b <= 'F'
+}
+
+func isNumber(b byte) bool {
+	return b >= '0' && b <= '9' || b == '.' || b == '-' || b == '+'
+}
+
+func (p *exprParser) expr() (exprNode, error) {
+	p.skipSpace()
+	if p.pos >= len(p.src) {
+		return nil, fmt.Errorf("unexpected end of expression")
+	}
+
+	switch b := p.src[p.pos]; {
+	case b == '$':
+		p.pos++
+		key := p.take(isIdent)
+		if key == "" {
+			return nil, fmt.Errorf("expected key name after $ at offset %d", p.pos)
+		}
+		return refNode{key: key}, nil
+
+	case b == '#':
+		p.pos++
+		return hexNode{hex: "#" + p.take(isHexDigit)}, nil
+
+	case isNumber(b):
+		lit := p.take(isNumber)
+		v, err := strconv.ParseFloat(lit, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q", lit)
+		}
+		if p.pos < len(p.src) && p.src[p.pos] == '%' {
+			p.pos++
+			v /= 100
+		}
+		return numNode{value: v}, nil
+
+	case isIdent(b):
+		name := p.take(isIdent)
+		p.skipSpace()
+		if p.pos >= len(p.src) || p.src[p.pos] != '(' {
+			return nil, fmt.Errorf("expected ( after %s", name)
+		}
+		p.pos++
+
+		var args []exprNode
+		for {
+			arg, err := p.expr()
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, arg)
+
+			p.skipSpace()
+			if p.pos >= len(p.src) {
+				return nil, fmt.Errorf("missing ) after %s arguments", name)
+			}
+			if p.src[p.pos] == ')' {
+				p.pos++
+				return callNode{name: name, args: args}, nil
+			}
+			if p.src[p.pos] != ',' {
+				return nil, fmt.Errorf("expected , or ) at offset %d", p.pos)
+			}
+			p.pos++
+		}
+	}
+	return nil, fmt.Errorf("unexpected %q at offset %d", p.src[p.pos:], p.pos)
+}
@@ -0,0 +1,22 @@
+//go:build ignore
+
+// gen_schema writes schema.json from the Config struct tree. Run it with
+// go generate ./palette after changing the palette types.
+package main
+
+import (
+	"log"
+	"os"
+
+	"github.com/kylesnowschwartz/the-themer/palette"
+)
+
+func main() {
+	out, err := palette.GenerateSchema(".")
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile("schema.json", out, 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
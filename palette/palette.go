@@ -5,70 +5,148 @@ package palette
 import (
 	"fmt"
 	"os"
+	"path/filepath"
+	"reflect"
 	"regexp"
+	"slices"
 	"strings"
 
 	"github.com/BurntSushi/toml"
+
+	"github.com/kylesnowschwartz/the-themer/palette/color"
 )
 
-// hexPattern validates a 6-digit hex color string like "#050a14".
-var hexPattern = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+// hexPattern validates a hex color string: 6 digits like "#050a14", or 8
+// digits like "#050a1480" where the last pair is alpha.
+var hexPattern = regexp.MustCompile(`^#[0-9a-fA-F]{6}([0-9a-fA-F]{2})?$`)
+
+// requiredPaletteKeys are the [palette] keys every theme must set, directly
+// or through extends.
+var requiredPaletteKeys = []string{
+	"bg", "fg",
+	"color0", "color1", "color2", "color3", "color4", "color5", "color6", "color7",
+	"color8", "color9", "color10", "color11", "color12", "color13", "color14", "color15",
+}
+
+// variants are the accepted values of [theme] variant.
+var variants = []string{"dark", "light"}
 
 // Theme holds theme metadata from the [theme] TOML section.
 type Theme struct {
-	Name    string `toml:"name"`
-	Author  string `toml:"author"`
+	Name    string `toml:"name"`    // theme name, matching its warehouse directory
+	Author  string `toml:"author"`  // who made the palette
 	Variant string `toml:"variant"` // "dark" or "light"
+
+	// Extends names a parent theme in the same warehouse. The parent's
+	// palette.toml is loaded first and this file's keys are overlaid on it.
+	Extends string `toml:"extends"`
+
+	// Sibling names the theme's counterpart in the other variant (the light
+	// version of a dark theme, or vice versa). Unlike other keys it is not
+	// inherited through extends.
+	Sibling string `toml:"sibling"`
 }
 
 // UI holds semantic color overrides from the [palette.ui] TOML section.
-// When omitted, these are derived from ANSI palette colors via ApplyDefaults.
+// When omitted, these are derived via ApplyDefaults: the state colors from
+// ANSI palette colors, the surfaces by mixing bg toward fg in OKLCH, and
+// the tints by stepping bg toward a state color's lightness and chroma
+// in its hue.
 type UI struct {
-	Border  string `toml:"border"`
-	Dimmed  string `toml:"dimmed"`
-	Accent  string `toml:"accent"`
-	Success string `toml:"success"`
-	Warning string `toml:"warning"`
-	Error   string `toml:"error"`
-	Info    string `toml:"info"`
+	Border  Color `toml:"border"`  // separators and frames; defaults to color8
+	Dimmed  Color `toml:"dimmed"`  // secondary text; defaults to color8
+	Accent  Color `toml:"accent"`  // highlights and matches; defaults to color6
+	Success Color `toml:"success"` // defaults to color2
+	Warning Color `toml:"warning"` // defaults to color3
+	Error   Color `toml:"error"`   // defaults to color1
+	Info    Color `toml:"info"`    // defaults to color4
+
+	Surface0 Color `toml:"surface0"` // raised panels, current line; defaults to bg mixed 12% toward fg
+	Surface1 Color `toml:"surface1"` // panels above surface0; defaults to bg mixed 22% toward fg
+	Surface2 Color `toml:"surface2"` // panels above surface1; defaults to bg mixed 32% toward fg
+	Overlay  Color `toml:"overlay"`  // popups and scrollbars over surfaces; defaults to bg mixed 45% toward fg
+
+	DiffAddBG    Color `toml:"diff_add_bg"`    // added-line background; defaults to bg tinted 20% toward success
+	DiffDelBG    Color `toml:"diff_del_bg"`    // removed-line background; defaults to bg tinted 20% toward error
+	DiffChangeBG Color `toml:"diff_change_bg"` // changed-line background; defaults to bg tinted 20% toward info
+
+	Link        Color `toml:"link"`         // hyperlinks; defaults to accent
+	Hint        Color `toml:"hint"`         // hints and placeholder text; defaults to bg mixed 65% toward fg
+	SearchMatch Color `toml:"search_match"` // search match background; defaults to bg tinted 35% toward warning
+
+	BorderActive   Color `toml:"border_active"`   // focused pane border; defaults to accent
+	BorderInactive Color `toml:"border_inactive"` // unfocused pane border; defaults to border
 }
 
-// Syntax holds colors for syntax highlighting used by bat (.tmTheme) and
-// potentially neovim. These are distinct from UI colors because syntax
-// highlighters need different shades than app chrome -- e.g., a muted blue
-// for numeric literals vs. the bright accent for keywords.
-// When omitted, these are derived from ANSI palette colors via ApplyDefaults.
+// Steps from bg toward another color, in OKLCH, for the UI roles that
+// ApplyDefaults derives by mixing.
+const (
+	surface0Step = 0.12
+	surface1Step = 0.22
+	surface2Step = 0.32
+	overlayStep  = 0.45
+	hintStep     = 0.65
+	diffBGStep   = 0.20
+	searchStep   = 0.35
+)
+
+// Syntax holds the semantic roles of syntax highlighting, used by bat
+// (.tmTheme) and editor adapters. These are distinct from UI colors because
+// syntax highlighters need different shades than app chrome -- e.g., a muted
+// blue for numeric literals vs. the bright accent for keywords.
+// When omitted, these are derived from ANSI palette and UI colors via
+// ApplyDefaults; some roles default to another role (constant to number,
+// regexp to string), so setting one recolors both.
 type Syntax struct {
-	Number        string `toml:"number"`         // numeric literals and constants
-	Error         string `toml:"error"`          // invalid/error tokens (distinct from ui.error)
-	LineHighlight string `toml:"line_highlight"` // current line background tint
+	Number        Color `toml:"number"`         // numeric literals; defaults to color4
+	Error         Color `toml:"error"`          // invalid/error tokens (distinct from ui.error); defaults to color1
+	LineHighlight Color `toml:"line_highlight"` // current line background tint; defaults to selection_bg
+
+	Keyword   Color `toml:"keyword"`   // keywords, storage, imports, builtins; defaults to ui.accent
+	String    Color `toml:"string"`    // string literals and inline code; defaults to color5
+	Function  Color `toml:"function"`  // function names; defaults to color15 (dark) or color0 (light)
+	Type      Color `toml:"type"`      // types, classes, namespaces; defaults to color4
+	Constant  Color `toml:"constant"`  // language and user constants; defaults to number
+	Comment   Color `toml:"comment"`   // comments, quotes, preprocessor; defaults to ui.dimmed
+	Operator  Color `toml:"operator"`  // operators, punctuation, escapes; defaults to color4
+	Tag       Color `toml:"tag"`       // markup tags and property names; defaults to color4
+	Attribute Color `toml:"attribute"` // attributes and decorators; defaults to ui.accent
+	Variable  Color `toml:"variable"`  // defaults to fg
+	Parameter Color `toml:"parameter"` // defaults to fg
+	Regexp    Color `toml:"regexp"`    // regular expressions; defaults to string
+	Heading   Color `toml:"heading"`   // markup headings and bold; defaults to function
+	Link      Color `toml:"link"`      // markup links; defaults to ui.link
+
+	DiffAdded   Color `toml:"diff_added"`   // defaults to color2
+	DiffRemoved Color `toml:"diff_removed"` // defaults to error
+	DiffChanged Color `toml:"diff_changed"` // defaults to ui.accent
 }
 
 // PaletteColors holds the full color palette from the [palette] TOML section.
 type PaletteColors struct {
-	BG          string `toml:"bg"`
-	FG          string `toml:"fg"`
-	Cursor      string `toml:"cursor"`
-	CursorText  string `toml:"cursor_text"` // text color drawn over a block cursor; defaults to FG (preserves legacy behavior; set to BG for inverted/standard)
-	SelectionBG string `toml:"selection_bg"`
-	SelectionFG string `toml:"selection_fg"`
-
-	Color0  string `toml:"color0"`
-	Color1  string `toml:"color1"`
-	Color2  string `toml:"color2"`
-	Color3  string `toml:"color3"`
-	Color4  string `toml:"color4"`
-	Color5  string `toml:"color5"`
-	Color6  string `toml:"color6"`
-	Color7  string `toml:"color7"`
-	Color8  string `toml:"color8"`
-	Color9  string `toml:"color9"`
-	Color10 string `toml:"color10"`
-	Color11 string `toml:"color11"`
-	Color12 string `toml:"color12"`
-	Color13 string `toml:"color13"`
-	Color14 string `toml:"color14"`
-	Color15 string `toml:"color15"`
+	BG          Color `toml:"bg"`           // background; must be opaque
+	FG          Color `toml:"fg"`           // default text
+	Cursor      Color `toml:"cursor"`       // defaults to color4
+	CursorText  Color `toml:"cursor_text"`  // text color drawn over a block cursor; defaults to FG (preserves legacy behavior; set to BG for inverted/standard)
+	SelectionBG Color `toml:"selection_bg"` // defaults to color8
+	SelectionFG Color `toml:"selection_fg"` // defaults to FG
+
+	Color0  Color `toml:"color0"`
+	Color1  Color `toml:"color1"`
+	Color2  Color `toml:"color2"`
+	Color3  Color `toml:"color3"`
+	Color4  Color `toml:"color4"`
+	Color5  Color `toml:"color5"`
+	Color6  Color `toml:"color6"`
+	Color7  Color `toml:"color7"`
+	Color8  Color `toml:"color8"`
+	Color9  Color `toml:"color9"`
+	Color10 Color `toml:"color10"`
+	Color11 Color `toml:"color11"`
+	Color12 Color `toml:"color12"`
+	Color13 Color `toml:"color13"`
+	Color14 Color `toml:"color14"`
+	Color15 Color `toml:"color15"`
 
 	UI     UI     `toml:"ui"`
 	Syntax Syntax `toml:"syntax"`
@@ -76,8 +154,8 @@ type PaletteColors struct {
 
 // Colors returns the 16 ANSI colors in index order (color0..color15).
 // This enables clean iteration in templates.
-func (p PaletteColors) Colors() []string {
-	return []string{
+func (p PaletteColors) Colors() []Color {
+	return []Color{
 		p.Color0, p.Color1, p.Color2, p.Color3,
 		p.Color4, p.Color5, p.Color6, p.Color7,
 		p.Color8, p.Color9, p.Color10, p.Color11,
@@ -85,10 +163,21 @@ func (p PaletteColors) Colors() []string {
 	}
 }
 
+// Walk calls fn for every color field, in declaration order, with its
+// dotted key relative to [palette] (e.g. "bg", "ui.accent") and a pointer
+// that fn may write through.
+func (p *PaletteColors) Walk(fn func(key string, value *Color)) {
+	for _, f := range paletteFields(p) {
+		fn(f.key, f.value.Addr().Interface().(*Color))
+	}
+}
+
 // AdapterConfig holds per-adapter palette overrides from [adapters.<name>].
-// When present, the override palette completely replaces the base palette
-// for that adapter -- no merging, no cascading.
+// Only the keys set in the override win; everything else cascades from the
+// base palette. Set replace = true to make the override palette stand alone
+// instead, with defaults derived from it and nothing inherited.
 type AdapterConfig struct {
+	Replace bool          `toml:"replace"` // stand alone instead of overlaying the base palette
 	Palette PaletteColors `toml:"palette"`
 }
 
@@ -98,56 +187,300 @@ type Config struct {
 	Theme      Theme                    `toml:"theme"`
 	Palette    PaletteColors            `toml:"palette"`
 	Adapters   map[string]AdapterConfig `toml:"adapters"`
-	References map[string]string        `toml:"references"`
+	References map[string]string        `toml:"references"` // app -> that app's built-in theme to use (e.g. bat = "Nord")
 
 	// RawPalette holds a snapshot of the user-supplied palette taken before
-	// ApplyDefaults populates any missing fields. Adapters whose token mapping
+	// ApplyDefaults populates any missing fields. For themes using extends,
+	// it covers keys set anywhere in the inheritance chain. Adapters whose token mapping
 	// has its own fallback chain (different from the global ApplyDefaults rules)
 	// can use this to detect whether a field was explicitly set vs. defaulted.
 	// The tcm adapter uses it for the "blue" token, where the spec
 	// fallback is color4 instead of UI.Accent's standard color6 default.
 	RawPalette PaletteColors `toml:"-"`
+
+	// Positions maps dotted keys ("palette.bg", and table names like
+	// "palette.ui") to where they are written, for error reporting. The ""
+	// entry names the file. With extends, each key points at the file in the
+	// chain that set it.
+	Positions map[string]Position `toml:"-"`
+
+	defined map[string]bool  // dotted keys present in the TOML, across the extends chain
+	unknown ValidationErrors // keys that matched no field; see UnknownKeys
 }
 
 // ValidationErrors collects multiple validation failures.
-type ValidationErrors []string
+type ValidationErrors []ValidationError
 
 // Error joins all validation errors into a newline-separated string.
 func (ve ValidationErrors) Error() string {
-	return strings.Join([]string(ve), "\n")
+	msgs := make([]string, len(ve))
+	for i, e := range ve {
+		msgs[i] = e.Error()
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// LoadOpts configures palette loading.
+type LoadOpts struct {
+	// Lenient ignores keys that match no palette field instead of reporting
+	// them, as palettes were read before unknown keys were checked.
+	Lenient bool
 }
 
 // Load reads a TOML palette file, parses it, applies defaults, and validates.
-// This is the primary entry point for palette loading.
+// If the palette declares [theme] extends, the parent chain is resolved
+// first (see LoadRaw), then color expressions are evaluated (see
+// ResolveExpressions). Unknown keys, usually typos, are reported alongside
+// validation errors. This is the primary entry point for palette loading.
 func Load(path string) (Config, error) {
+	return LoadWith(path, LoadOpts{})
+}
+
+// LoadWith is Load with options.
+func LoadWith(path string, opts LoadOpts) (Config, error) {
+	cfg, err := LoadRaw(path)
+	if err != nil {
+		return Config{}, err
+	}
+
+	var errs ValidationErrors
+	if !opts.Lenient {
+		errs = cfg.UnknownKeys()
+	}
+
+	if err := cfg.ResolveExpressions(); err != nil {
+		return Config{}, append(errs, err.(ValidationErrors)...)
+	}
+
+	cfg.ApplyDefaults()
+
+	if err := cfg.Validate(); err != nil {
+		errs = append(errs, err.(ValidationErrors)...)
+	}
+	if len(errs) > 0 {
+		return Config{}, errs
+	}
+
+	return cfg, nil
+}
+
+// UnknownKeys returns an error for every key in the parsed file (or its
+// extends chain) that matched no palette field, with a "did you mean"
+// suggestion when a known key is a close spelling.
+func (c *Config) UnknownKeys() ValidationErrors {
+	return c.unknown
+}
+
+// LoadRaw reads a TOML palette file and resolves its extends chain without
+// applying defaults or validating. Parents are looked up as sibling theme
+// directories in the same warehouse: a palette at themes/child/palette.toml
+// that extends "parent" inherits from themes/parent/palette.toml. Keys set
+// in the child win; everything else comes from the parent.
+func LoadRaw(path string) (Config, error) {
+	return loadChain(path, nil)
+}
+
+// ParentPath returns the palette.toml path for the theme that the palette
+// at path extends.
+func ParentPath(path, parent string) string {
+	warehouse := filepath.Dir(filepath.Dir(path))
+	return filepath.Join(warehouse, parent, "palette.toml")
+}
+
+// loadChain loads path and its ancestors. chain holds the absolute paths
+// already visited on the way down, for cycle detection.
+func loadChain(path string, chain []string) (Config, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return Config{}, fmt.Errorf("resolving palette path: %w", err)
+	}
+	for i, p := range chain {
+		if p == abs {
+			var names []string
+			for _, c := range append(chain[i:], abs) {
+				names = append(names, themeDirName(c))
+			}
+			return Config{}, fmt.Errorf("extends cycle: %s", strings.Join(names, " -> "))
+		}
+	}
+
 	data, err := os.ReadFile(path)
 	if err != nil {
 		return Config{}, fmt.Errorf("reading palette file: %w", err)
 	}
 
-	cfg, err := Parse(data)
+	cfg, err := parse(data, path)
 	if err != nil {
 		return Config{}, err
 	}
+	if cfg.Theme.Extends == "" {
+		return cfg, nil
+	}
 
-	cfg.ApplyDefaults()
+	parent, err := loadChain(ParentPath(abs, cfg.Theme.Extends), append(chain, abs))
+	if err != nil {
+		return Config{}, fmt.Errorf("theme %q extends %q: %w", themeDirName(abs), cfg.Theme.Extends, err)
+	}
+	return overlayConfig(parent, cfg), nil
+}
 
-	if err := cfg.Validate(); err != nil {
-		return Config{}, err
+// themeDirName returns the theme directory name for a palette.toml path.
+func themeDirName(path string) string {
+	return filepath.Base(filepath.Dir(path))
+}
+
+// overlayConfig returns base with every key explicitly set in child laid on
+// top. Maps (adapters, references) merge per key.
+func overlayConfig(base, child Config) Config {
+	out := base
+	out.Positions = make(map[string]Position, len(base.Positions)+len(child.Positions))
+	for k, p := range base.Positions {
+		out.Positions[k] = p
 	}
+	for k, p := range child.Positions {
+		out.Positions[k] = p
+	}
+	out.defined = make(map[string]bool, len(base.defined)+len(child.defined))
+	for k := range base.defined {
+		out.defined[k] = true
+	}
+	for k := range child.defined {
+		out.defined[k] = true
+	}
+	out.unknown = append(append(ValidationErrors{}, base.unknown...), child.unknown...)
+
+	// Name and sibling belong to one theme. A child that leaves out name
+	// must fail validation, not generate under its parent's name.
+	overlayStrings(&out.Theme, child.Theme)
+	out.Theme.Name = child.Theme.Name
+	out.Theme.Sibling = child.Theme.Sibling
+	for _, key := range []string{"theme.name", "theme.sibling"} {
+		if _, ok := child.Positions[key]; !ok {
+			delete(out.Positions, key)
+		}
+	}
+	overlayStrings(&out.Palette, child.Palette)
 
-	return cfg, nil
+	if len(child.Adapters) > 0 {
+		adapters := make(map[string]AdapterConfig, len(base.Adapters)+len(child.Adapters))
+		for name, a := range base.Adapters {
+			adapters[name] = a
+		}
+		for name, a := range child.Adapters {
+			merged := adapters[name]
+			overlayStrings(&merged, a)
+			// A bool's zero value is a setting too: replace = false in
+			// the child turns off a parent's replace = true.
+			if child.defined["adapters."+name+".replace"] {
+				merged.Replace = a.Replace
+			}
+			adapters[name] = merged
+		}
+		out.Adapters = adapters
+	}
+
+	if len(child.References) > 0 {
+		refs := make(map[string]string, len(base.References)+len(child.References))
+		for k, v := range base.References {
+			refs[k] = v
+		}
+		for k, v := range child.References {
+			refs[k] = v
+		}
+		out.References = refs
+	}
+	return out
 }
 
-// Parse decodes TOML bytes into a Config struct.
+// overlayStrings copies every non-empty string field of src onto the
+// struct pointed to by dst, recursing into nested structs. dst and src must
+// be the same struct type.
+func overlayStrings(dst, src any) {
+	overlayValue(reflect.ValueOf(dst).Elem(), reflect.ValueOf(src))
+}
+
+func overlayValue(dst, src reflect.Value) {
+	switch src.Kind() {
+	case reflect.Struct:
+		for i := 0; i < src.NumField(); i++ {
+			overlayValue(dst.Field(i), src.Field(i))
+		}
+	case reflect.String:
+		if src.String() != "" {
+			dst.Set(src)
+		}
+	}
+}
+
+// Parse decodes TOML bytes into a Config struct. Keys that match no field
+// are ignored here and recorded for UnknownKeys.
 func Parse(data []byte) (Config, error) {
+	return parse(data, "")
+}
+
+// parse is Parse for data read from file, which positions are reported in.
+func parse(data []byte, file string) (Config, error) {
 	var cfg Config
-	if err := toml.Unmarshal(data, &cfg); err != nil {
+	md, err := toml.Decode(string(data), &cfg)
+	if err != nil {
+		if file != "" {
+			return Config{}, fmt.Errorf("parsing TOML: %s: %w", file, err)
+		}
 		return Config{}, fmt.Errorf("parsing TOML: %w", err)
 	}
+	cfg.Positions = keyPositions(data, file)
+	cfg.defined = map[string]bool{}
+	for _, key := range md.Keys() {
+		cfg.defined[strings.Join(key, ".")] = true
+	}
+	cfg.unknown = cfg.unknownKeys(md)
 	return cfg, nil
 }
 
+// ForAdapter returns the config the named adapter should render from. Keys
+// set in [adapters.<name>.palette] are laid over the user-supplied base
+// palette (RawPalette) and defaults are re-derived from the result, so a
+// changed color4 also moves a defaulted cursor. Theme, References, and
+// Adapters carry over unchanged. Without an override, c is returned as is.
+//
+// c is expected to come from Load (or to have had ApplyDefaults called).
+func (c Config) ForAdapter(name string) (Config, error) {
+	override, ok := c.Adapters[name]
+	if !ok {
+		return c, nil
+	}
+
+	out := c
+	switch {
+	case override.Replace:
+		out.Palette = PaletteColors{}
+	case c.RawPalette != (PaletteColors{}):
+		out.Palette = c.RawPalette
+	}
+	overlayStrings(&out.Palette, override.Palette)
+
+	// Point errors in overridden keys at the override, not the base palette.
+	prefix := "adapters." + name + "."
+	out.Positions = make(map[string]Position, len(c.Positions))
+	for k, p := range c.Positions {
+		out.Positions[k] = p
+	}
+	for k, p := range c.Positions {
+		if rest, ok := strings.CutPrefix(k, prefix); ok && strings.HasPrefix(rest, "palette") {
+			out.Positions[rest] = p
+		}
+	}
+
+	if err := out.ResolveExpressions(); err != nil {
+		return Config{}, fmt.Errorf("adapter %s override: %w", name, err)
+	}
+	out.ApplyDefaults()
+	if err := out.Validate(); err != nil {
+		return Config{}, fmt.Errorf("adapter %s override: %w", name, err)
+	}
+	return out, nil
+}
+
 // ApplyDefaults fills zero-value optional fields with values derived from
 // the ANSI palette. It does not overwrite explicitly set values.
 func (c *Config) ApplyDefaults() {
@@ -194,6 +527,44 @@ func (c *Config) ApplyDefaults() {
 		p.UI.Info = p.Color4
 	}
 
+	// UI surfaces and tints mixed from bg, and roles following the above
+	if p.UI.Surface0 == "" {
+		p.UI.Surface0 = p.mixBG(p.FG, surface0Step)
+	}
+	if p.UI.Surface1 == "" {
+		p.UI.Surface1 = p.mixBG(p.FG, surface1Step)
+	}
+	if p.UI.Surface2 == "" {
+		p.UI.Surface2 = p.mixBG(p.FG, surface2Step)
+	}
+	if p.UI.Overlay == "" {
+		p.UI.Overlay = p.mixBG(p.FG, overlayStep)
+	}
+	if p.UI.DiffAddBG == "" {
+		p.UI.DiffAddBG = p.tintBG(p.UI.Success, diffBGStep)
+	}
+	if p.UI.DiffDelBG == "" {
+		p.UI.DiffDelBG = p.tintBG(p.UI.Error, diffBGStep)
+	}
+	if p.UI.DiffChangeBG == "" {
+		p.UI.DiffChangeBG = p.tintBG(p.UI.Info, diffBGStep)
+	}
+	if p.UI.Link == "" {
+		p.UI.Link = p.UI.Accent
+	}
+	if p.UI.Hint == "" {
+		p.UI.Hint = p.mixBG(p.FG, hintStep)
+	}
+	if p.UI.SearchMatch == "" {
+		p.UI.SearchMatch = p.tintBG(p.UI.Warning, searchStep)
+	}
+	if p.UI.BorderActive == "" {
+		p.UI.BorderActive = p.UI.Accent
+	}
+	if p.UI.BorderInactive == "" {
+		p.UI.BorderInactive = p.UI.Border
+	}
+
 	// Syntax defaults derived from ANSI colors
 	if p.Syntax.Number == "" {
 		p.Syntax.Number = p.Color4
@@ -204,6 +575,126 @@ func (c *Config) ApplyDefaults() {
 	if p.Syntax.LineHighlight == "" {
 		p.Syntax.LineHighlight = p.SelectionBG
 	}
+
+	// Syntax role defaults, matching bat's long-standing scope colors. Some
+	// follow the UI colors and syntax roles set above.
+	emphasis := p.Color15
+	if c.Theme.Variant == "light" {
+		emphasis = p.Color0
+	}
+	if p.Syntax.Keyword == "" {
+		p.Syntax.Keyword = p.UI.Accent
+	}
+	if p.Syntax.String == "" {
+		p.Syntax.String = p.Color5
+	}
+	if p.Syntax.Function == "" {
+		p.Syntax.Function = emphasis
+	}
+	if p.Syntax.Type == "" {
+		p.Syntax.Type = p.Color4
+	}
+	if p.Syntax.Constant == "" {
+		p.Syntax.Constant = p.Syntax.Number
+	}
+	if p.Syntax.Comment == "" {
+		p.Syntax.Comment = p.UI.Dimmed
+	}
+	if p.Syntax.Operator == "" {
+		p.Syntax.Operator = p.Color4
+	}
+	if p.Syntax.Tag == "" {
+		p.Syntax.Tag = p.Color4
+	}
+	if p.Syntax.Attribute == "" {
+		p.Syntax.Attribute = p.UI.Accent
+	}
+	if p.Syntax.Variable == "" {
+		p.Syntax.Variable = p.FG
+	}
+	if p.Syntax.Parameter == "" {
+		p.Syntax.Parameter = p.FG
+	}
+	if p.Syntax.Regexp == "" {
+		p.Syntax.Regexp = p.Syntax.String
+	}
+	if p.Syntax.Heading == "" {
+		p.Syntax.Heading = p.Syntax.Function
+	}
+	if p.Syntax.Link == "" {
+		p.Syntax.Link = p.UI.Link
+	}
+	if p.Syntax.DiffAdded == "" {
+		p.Syntax.DiffAdded = p.Color2
+	}
+	if p.Syntax.DiffRemoved == "" {
+		p.Syntax.DiffRemoved = p.Syntax.Error
+	}
+	if p.Syntax.DiffChanged == "" {
+		p.Syntax.DiffChanged = p.UI.Accent
+	}
+}
+
+// mixBG mixes bg toward c by t in OKLCH, compositing a translucent c over
+// bg first. It returns "" when either isn't valid hex, leaving the role
+// unset for Validate to report the source.
+func (p *PaletteColors) mixBG(c Color, t float64) Color {
+	bg, rgb, ok := p.overBG(c)
+	if !ok {
+		return ""
+	}
+	return Color(color.Mix(bg, rgb, t).Hex())
+}
+
+// tintBG is mixBG for tints toward a state color: lightness and chroma
+// move by t, but the hue is c's throughout, so even a faint tint reads as
+// c rather than as bg.
+func (p *PaletteColors) tintBG(c Color, t float64) Color {
+	bg, rgb, ok := p.overBG(c)
+	if !ok {
+		return ""
+	}
+	from, to := bg.OKLCH(), rgb.OKLCH()
+	return Color(color.OKLCH{
+		L: from.L + (to.L-from.L)*t,
+		C: from.C + (to.C-from.C)*t,
+		H: to.H,
+	}.RGB().Hex())
+}
+
+// overBG parses bg and c, compositing a translucent c over bg.
+func (p *PaletteColors) overBG(c Color) (bg, rgb color.RGB, ok bool) {
+	bg, err := color.ParseHex(string(p.BG))
+	if err != nil {
+		return bg, rgb, false
+	}
+	rgb, alpha, err := color.ParseHexAlpha(string(c))
+	if err != nil {
+		return bg, rgb, false
+	}
+	return bg, color.Composite(rgb, alpha, bg), true
+}
+
+// HasAlpha reports whether a palette color carries an alpha channel
+// ("#RRGGBBAA").
+func HasAlpha(hex string) bool {
+	return len(hex) == 9 && hexPattern.MatchString(hex)
+}
+
+// Opaque returns a copy of c with every translucent color in Palette and
+// RawPalette composited over bg, for consumers whose output formats (or
+// whose math) only handle opaque colors. Adapters whose format can carry
+// alpha render from c directly. c must be valid.
+func (c Config) Opaque() Config {
+	bg := c.Palette.BG.RGB()
+	flatten := func(_ string, v *Color) {
+		if v.HasAlpha() {
+			*v = Color(color.Composite(v.RGB(), v.Alpha(), bg).Hex())
+		}
+	}
+	c.Palette.Walk(flatten)
+	c.RawPalette.Walk(flatten)
+	return c
 }
 
 // Validate checks that all required fields are present and all hex colors
@@ -213,71 +704,40 @@ func (c *Config) Validate() error {
 	var errs ValidationErrors
 
 	if c.Theme.Name == "" {
-		errs = append(errs, "theme.name is required but not set")
+		errs = append(errs, c.errorAt("theme.name", "theme.name is required but not set"))
+	}
+	if v := c.Theme.Variant; v != "" && !slices.Contains(variants, v) {
+		errs = append(errs, c.errorAt("theme.variant", "theme.variant must be %s, got %q", strings.Join(variants, " or "), v))
 	}
 
+	values := map[string]Color{}
+	c.Palette.Walk(func(key string, value *Color) { values[key] = *value })
+
 	// Required hex fields in a stable order for deterministic error output.
-	requiredHex := []struct {
-		name  string
-		value string
-	}{
-		{"palette.bg", c.Palette.BG},
-		{"palette.fg", c.Palette.FG},
-		{"palette.color0", c.Palette.Color0},
-		{"palette.color1", c.Palette.Color1},
-		{"palette.color2", c.Palette.Color2},
-		{"palette.color3", c.Palette.Color3},
-		{"palette.color4", c.Palette.Color4},
-		{"palette.color5", c.Palette.Color5},
-		{"palette.color6", c.Palette.Color6},
-		{"palette.color7", c.Palette.Color7},
-		{"palette.color8", c.Palette.Color8},
-		{"palette.color9", c.Palette.Color9},
-		{"palette.color10", c.Palette.Color10},
-		{"palette.color11", c.Palette.Color11},
-		{"palette.color12", c.Palette.Color12},
-		{"palette.color13", c.Palette.Color13},
-		{"palette.color14", c.Palette.Color14},
-		{"palette.color15", c.Palette.Color15},
-	}
-
-	for _, f := range requiredHex {
-		if f.value == "" {
-			errs = append(errs, fmt.Sprintf("%s is required but not set", f.name))
-		} else if !hexPattern.MatchString(f.value) {
-			errs = append(errs, fmt.Sprintf("%s has invalid hex format: %q (expected #RRGGBB)", f.name, f.value))
+	for _, key := range requiredPaletteKeys {
+		name, value := "palette."+key, values[key]
+		if value == "" {
+			errs = append(errs, c.errorAt(name, "%s is required but not set", name))
+		} else if !hexPattern.MatchString(string(value)) {
+			errs = append(errs, c.errorAt(name, "%s has invalid hex format: %q (expected #RRGGBB or #RRGGBBAA)", name, value))
 		}
 	}
 
-	// Optional hex fields: validate format only if present.
-	// These have already been filled by ApplyDefaults if the caller used Load,
-	// but Validate can be called independently.
-	optionalHex := []struct {
-		name  string
-		value string
-	}{
-		{"palette.cursor", c.Palette.Cursor},
-		{"palette.cursor_text", c.Palette.CursorText},
-		{"palette.selection_bg", c.Palette.SelectionBG},
-		{"palette.selection_fg", c.Palette.SelectionFG},
-		{"palette.ui.border", c.Palette.UI.Border},
-		{"palette.ui.dimmed", c.Palette.UI.Dimmed},
-		{"palette.ui.accent", c.Palette.UI.Accent},
-		{"palette.ui.success", c.Palette.UI.Success},
-		{"palette.ui.warning", c.Palette.UI.Warning},
-		{"palette.ui.error", c.Palette.UI.Error},
-		{"palette.ui.info", c.Palette.UI.Info},
-		{"palette.syntax.number", c.Palette.Syntax.Number},
-		{"palette.syntax.error", c.Palette.Syntax.Error},
-		{"palette.syntax.line_highlight", c.Palette.Syntax.LineHighlight},
-	}
-
-	for _, f := range optionalHex {
-		if f.value != "" && !hexPattern.MatchString(f.value) {
-			errs = append(errs, fmt.Sprintf("%s has invalid hex format: %q (expected #RRGGBB)", f.name, f.value))
-		}
+	// Translucent colors are composited over bg, so bg itself must be opaque.
+	if c.Palette.BG.HasAlpha() {
+		errs = append(errs, c.errorAt("palette.bg", "palette.bg must be opaque: %q (other colors are composited over it)", c.Palette.BG))
 	}
 
+	// Optional hex fields, in declaration order: validate format only if
+	// present. These have already been filled by ApplyDefaults if the
+	// caller used Load, but Validate can be called independently.
+	c.Palette.Walk(func(key string, value *Color) {
+		name := "palette." + key
+		if !slices.Contains(requiredPaletteKeys, key) && *value != "" && !hexPattern.MatchString(string(*value)) {
+			errs = append(errs, c.errorAt(name, "%s has invalid hex format: %q (expected #RRGGBB or #RRGGBBAA)", name, *value))
+		}
+	})
+
 	if len(errs) > 0 {
 		return errs
 	}
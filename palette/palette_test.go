@@ -1,11 +1,19 @@
 package palette_test
 
 import (
+	"encoding/json"
 	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
 	"strings"
 	"testing"
+	"text/template"
 
 	"github.com/kylesnowschwartz/the-themer/palette"
+	"github.com/kylesnowschwartz/the-themer/palette/color"
 )
 
 func TestParseValidTOML(t *testing.T) {
@@ -104,6 +112,64 @@ color15 = "#ffffff"
 	assertEqual(t, "ui.warning", cfg.Palette.UI.Warning, "#aaaa00") // color3
 	assertEqual(t, "ui.error", cfg.Palette.UI.Error, "#aa0000")     // color1
 	assertEqual(t, "ui.info", cfg.Palette.UI.Info, "#0000aa")       // color4
+
+	// Syntax roles from ANSI, UI, and other roles
+	assertEqual(t, "syntax.keyword", cfg.Palette.Syntax.Keyword, "#00aaaa")   // ui.accent
+	assertEqual(t, "syntax.string", cfg.Palette.Syntax.String, "#aa00aa")     // color5
+	assertEqual(t, "syntax.function", cfg.Palette.Syntax.Function, "#ffffff") // color15 (dark)
+	assertEqual(t, "syntax.comment", cfg.Palette.Syntax.Comment, "#555555")   // ui.dimmed
+	assertEqual(t, "syntax.constant", cfg.Palette.Syntax.Constant, "#0000aa") // number
+	assertEqual(t, "syntax.regexp", cfg.Palette.Syntax.Regexp, "#aa00aa")     // string
+	assertEqual(t, "syntax.heading", cfg.Palette.Syntax.Heading, "#ffffff")   // function
+	assertEqual(t, "syntax.diff_removed", cfg.Palette.Syntax.DiffRemoved, "#aa0000")
+
+	light, err := palette.Parse([]byte(strings.Replace(tomlStr, `name = "minimal"`, "name = \"minimal\"\nvariant = \"light\"", 1)))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	light.ApplyDefaults()
+	assertEqual(t, "light syntax.function", light.Palette.Syntax.Function, "#000000") // color0
+}
+
+// TestDefaults_UISurfaces checks the UI roles derived by mixing: surfaces
+// step evenly up from bg, and tints keep their state color's hue.
+func TestDefaults_UISurfaces(t *testing.T) {
+	cfg, err := palette.Load("../testdata/bleu.toml")
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	ui := cfg.Palette.UI
+
+	prev := cfg.Palette.BG.OKLCH().L
+	for _, c := range []palette.Color{ui.Surface0, ui.Surface1, ui.Surface2, ui.Overlay, ui.Hint, cfg.Palette.FG} {
+		l := c.OKLCH().L
+		if l <= prev {
+			t.Errorf("%s (L %.3f) is not lighter than the tier below (L %.3f)", c, l, prev)
+		}
+		prev = l
+	}
+
+	for _, tc := range []struct {
+		name       string
+		tint, base palette.Color
+	}{
+		{"diff_add_bg", ui.DiffAddBG, ui.Success},
+		{"diff_del_bg", ui.DiffDelBG, ui.Error},
+		{"diff_change_bg", ui.DiffChangeBG, ui.Info},
+		{"search_match", ui.SearchMatch, ui.Warning},
+	} {
+		if d := color.HueDistance(tc.tint.OKLCH().H, tc.base.OKLCH().H); d > 10 {
+			t.Errorf("%s %s hue is %.0f° from %s", tc.name, tc.tint, d, tc.base)
+		}
+		if tc.tint.OKLCH().L >= tc.base.OKLCH().L {
+			t.Errorf("%s %s is not a background tint of %s", tc.name, tc.tint, tc.base)
+		}
+	}
+
+	assertEqual(t, "ui.link", ui.Link, ui.Accent)
+	assertEqual(t, "ui.border_active", ui.BorderActive, ui.Accent)
+	assertEqual(t, "ui.border_inactive", ui.BorderInactive, ui.Border)
+	assertEqual(t, "syntax.link", cfg.Palette.Syntax.Link, ui.Link)
 }
 
 func TestDefaults_DoNotOverrideExplicit(t *testing.T) {
@@ -151,6 +217,9 @@ error = "#fedcba"
 
 	// Other UI fields still get defaults
 	assertEqual(t, "ui.success", cfg.Palette.UI.Success, "#00aa00") // color2
+
+	// Roles defaulted from an explicit value follow it
+	assertEqual(t, "syntax.keyword", cfg.Palette.Syntax.Keyword, "#abcdef") // ui.accent
 }
 
 func TestValidation_MissingRequired(t *testing.T) {
@@ -223,8 +292,9 @@ func TestValidation_InvalidHex(t *testing.T) {
 		wantMsg string
 	}{
 		{"no hash prefix", "050a14", "invalid hex format"},
-		{"short hex", "#fff", "invalid hex format"},
-		{"too long", "#050a14ff", "invalid hex format"},
+		{"short hex", "#ffff", "invalid hex format"},
+		{"too long", "#050a14fff", "invalid hex format"},
+		{"translucent bg", "#050a1480", "must be opaque"},
 		{"non-hex chars", "#gggggg", "invalid hex format"},
 		{"empty hash", "#", "invalid hex format"},
 	}
@@ -276,6 +346,43 @@ color15 = "#ffffff"
 	}
 }
 
+func TestValidation_Variant(t *testing.T) {
+	tomlStr := `
+[theme]
+name = "test"
+variant = "dim"
+
+[palette]
+bg = "#111111"
+fg = "#eeeeee"
+color0 = "#000000"
+color1 = "#aa0000"
+color2 = "#00aa00"
+color3 = "#aaaa00"
+color4 = "#0000aa"
+color5 = "#aa00aa"
+color6 = "#00aaaa"
+color7 = "#aaaaaa"
+color8 = "#555555"
+color9 = "#ff0000"
+color10 = "#00ff00"
+color11 = "#ffff00"
+color12 = "#0000ff"
+color13 = "#ff00ff"
+color14 = "#00ffff"
+color15 = "#ffffff"
+`
+	cfg, err := palette.Parse([]byte(tomlStr))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	cfg.ApplyDefaults()
+	err = cfg.Validate()
+	if err == nil || !strings.Contains(err.Error(), `theme.variant must be dark or light, got "dim"`) {
+		t.Errorf("got error %v, want one rejecting variant \"dim\"", err)
+	}
+}
+
 func TestValidation_CollectsAllErrors(t *testing.T) {
 	// Missing color2, color5, and invalid hex for bg -- three errors total.
 	tomlStr := `
@@ -393,7 +500,7 @@ func TestColors(t *testing.T) {
 	}
 
 	// Verify order matches individual fields
-	want := []string{
+	want := []palette.Color{
 		cfg.Palette.Color0, cfg.Palette.Color1, cfg.Palette.Color2, cfg.Palette.Color3,
 		cfg.Palette.Color4, cfg.Palette.Color5, cfg.Palette.Color6, cfg.Palette.Color7,
 		cfg.Palette.Color8, cfg.Palette.Color9, cfg.Palette.Color10, cfg.Palette.Color11,
@@ -471,21 +578,725 @@ color15 = "#eeeeee"
 	assertEqual(t, "ghostty color0", ghostty.Palette.Color0, "#111111")
 	assertEqual(t, "ghostty color15", ghostty.Palette.Color15, "#eeeeee")
 
-	// Override palette can go through defaults + validate
-	overrideCfg := palette.Config{
-		Theme:   cfg.Theme,
-		Palette: ghostty.Palette,
-	}
-	overrideCfg.ApplyDefaults()
-	if err := overrideCfg.Validate(); err != nil {
+	// Override palette goes through defaults + validate
+	cfg.ApplyDefaults()
+	overrideCfg, err := cfg.ForAdapter("ghostty")
+	if err != nil {
 		t.Fatalf("override validation failed: %v", err)
 	}
 	// Defaults applied to override palette
 	assertEqual(t, "override cursor", overrideCfg.Palette.Cursor, "#0000bb") // color4
 }
 
+func TestForAdapter_Layered(t *testing.T) {
+	tomlStr := `
+[theme]
+name = "test"
+variant = "dark"
+
+[palette]
+bg = "#111111"
+fg = "#eeeeee"
+selection_fg = "#dddddd"
+color0 = "#000000"
+color1 = "#aa0000"
+color2 = "#00aa00"
+color3 = "#aaaa00"
+color4 = "#0000aa"
+color5 = "#aa00aa"
+color6 = "#00aaaa"
+color7 = "#aaaaaa"
+color8 = "#555555"
+color9 = "#ff0000"
+color10 = "#00ff00"
+color11 = "#ffff00"
+color12 = "#0000ff"
+color13 = "#ff00ff"
+color14 = "#00ffff"
+color15 = "#ffffff"
+
+[palette.ui]
+accent = "#abcdef"
+
+[references]
+bat = "Nord"
+
+[adapters.fzf.palette]
+fg = "#f0f0f0"
+color4 = "#3344ff"
+
+[adapters.fzf.palette.ui]
+border = "#222222"
+`
+
+	cfg, err := palette.Parse([]byte(tomlStr))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	cfg.ApplyDefaults()
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate failed: %v", err)
+	}
+
+	fzf, err := cfg.ForAdapter("fzf")
+	if err != nil {
+		t.Fatalf("ForAdapter failed: %v", err)
+	}
+
+	// Override keys win.
+	assertEqual(t, "fg", fzf.Palette.FG, "#f0f0f0")
+	assertEqual(t, "color4", fzf.Palette.Color4, "#3344ff")
+	assertEqual(t, "ui.border", fzf.Palette.UI.Border, "#222222")
+
+	// Explicit base keys cascade.
+	assertEqual(t, "bg", fzf.Palette.BG, "#111111")
+	assertEqual(t, "selection_fg", fzf.Palette.SelectionFG, "#dddddd")
+	assertEqual(t, "ui.accent", fzf.Palette.UI.Accent, "#abcdef")
+
+	// Defaults re-derive from the merged palette.
+	assertEqual(t, "cursor", fzf.Palette.Cursor, "#3344ff")   // override color4
+	assertEqual(t, "ui.info", fzf.Palette.UI.Info, "#3344ff") // override color4
+	assertEqual(t, "raw ui.accent", fzf.RawPalette.UI.Accent, "#abcdef")
+
+	// Theme and references carry over.
+	assertEqual(t, "theme.name", fzf.Theme.Name, "test")
+	assertEqual(t, "references.bat", fzf.References["bat"], "Nord")
+
+	// Base config is untouched, and adapters without overrides get it as is.
+	assertEqual(t, "base fg", cfg.Palette.FG, "#eeeeee")
+	ghostty, err := cfg.ForAdapter("ghostty")
+	if err != nil {
+		t.Fatalf("ForAdapter(ghostty) failed: %v", err)
+	}
+	assertEqual(t, "ghostty fg", ghostty.Palette.FG, "#eeeeee")
+}
+
+func TestForAdapter_Replace(t *testing.T) {
+	tomlStr := `
+[theme]
+name = "test"
+
+[palette]
+bg = "#111111"
+fg = "#eeeeee"
+color0 = "#000000"
+color1 = "#aa0000"
+color2 = "#00aa00"
+color3 = "#aaaa00"
+color4 = "#0000aa"
+color5 = "#aa00aa"
+color6 = "#00aaaa"
+color7 = "#aaaaaa"
+color8 = "#555555"
+color9 = "#ff0000"
+color10 = "#00ff00"
+color11 = "#ffff00"
+color12 = "#0000ff"
+color13 = "#ff00ff"
+color14 = "#00ffff"
+color15 = "#ffffff"
+
+[palette.ui]
+accent = "#abcdef"
+
+[adapters.partial]
+replace = true
+
+[adapters.partial.palette]
+fg = "#f0f0f0"
+`
+
+	cfg, err := palette.Parse([]byte(tomlStr))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	cfg.ApplyDefaults()
+
+	// A replacing override must stand alone, so a partial one fails validation.
+	_, err = cfg.ForAdapter("partial")
+	if err == nil {
+		t.Fatal("expected validation error for partial replace override, got nil")
+	}
+	if !strings.Contains(err.Error(), "adapter partial override") || !strings.Contains(err.Error(), "palette.bg") {
+		t.Errorf("error %q does not name the adapter and missing field", err.Error())
+	}
+}
+
+// parentTOML is a complete palette used as the base of extends tests.
+const parentTOML = `
+[theme]
+name = "parent"
+author = "someone"
+variant = "dark"
+
+[palette]
+bg = "#111111"
+fg = "#eeeeee"
+color0 = "#000000"
+color1 = "#aa0000"
+color2 = "#00aa00"
+color3 = "#aaaa00"
+color4 = "#0000aa"
+color5 = "#aa00aa"
+color6 = "#00aaaa"
+color7 = "#aaaaaa"
+color8 = "#555555"
+color9 = "#ff0000"
+color10 = "#00ff00"
+color11 = "#ffff00"
+color12 = "#0000ff"
+color13 = "#ff00ff"
+color14 = "#00ffff"
+color15 = "#ffffff"
+
+[palette.ui]
+accent = "#abcdef"
+
+[references]
+neovim = "parent-scheme"
+bat = "Nord"
+`
+
+// writeWarehouse creates a themes directory with one palette.toml per entry
+// and returns the warehouse path.
+func writeWarehouse(t *testing.T, themes map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range themes {
+		themeDir := filepath.Join(dir, name)
+		if err := os.MkdirAll(themeDir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(themeDir, "palette.toml"), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestLoad_Extends(t *testing.T) {
+	dir := writeWarehouse(t, map[string]string{
+		"parent": parentTOML,
+		"child": `
+[theme]
+name = "child"
+extends = "parent"
+
+[palette]
+bg = "#222222"
+color4 = "#3344ff"
+
+[references]
+bat = "Dracula"
+`,
+	})
+
+	cfg, err := palette.Load(filepath.Join(dir, "child", "palette.toml"))
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	// Child keys win.
+	assertEqual(t, "theme.name", cfg.Theme.Name, "child")
+	assertEqual(t, "bg", cfg.Palette.BG, "#222222")
+	assertEqual(t, "color4", cfg.Palette.Color4, "#3344ff")
+	assertEqual(t, "references.bat", cfg.References["bat"], "Dracula")
+
+	// Everything else cascades from the parent.
+	assertEqual(t, "theme.author", cfg.Theme.Author, "someone")
+	assertEqual(t, "theme.variant", cfg.Theme.Variant, "dark")
+	assertEqual(t, "fg", cfg.Palette.FG, "#eeeeee")
+	assertEqual(t, "ui.accent", cfg.Palette.UI.Accent, "#abcdef")
+	assertEqual(t, "references.neovim", cfg.References["neovim"], "parent-scheme")
+
+	// Defaults derive from the merged palette.
+	assertEqual(t, "cursor", cfg.Palette.Cursor, "#3344ff") // child's color4
+
+	// RawPalette reflects explicit keys from anywhere in the chain.
+	assertEqual(t, "raw ui.accent", cfg.RawPalette.UI.Accent, "#abcdef")
+	assertEqual(t, "raw bg", cfg.RawPalette.BG, "#222222")
+	assertEqual(t, "raw cursor", cfg.RawPalette.Cursor, "")
+}
+
+func TestLoad_SiblingNotInherited(t *testing.T) {
+	dir := writeWarehouse(t, map[string]string{
+		"parent": strings.Replace(parentTOML, `variant = "dark"`, "variant = \"dark\"\nsibling = \"parent-light\"", 1),
+		"child": `
+[theme]
+name = "child"
+extends = "parent"
+`,
+	})
+
+	parent, err := palette.Load(filepath.Join(dir, "parent", "palette.toml"))
+	if err != nil {
+		t.Fatalf("Load parent failed: %v", err)
+	}
+	assertEqual(t, "parent sibling", parent.Theme.Sibling, "parent-light")
+
+	child, err := palette.Load(filepath.Join(dir, "child", "palette.toml"))
+	if err != nil {
+		t.Fatalf("Load child failed: %v", err)
+	}
+	assertEqual(t, "child sibling", child.Theme.Sibling, "")
+}
+
+func TestLoad_ExtendsReplaceFalse(t *testing.T) {
+	dir := writeWarehouse(t, map[string]string{
+		"parent": parentTOML + "\n[adapters.fzf]\nreplace = true\n\n[adapters.fzf.palette]\nfg = \"#f0f0f0\"\n",
+		"child":  "[theme]\nname = \"child\"\nextends = \"parent\"\n\n[adapters.fzf]\nreplace = false\n",
+	})
+
+	cfg, err := palette.Load(filepath.Join(dir, "child", "palette.toml"))
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if cfg.Adapters["fzf"].Replace {
+		t.Error("child's replace = false did not override the parent's replace = true")
+	}
+	fzf, err := cfg.ForAdapter("fzf")
+	if err != nil {
+		t.Fatalf("ForAdapter: %v", err)
+	}
+	assertEqual(t, "fzf fg", fzf.Palette.FG, "#f0f0f0") // parent's override still applies
+	assertEqual(t, "fzf bg", fzf.Palette.BG, "#111111") // overlaid on the base palette
+}
+
+func TestLoad_NameNotInherited(t *testing.T) {
+	dir := writeWarehouse(t, map[string]string{
+		"parent": parentTOML,
+		"child":  "[theme]\nextends = \"parent\"\n",
+	})
+
+	_, err := palette.Load(filepath.Join(dir, "child", "palette.toml"))
+	if err == nil {
+		t.Fatal("expected error for child without theme.name, got nil")
+	}
+	if !strings.Contains(err.Error(), "theme.name is required") {
+		t.Errorf("error %q does not report the missing name", err.Error())
+	}
+	if !strings.Contains(err.Error(), filepath.Join("child", "palette.toml")) {
+		t.Errorf("error %q does not point at the child", err.Error())
+	}
+}
+
+func TestLoad_ExtendsMultiLevel(t *testing.T) {
+	dir := writeWarehouse(t, map[string]string{
+		"parent": parentTOML,
+		"child": `
+[theme]
+name = "child"
+extends = "parent"
+
+[palette]
+fg = "#dddddd"
+`,
+		"grandchild": `
+[theme]
+name = "grandchild"
+extends = "child"
+
+[palette.ui]
+error = "#ff1111"
+`,
+	})
+
+	cfg, err := palette.Load(filepath.Join(dir, "grandchild", "palette.toml"))
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	assertEqual(t, "theme.name", cfg.Theme.Name, "grandchild")
+	assertEqual(t, "fg", cfg.Palette.FG, "#dddddd")
+	assertEqual(t, "bg", cfg.Palette.BG, "#111111")
+	assertEqual(t, "ui.accent", cfg.Palette.UI.Accent, "#abcdef")
+	assertEqual(t, "ui.error", cfg.Palette.UI.Error, "#ff1111")
+}
+
+func TestLoad_ExtendsCycle(t *testing.T) {
+	dir := writeWarehouse(t, map[string]string{
+		"a": "[theme]\nname = \"a\"\nextends = \"b\"\n",
+		"b": "[theme]\nname = \"b\"\nextends = \"a\"\n",
+	})
+
+	_, err := palette.Load(filepath.Join(dir, "a", "palette.toml"))
+	if err == nil {
+		t.Fatal("expected cycle error, got nil")
+	}
+	if !strings.Contains(err.Error(), "extends cycle: a -> b -> a") {
+		t.Errorf("error %q does not describe the cycle", err.Error())
+	}
+}
+
+func TestLoad_ExtendsMissingParent(t *testing.T) {
+	dir := writeWarehouse(t, map[string]string{
+		"child": "[theme]\nname = \"child\"\nextends = \"nowhere\"\n",
+	})
+
+	_, err := palette.Load(filepath.Join(dir, "child", "palette.toml"))
+	if err == nil {
+		t.Fatal("expected error for missing parent, got nil")
+	}
+	if !strings.Contains(err.Error(), `theme "child" extends "nowhere"`) {
+		t.Errorf("error %q does not name the broken link", err.Error())
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("error %q does not wrap the underlying not-exist error", err.Error())
+	}
+}
+
+func TestLoad_Expressions(t *testing.T) {
+	dir := writeWarehouse(t, map[string]string{
+		"exprs": strings.Replace(parentTOML, "[palette.ui]", `cursor_text = "$bg"
+selection_bg = "mix($bg, $fg, 0.5)"
+
+[palette.ui]
+dimmed = "$color8"
+border = "lighten($ui.dimmed, 10%)"
+info = "darken(#fff, 0.1)"
+`, 1),
+	})
+
+	cfg, err := palette.Load(filepath.Join(dir, "exprs", "palette.toml"))
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	assertEqual(t, "cursor_text", cfg.Palette.CursorText, "#111111")
+	assertEqual(t, "selection_bg", cfg.Palette.SelectionBG, palette.Color(color.Mix(color.MustParseHex("#111111"), color.MustParseHex("#eeeeee"), 0.5).Hex()))
+	assertEqual(t, "ui.dimmed", cfg.Palette.UI.Dimmed, "#555555")
+	assertEqual(t, "ui.border", cfg.Palette.UI.Border, palette.Color(color.Lighten(color.MustParseHex("#555555"), 0.1).Hex()))
+	assertEqual(t, "ui.info", cfg.Palette.UI.Info, palette.Color(color.Darken(color.MustParseHex("#ffffff"), 0.1).Hex()))
+}
+
+func TestLoad_ExpressionsResolveAcrossExtends(t *testing.T) {
+	dir := writeWarehouse(t, map[string]string{
+		"parent": strings.Replace(parentTOML, "[palette.ui]", "[palette.ui]\nborder = \"alpha($fg, 50%)\"", 1),
+		"child":  "[theme]\nname = \"child\"\nextends = \"parent\"\n\n[palette]\nbg = \"#000000\"\nfg = \"#ffffff\"\n",
+	})
+
+	cfg, err := palette.Load(filepath.Join(dir, "child", "palette.toml"))
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	// The parent's expression sees the child's colors.
+	assertEqual(t, "ui.border", cfg.Palette.UI.Border, "#ffffff80")
+}
+
+func TestLoad_Alpha(t *testing.T) {
+	dir := writeWarehouse(t, map[string]string{
+		"alpha": strings.Replace(parentTOML, "[palette.ui]", `selection_bg = "#3344ff80"
+
+[palette.syntax]
+line_highlight = "alpha($selection_bg, 50%)"
+number = "lighten(#ff000080, 0)"
+
+[palette.ui]
+`, 1),
+	})
+
+	cfg, err := palette.Load(filepath.Join(dir, "alpha", "palette.toml"))
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	assertEqual(t, "selection_bg", cfg.Palette.SelectionBG, "#3344ff80")
+	assertEqual(t, "syntax.line_highlight", cfg.Palette.Syntax.LineHighlight, "#3344ff40")
+	assertEqual(t, "syntax.number", cfg.Palette.Syntax.Number, "#ff000080")
+
+	opaque := cfg.Opaque()
+	assertEqual(t, "opaque selection_bg", opaque.Palette.SelectionBG, "#222b88")
+	assertEqual(t, "opaque raw selection_bg", opaque.RawPalette.SelectionBG, "#222b88")
+	assertEqual(t, "opaque fg", opaque.Palette.FG, "#eeeeee")
+	// The receiver is untouched.
+	assertEqual(t, "selection_bg after Opaque", cfg.Palette.SelectionBG, "#3344ff80")
+}
+
+func TestLoad_UnknownKeys(t *testing.T) {
+	content := strings.Replace(parentTOML, `fg = "#eeeeee"`, "fg = \"#eeeeee\"\nselection_gb = \"#333333\"", 1)
+	content = strings.Replace(content, "[palette.ui]", "[palette.iu]", 1)
+	content += "\n[adapters.fzf]\n  replce = true\n"
+	dir := writeWarehouse(t, map[string]string{"typos": content})
+	path := filepath.Join(dir, "typos", "palette.toml")
+
+	_, err := palette.Load(path)
+	var ve palette.ValidationErrors
+	if !errors.As(err, &ve) {
+		t.Fatalf("error = %v, want ValidationErrors", err)
+	}
+	want := []string{
+		path + `:10:1: unknown key palette.selection_gb (did you mean "selection_bg"?)`,
+		path + `:28:1: unknown table palette.iu (did you mean "ui"?)`,
+		path + `:36:3: unknown key adapters.fzf.replce (did you mean "replace"?)`,
+	}
+	if len(ve) != len(want) {
+		t.Fatalf("got %d errors, want %d:\n%v", len(ve), len(want), err)
+	}
+	for i, w := range want {
+		if ve[i].Error() != w {
+			t.Errorf("error %d = %q, want %q", i, ve[i].Error(), w)
+		}
+	}
+	assertEqual(t, "key", ve[0].Key, "palette.selection_gb")
+	if ve[0].Pos != (palette.Position{File: path, Line: 10, Col: 1}) {
+		t.Errorf("position = %+v", ve[0].Pos)
+	}
+
+	cfg, err := palette.LoadWith(path, palette.LoadOpts{Lenient: true})
+	if err != nil {
+		t.Fatalf("lenient Load: %v", err)
+	}
+	if n := len(cfg.UnknownKeys()); n != 3 {
+		t.Errorf("UnknownKeys() = %d entries, want 3", n)
+	}
+}
+
+func TestLoad_ErrorPositions(t *testing.T) {
+	dir := writeWarehouse(t, map[string]string{
+		"parent":   strings.Replace(parentTOML, `color3 = "#aaaa00"`, `color3 = "#aaaa0"`, 1),
+		"child":    "[theme]\nname = \"child\"\nextends = \"parent\"\n\n[palette]\ncursor = \"$nope\"\n",
+		"nameless": strings.Replace(parentTOML, `name = "parent"`, "", 1),
+	})
+
+	_, err := palette.Load(filepath.Join(dir, "child", "palette.toml"))
+	if err == nil || !strings.Contains(err.Error(), filepath.Join(dir, "child", "palette.toml")+":6:1: palette.cursor") {
+		t.Errorf("expression error not located in the child: %v", err)
+	}
+
+	// Fix the expression so validation runs; the bad hex is in the parent.
+	dir2 := writeWarehouse(t, map[string]string{
+		"parent": strings.Replace(parentTOML, `color3 = "#aaaa00"`, `color3 = "#aaaa0"`, 1),
+		"child":  "[theme]\nname = \"child\"\nextends = \"parent\"\n",
+	})
+	_, err = palette.Load(filepath.Join(dir2, "child", "palette.toml"))
+	if err == nil || !strings.Contains(err.Error(), filepath.Join(dir2, "parent", "palette.toml")+":13:1: palette.color3 has invalid hex format") {
+		t.Errorf("hex error not located in the parent: %v", err)
+	}
+
+	// A missing key points at its table.
+	_, err = palette.Load(filepath.Join(dir, "nameless", "palette.toml"))
+	if err == nil || !strings.Contains(err.Error(), filepath.Join(dir, "nameless", "palette.toml")+":2:1: theme.name is required") {
+		t.Errorf("missing key not located at its table: %v", err)
+	}
+}
+
+func TestForAdapter_ErrorPositions(t *testing.T) {
+	dir := writeWarehouse(t, map[string]string{
+		"over": parentTOML + "\n[adapters.fzf.palette]\nbg = \"#12345\"\n",
+	})
+	path := filepath.Join(dir, "over", "palette.toml")
+	cfg, err := palette.LoadRaw(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.ApplyDefaults()
+
+	_, err = cfg.ForAdapter("fzf")
+	if err == nil || !strings.Contains(err.Error(), path+":35:1: palette.bg has invalid hex format") {
+		t.Errorf("override error not located at the override: %v", err)
+	}
+}
+
+func TestLoad_ExpressionErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		extra string
+		want  string
+	}{
+		{"unknown reference", `cursor = "lighten($colr4, 8%)"`,
+			`palette.cursor has invalid expression "lighten($colr4, 8%)": unknown reference $colr4`},
+		{"unset reference", `cursor = "$cursor_text"`,
+			`palette.cursor has invalid expression "$cursor_text": reference $cursor_text is not set`},
+		{"unknown function", `cursor = "brighten($bg, 8%)"`,
+			`palette.cursor has invalid expression "brighten($bg, 8%)": unknown function brighten()`},
+		{"wrong arity", `cursor = "mix($bg, $fg)"`,
+			`mix() takes 3 arguments, got 2`},
+		{"syntax", `cursor = "lighten($bg, 8%"`,
+			`missing ) after lighten arguments`},
+		{"cycle", "cursor = \"$cursor_text\"\ncursor_text = \"mix($cursor, $bg, 0.5)\"",
+			`reference cycle: $cursor -> $cursor_text -> $cursor`},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := writeWarehouse(t, map[string]string{
+				"bad": strings.Replace(parentTOML, "[palette.ui]", tc.extra+"\n\n[palette.ui]", 1),
+			})
+			_, err := palette.Load(filepath.Join(dir, "bad", "palette.toml"))
+			if err == nil {
+				t.Fatal("expected error, got nil")
+			}
+			var ve palette.ValidationErrors
+			if !errors.As(err, &ve) || len(ve) != 1 {
+				t.Fatalf("error = %#v, want a single ValidationErrors entry", err)
+			}
+			if !strings.Contains(ve[0].Msg, tc.want) {
+				t.Errorf("error %q does not contain %q", ve[0].Msg, tc.want)
+			}
+		})
+	}
+}
+
+func TestEncode_RoundTrip(t *testing.T) {
+	data, err := os.ReadFile("../testdata/bleu.toml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := palette.Parse(data)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	got, err := palette.Parse(palette.Encode(want))
+	if err != nil {
+		t.Fatalf("Parse(Encode): %v", err)
+	}
+	got.Positions, want.Positions = nil, nil // layout differs by design
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("round trip changed config\n got: %+v\nwant: %+v", got, want)
+	}
+}
+
+func TestEncode_Layout(t *testing.T) {
+	cfg := palette.Config{
+		Theme: palette.Theme{Name: "demo", Variant: "dark"},
+		Palette: palette.PaletteColors{
+			BG: "#000000", FG: "#ffffff",
+			Color0: "#000000", Color7: "#cccccc", Color8: "#555555", Color10: "#00ff00",
+			Syntax: palette.Syntax{Number: "$color4"},
+		},
+		References: map[string]string{"neovim": "demo", "bat": "Nord"},
+	}
+
+	want := `[theme]
+name = "demo"
+variant = "dark"
+
+[palette]
+bg = "#000000"
+fg = "#ffffff"
+
+# ANSI 16 colors (normal)
+color0 = "#000000"   # black
+color7 = "#cccccc"   # white
+
+# ANSI 16 colors (bright)
+color8 = "#555555"   # bright black
+color10 = "#00ff00"  # bright green
+
+[palette.syntax]
+number = "$color4"
+
+[references]
+bat = "Nord"
+neovim = "demo"
+`
+	if got := string(palette.Encode(cfg)); got != want {
+		t.Errorf("Encode =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestSetKey(t *testing.T) {
+	src := `# header comment
+[theme]
+name = "demo"   # display name
+variant = "dark"
+
+# palette follows
+[palette]
+bg = "#000000"
+`
+
+	t.Run("replace keeps comment", func(t *testing.T) {
+		got, err := palette.SetKey([]byte(src), "theme", "name", "renamed")
+		if err != nil {
+			t.Fatalf("SetKey: %v", err)
+		}
+		want := strings.Replace(src, `name = "demo"   # display name`, `name = "renamed"   # display name`, 1)
+		if string(got) != want {
+			t.Errorf("got\n%s\nwant\n%s", got, want)
+		}
+	})
+
+	t.Run("insert after last assignment", func(t *testing.T) {
+		got, err := palette.SetKey([]byte(src), "theme", "sibling", "demo-light")
+		if err != nil {
+			t.Fatalf("SetKey: %v", err)
+		}
+		want := strings.Replace(src, "variant = \"dark\"\n", "variant = \"dark\"\nsibling = \"demo-light\"\n", 1)
+		if string(got) != want {
+			t.Errorf("got\n%s\nwant\n%s", got, want)
+		}
+	})
+
+	t.Run("missing table", func(t *testing.T) {
+		if _, err := palette.SetKey([]byte(src), "references", "bat", "Nord"); err == nil {
+			t.Error("expected error for missing table")
+		}
+	})
+}
+
+func TestColor_Notations(t *testing.T) {
+	tomlStr := strings.Replace(parentTOML, "[palette.ui]", `cursor = "#f80"
+cursor_text = "rgb(255 136 0)"
+selection_bg = "rgba(255, 136, 0, 50%)"
+selection_fg = "hsl(32, 100%, 50%)"
+
+[palette.syntax]
+number = "oklch(0.7 0.15 50 / 0.5)"
+error = "#A167A5"
+line_highlight = "mix($bg, $fg, 0.1)"
+
+[palette.ui]
+`, 1)
+	cfg, err := palette.Parse([]byte(tomlStr))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	p := cfg.Palette
+	assertEqual(t, "#rgb", p.Cursor, "#ff8800")
+	assertEqual(t, "rgb()", p.CursorText, "#ff8800")
+	assertEqual(t, "rgba()", p.SelectionBG, "#ff880080")
+	assertEqual(t, "hsl()", p.SelectionFG, "#ff8800")
+	assertEqual(t, "oklch()", p.Syntax.Number,
+		palette.Color(color.OKLCH{L: 0.7, C: 0.15, H: 50}.RGB().HexAlpha(0.5)))
+	// Long hex keeps its spelling; expressions are left for ResolveExpressions.
+	assertEqual(t, "long hex", p.Syntax.Error, "#A167A5")
+	assertEqual(t, "expression", p.Syntax.LineHighlight, "mix($bg, $fg, 0.1)")
+
+	if _, err := palette.Parse([]byte("[palette]\nbg = \"rgb(1, 2)\"\n")); err == nil {
+		t.Error("expected error for malformed rgb()")
+	}
+}
+
+func TestColor_Accessors(t *testing.T) {
+	c := palette.Color("#FF880080")
+	if got := c.RGB(); got != (color.RGB{R: 0xff, G: 0x88, B: 0x00}) {
+		t.Errorf("RGB = %+v", got)
+	}
+	if got := c.Alpha(); got != 128.0/255 {
+		t.Errorf("Alpha = %v", got)
+	}
+	assertEqual(t, "Hex", c.Hex(), "#ff8800")
+	assertEqual(t, "Bare", c.Bare(), "ff8800")
+	if got := c.Floats(); got != [3]float64{1, 0x88 / 255.0, 0} {
+		t.Errorf("Floats = %v", got)
+	}
+	if h := c.HSL(); math.Round(h.H) != 32 || h.S != 1 || h.L != 0.5 {
+		t.Errorf("HSL = %+v", h)
+	}
+	if o := c.OKLCH(); o != c.RGB().OKLCH() {
+		t.Errorf("OKLCH = %+v", o)
+	}
+
+	// Accessors are usable from adapter templates.
+	tmpl := template.Must(template.New("t").Parse(`{{.BG.Bare}} {{.BG.RGB.R}} {{printf "%.2f" (index .BG.Floats 1)}}`))
+	var b strings.Builder
+	if err := tmpl.Execute(&b, palette.PaletteColors{BG: "#0a1B2c"}); err != nil {
+		t.Fatalf("template: %v", err)
+	}
+	assertEqual(t, "template", b.String(), "0a1b2c 10 0.11")
+}
+
 // assertEqual is a test helper that reports field mismatches.
-func assertEqual(t *testing.T, field, got, want string) {
+func assertEqual[T ~string](t *testing.T, field string, got, want T) {
 	t.Helper()
 	if got != want {
 		t.Errorf("%s: got %q, want %q", field, got, want)
@@ -532,3 +1343,48 @@ func removeTOMLField(toml, field string) string {
 
 	return strings.Join(result, "\n")
 }
+
+// TestJSONSchema_InSync fails when the palette types change without
+// regenerating schema.json.
+func TestJSONSchema_InSync(t *testing.T) {
+	want, err := palette.GenerateSchema(".")
+	if err != nil {
+		t.Fatalf("GenerateSchema: %v", err)
+	}
+	if string(palette.JSONSchema()) != string(want) {
+		t.Error("schema.json is out of date with the palette types; run go generate ./palette")
+	}
+}
+
+func TestJSONSchema_ColorPattern(t *testing.T) {
+	var schema struct {
+		Properties map[string]struct {
+			Properties map[string]struct {
+				Pattern string `json:"pattern"`
+			} `json:"properties"`
+		} `json:"properties"`
+	}
+	if err := json.Unmarshal(palette.JSONSchema(), &schema); err != nil {
+		t.Fatalf("schema.json: %v", err)
+	}
+	pattern := regexp.MustCompile(schema.Properties["palette"].Properties["bg"].Pattern)
+
+	paths, _ := filepath.Glob("../themes/*/palette.toml")
+	for _, path := range append(paths, "../testdata/bleu.toml") {
+		cfg, err := palette.LoadRaw(path)
+		if err != nil {
+			t.Fatalf("LoadRaw(%s): %v", path, err)
+		}
+		cfg.Palette.Walk(func(key string, value *palette.Color) {
+			if *value != "" && !pattern.MatchString(string(*value)) {
+				t.Errorf("%s: palette.%s = %q rejected by the schema", path, key, *value)
+			}
+		})
+	}
+
+	for _, bad := range []string{"#12345", "red", "#gggggg", "1a2b3c"} {
+		if pattern.MatchString(bad) {
+			t.Errorf("schema accepts %q", bad)
+		}
+	}
+}
@@ -0,0 +1,251 @@
+package palette
+
+//go:generate go run gen_schema.go
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// schemaJSON is the checked-in output of GenerateSchema, kept current by
+// go generate and a test that regenerates it.
+//
+//go:embed schema.json
+var schemaJSON []byte
+
+// JSONSchema returns a JSON Schema (draft-07) describing palette.toml, for
+// editors and TOML language servers such as taplo.
+func JSONSchema() []byte {
+	return schemaJSON
+}
+
+// colorPattern accepts what a Color may be written as: hex with 3, 6, or 8
+// digits, or an expression or color notation (see isExpression), whose
+// syntax is left to Load to check.
+const colorPattern = `^#([0-9a-fA-F]{3}|[0-9a-fA-F]{6}|[0-9a-fA-F]{8})$|^\s*\$|\(`
+
+// schema is the subset of JSON Schema that GenerateSchema emits.
+type schema struct {
+	Schema               string     `json:"$schema,omitempty"`
+	Title                string     `json:"title,omitempty"`
+	Description          string     `json:"description,omitempty"`
+	Type                 string     `json:"type,omitempty"`
+	Enum                 []string   `json:"enum,omitempty"`
+	Pattern              string     `json:"pattern,omitempty"`
+	Properties           properties `json:"properties,omitempty"`
+	Required             []string   `json:"required,omitempty"`
+	AdditionalProperties any        `json:"additionalProperties,omitempty"`
+	If                   *schema    `json:"if,omitempty"`
+	Then                 *schema    `json:"then,omitempty"`
+	Not                  *schema    `json:"not,omitempty"`
+}
+
+// properties keeps object properties in struct declaration order, which
+// encoding/json would otherwise sort.
+type properties []property
+
+type property struct {
+	name   string
+	schema *schema
+}
+
+func (ps properties) MarshalJSON() ([]byte, error) {
+	var b strings.Builder
+	b.WriteString("{")
+	for i, p := range ps {
+		if i > 0 {
+			b.WriteString(",")
+		}
+		name, _ := marshal(p.name)
+		value, err := marshal(p.schema)
+		if err != nil {
+			return nil, err
+		}
+		b.Write(name)
+		b.WriteString(":")
+		b.Write(value)
+	}
+	b.WriteString("}")
+	return []byte(b.String()), nil
+}
+
+// GenerateSchema builds the JSON Schema for Config by walking its struct
+// tree. Descriptions come from the doc and line comments on the fields (or
+// on a field's type) in the Go sources in srcDir, the palette package
+// directory; GenerateSchema is meant for go generate and tests, not for use
+// at run time.
+func GenerateSchema(srcDir string) ([]byte, error) {
+	docs, err := fieldDocs(srcDir)
+	if err != nil {
+		return nil, err
+	}
+
+	root := schemaFor(reflect.TypeOf(Config{}), docs)
+	root.Schema = "http://json-schema.org/draft-07/schema#"
+	root.Title = "the-themer palette"
+	root.Required = []string{"theme"}
+	root.property("theme").Required = []string{"name"}
+	root.property("theme").property("variant").Enum = variants
+
+	// A theme that extends another may inherit every color, so the
+	// required colors only apply to standalone themes.
+	root.If = &schema{Properties: properties{{"theme", &schema{Not: &schema{Required: []string{"extends"}}}}}}
+	root.Then = &schema{
+		Required:   []string{"palette"},
+		Properties: properties{{"palette", &schema{Required: requiredPaletteKeys}}},
+	}
+
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(root); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// marshal is json.Marshal without HTML escaping, so descriptions keep
+// their "<" and ">" readable.
+func marshal(v any) ([]byte, error) {
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(b.Bytes(), []byte("\n")), nil
+}
+
+// property returns the schema of the named property.
+func (s *schema) property(name string) *schema {
+	for _, p := range s.Properties {
+		if p.name == name {
+			return p.schema
+		}
+	}
+	panic("palette: no schema property " + name)
+}
+
+// schemaFor maps a Go type to its schema. Structs become closed objects,
+// since Load reports unknown keys; maps become objects with any key.
+func schemaFor(t reflect.Type, docs map[string]string) *schema {
+	switch {
+	case t == reflect.TypeOf(Color("")):
+		return &schema{Type: "string", Pattern: colorPattern}
+	case t.Kind() == reflect.String:
+		return &schema{Type: "string"}
+	case t.Kind() == reflect.Bool:
+		return &schema{Type: "boolean"}
+	case t.Kind() == reflect.Map:
+		return &schema{Type: "object", AdditionalProperties: schemaFor(t.Elem(), docs)}
+	case t.Kind() == reflect.Struct:
+		s := &schema{Type: "object", AdditionalProperties: false}
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			key := f.Tag.Get("toml")
+			if key == "" || key == "-" {
+				continue
+			}
+			ps := schemaFor(f.Type, docs)
+			ps.Description = docs[t.Name()+"."+f.Name]
+			if n, err := strconv.Atoi(strings.TrimPrefix(key, "color")); err == nil && ps.Description == "" {
+				ps.Description = fmt.Sprintf("ANSI color %d (%s)", n, ansiNames[n])
+			} else if ps.Description == "" && f.Type.Kind() != reflect.String {
+				ps.Description = docs[typeName(f.Type)]
+			}
+			s.Properties = append(s.Properties, property{key, ps})
+		}
+		return s
+	}
+	panic(fmt.Sprintf("palette: no schema for %s", t))
+}
+
+// typeName names t, or the element type of a map, as fieldDocs keys it.
+func typeName(t reflect.Type) string {
+	if t.Kind() == reflect.Map {
+		t = t.Elem()
+	}
+	return t.Name()
+}
+
+// fieldDocs reads the comments on struct types and their fields in the
+// package sources, keyed "Type" and "Type.Field". A field's doc comment
+// wins over its line comment; both are flattened to one line.
+func fieldDocs(dir string) (map[string]string, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return nil, err
+	}
+
+	docs := map[string]string{}
+	fset := token.NewFileSet()
+	for _, path := range paths {
+		if strings.HasSuffix(path, "_test.go") {
+			continue
+		}
+		src, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		file, err := parser.ParseFile(fset, path, src, parser.ParseComments)
+		if err != nil {
+			return nil, err
+		}
+		for _, decl := range file.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				ts := spec.(*ast.TypeSpec)
+				doc := ts.Doc
+				if doc == nil && len(gen.Specs) == 1 {
+					doc = gen.Doc
+				}
+				if text := flatten(doc); text != "" {
+					docs[ts.Name.Name] = text
+				}
+
+				st, ok := ts.Type.(*ast.StructType)
+				if !ok {
+					continue
+				}
+				for _, f := range st.Fields.List {
+					text := flatten(f.Doc)
+					if text == "" {
+						text = flatten(f.Comment)
+					}
+					for _, name := range f.Names {
+						if text != "" {
+							docs[ts.Name.Name+"."+name.Name] = text
+						}
+					}
+				}
+			}
+		}
+	}
+	return docs, nil
+}
+
+// flatten joins a comment's paragraphs into one line, dropping the Go doc
+// directives (//go:embed and the like).
+func flatten(cg *ast.CommentGroup) string {
+	if cg == nil {
+		return ""
+	}
+	lines := strings.Split(cg.Text(), "\n")
+	lines = slices.DeleteFunc(lines, func(l string) bool { return strings.TrimSpace(l) == "" })
+	return strings.Join(lines, " ")
+}
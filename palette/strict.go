@@ -0,0 +1,237 @@
+package palette
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// Position locates a key in a palette file. Line and Col are 1-based; a
+// zero Line means only the file is known.
+type Position struct {
+	File string
+	Line int
+	Col  int
+}
+
+// String formats the position as "file:line:col", dropping the parts that
+// are unknown.
+func (p Position) String() string {
+	switch {
+	case p.Line == 0:
+		return p.File
+	case p.File == "":
+		return fmt.Sprintf("%d:%d", p.Line, p.Col)
+	}
+	return fmt.Sprintf("%s:%d:%d", p.File, p.Line, p.Col)
+}
+
+// ValidationError is one problem found in a palette, located at the key it
+// concerns when that is known.
+type ValidationError struct {
+	Key string   // dotted key, e.g. "palette.bg"; empty when not key-specific
+	Pos Position // where Key is written (or the nearest enclosing table)
+	Msg string
+}
+
+// Error prefixes the message with its position, compiler style.
+func (e ValidationError) Error() string {
+	if pos := e.Pos.String(); pos != "" {
+		return pos + ": " + e.Msg
+	}
+	return e.Msg
+}
+
+// errorAt builds a ValidationError for key, positioned from c.Positions.
+func (c *Config) errorAt(key, format string, args ...any) ValidationError {
+	return ValidationError{Key: key, Pos: c.position(key), Msg: fmt.Sprintf(format, args...)}
+}
+
+// position returns where key was set. A key that isn't written anywhere
+// (a missing required key, or one inside an inline table) falls back to its
+// nearest enclosing table, then to the file.
+func (c *Config) position(key string) Position {
+	for k := key; k != ""; {
+		if pos, ok := c.Positions[k]; ok {
+			return pos
+		}
+		i := strings.LastIndex(k, ".")
+		if i < 0 {
+			break
+		}
+		k = k[:i]
+	}
+	return Position{File: c.Positions[""].File}
+}
+
+// keyPositions maps every table header and key assignment in a TOML
+// document to its position, by dotted key. The "" entry records the file.
+// It is a line scanner, not a parser: it handles the layouts palette files
+// use (headers, dotted and quoted keys) and skips anything else.
+func keyPositions(data []byte, file string) map[string]Position {
+	positions := map[string]Position{"": {File: file}}
+	table := ""
+	for i, line := range strings.Split(string(data), "\n") {
+		trimmed := strings.TrimLeft(line, " \t")
+		col := len(line) - len(trimmed) + 1
+		pos := Position{File: file, Line: i + 1, Col: col}
+
+		switch {
+		case trimmed == "" || strings.HasPrefix(trimmed, "#"):
+			continue
+		case strings.HasPrefix(trimmed, "["):
+			end := strings.Index(trimmed, "]")
+			if end < 0 {
+				continue
+			}
+			table = normalizeKey(strings.Trim(trimmed[:end], "[ \t"))
+			if _, ok := positions[table]; !ok {
+				positions[table] = pos
+			}
+		default:
+			key, _, ok := strings.Cut(trimmed, "=")
+			if !ok {
+				continue
+			}
+			full := normalizeKey(key)
+			if table != "" {
+				full = table + "." + full
+			}
+			positions[full] = pos
+		}
+	}
+	return positions
+}
+
+// normalizeKey strips whitespace and quotes from each part of a dotted key.
+func normalizeKey(key string) string {
+	parts := strings.Split(key, ".")
+	for i, p := range parts {
+		parts[i] = strings.Trim(strings.TrimSpace(p), `"'`)
+	}
+	return strings.Join(parts, ".")
+}
+
+// unknownKeys reports every key in md that didn't decode into Config, with
+// a suggestion when a known key is a close spelling. Only the outermost
+// unknown key is reported: a misspelled table doesn't also list its keys.
+func (c *Config) unknownKeys(md toml.MetaData) ValidationErrors {
+	var errs ValidationErrors
+	var reported []string
+	for _, key := range md.Undecoded() {
+		name := key.String()
+		if hasPrefix(reported, name) {
+			continue
+		}
+		reported = append(reported, name)
+
+		parent, last := key[:len(key)-1], key[len(key)-1]
+		hint := ""
+		if s := suggest(last, schemaChildren(parent)); s != "" {
+			hint = fmt.Sprintf(" (did you mean %q?)", s)
+		}
+		kind := "key"
+		if md.Type(key...) == "Hash" {
+			kind = "table"
+		}
+		errs = append(errs, c.errorAt(name, "unknown %s %s%s", kind, name, hint))
+	}
+	return errs
+}
+
+// hasPrefix reports whether key lies inside any of tables.
+func hasPrefix(tables []string, key string) bool {
+	for _, t := range tables {
+		if strings.HasPrefix(key, t+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// schemaChildren lists the keys Config accepts directly under path.
+// Map-valued tables ([adapters], [references]) accept any key, so their
+// names don't constrain suggestions.
+func schemaChildren(path []string) []string {
+	t := reflect.TypeOf(Config{})
+	for _, seg := range path {
+		switch t.Kind() {
+		case reflect.Map:
+			t = t.Elem()
+			continue
+		case reflect.Struct:
+			f, ok := fieldByTag(t, seg)
+			if !ok {
+				return nil
+			}
+			t = f.Type
+			continue
+		}
+		return nil
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+	var keys []string
+	for i := 0; i < t.NumField(); i++ {
+		if tag := t.Field(i).Tag.Get("toml"); tag != "" && tag != "-" {
+			keys = append(keys, tag)
+		}
+	}
+	return keys
+}
+
+func fieldByTag(t reflect.Type, tag string) (reflect.StructField, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		if t.Field(i).Tag.Get("toml") == tag {
+			return t.Field(i), true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+// suggest returns the candidate closest to key by edit distance, if it is
+// close enough to be a plausible typo.
+func suggest(key string, candidates []string) string {
+	best, bestDist := "", 0
+	for _, c := range candidates {
+		d := editDistance(key, c)
+		if best == "" || d < bestDist {
+			best, bestDist = c, d
+		}
+	}
+	limit := max(1, len(key)/3)
+	if best == "" || bestDist > limit {
+		return ""
+	}
+	return best
+}
+
+// editDistance is the optimal string alignment distance between a and b:
+// Levenshtein distance with adjacent transpositions ("gb" for "bg")
+// counting as one edit.
+func editDistance(a, b string) int {
+	d := make([][]int, len(a)+1)
+	for i := range d {
+		d[i] = make([]int, len(b)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			d[i][j] = min(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				d[i][j] = min(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+	return d[len(a)][len(b)]
+}
@@ -0,0 +1,342 @@
+package theme
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// now is the clock backups are stamped with; tests replace it.
+var now = time.Now
+
+// backupTimeFormat names backup directories; it sorts chronologically.
+const backupTimeFormat = "20060102T150405Z"
+
+// Backup is a set of user files the themer saved before replacing them for
+// the first time, under ~/.config/the-themer/backups/<ID>/.
+type Backup struct {
+	ID      string       `json:"id"`
+	Created time.Time    `json:"created"`
+	Files   []BackupFile `json:"files"`
+
+	Dir string `json:"-"` // absolute path of the backup directory
+}
+
+// BackupFile is one saved file: a regular file's contents are stored in the
+// backup directory; a symlink only needs its target.
+type BackupFile struct {
+	Path    string      `json:"path"`              // where the file lives
+	Stored  string      `json:"stored,omitempty"`  // copy, relative to the backup directory
+	Symlink string      `json:"symlink,omitempty"` // target, for a symlink
+	Mode    os.FileMode `json:"mode"`
+}
+
+// String summarizes the backup for switch and install output.
+func (b *Backup) String() string {
+	return fmt.Sprintf("backed up %d file(s) to %s", len(b.Files), b.Dir)
+}
+
+// discard deletes a backup that turned out not to be needed, and the
+// backups directory with it if that leaves it empty.
+func (b *Backup) discard() {
+	if b == nil {
+		return
+	}
+	os.RemoveAll(b.Dir)
+	os.Remove(filepath.Dir(b.Dir))
+}
+
+// backupsDir returns the directory holding all backups.
+func backupsDir(home string) string {
+	return filepath.Join(stateDir(home), "backups")
+}
+
+// managedPath returns the path to the list of files the themer manages.
+func managedPath(home string) string {
+	return filepath.Join(stateDir(home), "managed")
+}
+
+// readManaged returns the set of paths the themer has written: those it
+// can replace without a backup. Everything under the state directory is
+// managed implicitly.
+func readManaged(home string) (map[string]bool, error) {
+	managed := map[string]bool{}
+	data, err := os.ReadFile(managedPath(home))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return managed, nil
+		}
+		return nil, fmt.Errorf("reading managed files: %w", err)
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			managed[line] = true
+		}
+	}
+	return managed, nil
+}
+
+// writeManaged records the set of managed paths, sorted.
+func writeManaged(home string, managed map[string]bool) error {
+	paths := make([]string, 0, len(managed))
+	for p := range managed {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+
+	var b strings.Builder
+	for _, p := range paths {
+		b.WriteString(p + "\n")
+	}
+	if err := os.MkdirAll(stateDir(home), 0o755); err != nil {
+		return fmt.Errorf("creating state directory: %w", err)
+	}
+	if err := os.WriteFile(managedPath(home), []byte(b.String()), 0o644); err != nil {
+		return fmt.Errorf("writing managed files: %w", err)
+	}
+	return nil
+}
+
+// markManaged adds the destinations of the actions to the managed list.
+func markManaged(home string, actions []Action) error {
+	managed, err := readManaged(home)
+	if err != nil {
+		return err
+	}
+	changed := false
+	for _, a := range actions {
+		if a.Kind != ActionCommand && !managed[a.Path] && !inStateDir(home, a.Path) {
+			managed[a.Path] = true
+			changed = true
+		}
+	}
+	if !changed {
+		return nil
+	}
+	return writeManaged(home, managed)
+}
+
+// unmarkManaged drops the paths the actions removed from the managed list.
+func unmarkManaged(home string, actions []Action) error {
+	managed, err := readManaged(home)
+	if err != nil {
+		return err
+	}
+	changed := false
+	for _, a := range actions {
+		if a.Kind == ActionRemove && managed[a.Path] {
+			delete(managed, a.Path)
+			changed = true
+		}
+	}
+	if !changed {
+		return nil
+	}
+	return writeManaged(home, managed)
+}
+
+// inStateDir reports whether path is inside the themer's own directory.
+func inStateDir(home, path string) bool {
+	rel, err := filepath.Rel(stateDir(home), path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// backupUnmanaged saves every existing file the actions would replace that
+// the themer didn't write itself. It returns nil when there was nothing to
+// save.
+func backupUnmanaged(home string, actions []Action) (*Backup, error) {
+	managed, err := readManaged(home)
+	if err != nil {
+		return nil, err
+	}
+
+	var paths []string
+	seen := map[string]bool{}
+	for _, a := range actions {
+		if a.Kind == ActionCommand || managed[a.Path] || seen[a.Path] || inStateDir(home, a.Path) {
+			continue
+		}
+		seen[a.Path] = true
+		if _, err := os.Lstat(a.Path); err == nil {
+			paths = append(paths, a.Path)
+		}
+	}
+	if len(paths) == 0 {
+		return nil, nil
+	}
+
+	created := now().UTC()
+	b := &Backup{ID: created.Format(backupTimeFormat), Created: created}
+	b.Dir = filepath.Join(backupsDir(home), b.ID)
+	for i := 1; ; i++ {
+		if _, err := os.Lstat(b.Dir); os.IsNotExist(err) {
+			break
+		}
+		b.ID = fmt.Sprintf("%s-%d", created.Format(backupTimeFormat), i)
+		b.Dir = filepath.Join(backupsDir(home), b.ID)
+	}
+	if err := os.MkdirAll(b.Dir, 0o755); err != nil {
+		return nil, fmt.Errorf("creating backup directory: %w", err)
+	}
+
+	for _, path := range paths {
+		f, err := backupFile(home, b.Dir, path)
+		if err != nil {
+			os.RemoveAll(b.Dir)
+			return nil, err
+		}
+		b.Files = append(b.Files, f)
+	}
+
+	data, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(filepath.Join(b.Dir, "manifest.json"), append(data, '\n'), 0o644); err != nil {
+		os.RemoveAll(b.Dir)
+		return nil, fmt.Errorf("writing backup manifest: %w", err)
+	}
+	return b, nil
+}
+
+// backupFile saves path into dir, mirroring its location under home.
+func backupFile(home, dir, path string) (BackupFile, error) {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return BackupFile{}, fmt.Errorf("backing up %s: %w", path, err)
+	}
+	f := BackupFile{Path: path, Mode: info.Mode()}
+	if info.Mode()&os.ModeSymlink != 0 {
+		f.Symlink, err = os.Readlink(path)
+		if err != nil {
+			return BackupFile{}, fmt.Errorf("backing up %s: %w", path, err)
+		}
+		return f, nil
+	}
+
+	rel, err := filepath.Rel(home, path)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		rel = strings.TrimPrefix(path, string(filepath.Separator))
+	}
+	f.Stored = filepath.Join("files", rel)
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return BackupFile{}, fmt.Errorf("backing up %s: %w", path, err)
+	}
+	stored := filepath.Join(dir, f.Stored)
+	if err := os.MkdirAll(filepath.Dir(stored), 0o755); err != nil {
+		return BackupFile{}, fmt.Errorf("backing up %s: %w", path, err)
+	}
+	if err := os.WriteFile(stored, data, info.Mode().Perm()); err != nil {
+		return BackupFile{}, fmt.Errorf("backing up %s: %w", path, err)
+	}
+	return f, nil
+}
+
+// ListBackups returns the backups under home, oldest first.
+func ListBackups(home string) ([]Backup, error) {
+	entries, err := os.ReadDir(backupsDir(home))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("reading backups: %w", err)
+	}
+
+	var backups []Backup
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		b, err := LoadBackup(home, e.Name())
+		if err != nil {
+			return nil, err
+		}
+		backups = append(backups, b)
+	}
+	sort.Slice(backups, func(i, j int) bool { return backups[i].ID < backups[j].ID })
+	return backups, nil
+}
+
+// LoadBackup reads the manifest of the backup with the given ID.
+func LoadBackup(home, id string) (Backup, error) {
+	dir := filepath.Join(backupsDir(home), id)
+	data, err := os.ReadFile(filepath.Join(dir, "manifest.json"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return Backup{}, fmt.Errorf("no backup %q in %s", id, backupsDir(home))
+		}
+		return Backup{}, fmt.Errorf("reading backup %s: %w", id, err)
+	}
+	var b Backup
+	if err := json.Unmarshal(data, &b); err != nil {
+		return Backup{}, fmt.Errorf("parsing backup %s manifest: %w", id, err)
+	}
+	b.ID, b.Dir = id, dir
+	return b, nil
+}
+
+// RestoreBackup puts back every file in the backup with the given ID, or
+// in the latest backup if id is "". Restored files are the user's again:
+// they're dropped from the managed list, so the next switch backs them up
+// before replacing them.
+func RestoreBackup(home, id string) (Backup, error) {
+	if id == "" {
+		backups, err := ListBackups(home)
+		if err != nil {
+			return Backup{}, err
+		}
+		if len(backups) == 0 {
+			return Backup{}, errors.New("no backups to restore")
+		}
+		id = backups[len(backups)-1].ID
+	}
+
+	b, err := LoadBackup(home, id)
+	if err != nil {
+		return Backup{}, err
+	}
+
+	managed, err := readManaged(home)
+	if err != nil {
+		return Backup{}, err
+	}
+	for _, f := range b.Files {
+		if err := f.restore(b.Dir); err != nil {
+			return b, err
+		}
+		delete(managed, f.Path)
+	}
+	return b, writeManaged(home, managed)
+}
+
+// restore puts the file back from the backup directory dir.
+func (f BackupFile) restore(dir string) error {
+	if err := os.MkdirAll(filepath.Dir(f.Path), 0o755); err != nil {
+		return fmt.Errorf("restoring %s: %w", f.Path, err)
+	}
+	if err := os.Remove(f.Path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("restoring %s: %w", f.Path, err)
+	}
+
+	if f.Symlink != "" {
+		if err := os.Symlink(f.Symlink, f.Path); err != nil {
+			return fmt.Errorf("restoring %s: %w", f.Path, err)
+		}
+		return nil
+	}
+	data, err := os.ReadFile(filepath.Join(dir, f.Stored))
+	if err != nil {
+		return fmt.Errorf("restoring %s: %w", f.Path, err)
+	}
+	if err := os.WriteFile(f.Path, data, f.Mode.Perm()); err != nil {
+		return fmt.Errorf("restoring %s: %w", f.Path, err)
+	}
+	return nil
+}
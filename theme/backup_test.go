@@ -0,0 +1,142 @@
+package theme
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSwitch_BackupAndRestore(t *testing.T) {
+	themesDir, themeDir := setupThemeDir(t, []string{"starship", "gh-dash"}, minimalPaletteTOML)
+	writeFile(t, filepath.Join(themeDir, "starship", "test-starship.toml"), "starship content")
+	writeFile(t, filepath.Join(themeDir, "gh-dash", "test-theme.yml"), ghDashThemeYAML)
+	th, err := LoadTheme(themesDir, "test-theme")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	home := t.TempDir()
+	Install(th, InstallOpts{HomeDir: home})
+	orig := now
+	t.Cleanup(func() { now = orig })
+	now = func() time.Time { return time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC) }
+
+	// The user's own configs.
+	starship := filepath.Join(home, ".config", "starship.toml")
+	ghDash := filepath.Join(home, ".config", "gh-dash", "config.yml")
+	writeFile(t, starship, "format = 'mine'\n")
+	writeFile(t, ghDash, "mine: true\n")
+
+	// A regular starship.toml is refused without Force, before anything
+	// is touched.
+	results := Switch(th, SwitchOpts{HomeDir: home})
+	for _, r := range results {
+		if (r.Err != nil) != (r.App == "starship") {
+			t.Errorf("%s: Err = %v", r.App, r.Err)
+		}
+	}
+	assertFileContains(t, ghDash, "mine: true")
+	if backups, _ := ListBackups(home); len(backups) != 0 {
+		t.Fatalf("refused switch left backups: %+v", backups)
+	}
+
+	checkNoErrors(t, Switch(th, SwitchOpts{HomeDir: home, Force: true}))
+	assertFileContains(t, ghDash, `primary: "#0000aa"`)
+	if target, err := os.Readlink(starship); err != nil || filepath.Base(target) != "test-starship.toml" {
+		t.Fatalf("starship.toml -> %q, %v; want the installed config", target, err)
+	}
+
+	backups, err := ListBackups(home)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(backups) != 1 || backups[0].ID != "20260102T030405Z" || len(backups[0].Files) != 2 {
+		t.Fatalf("backups = %+v, want one with starship.toml and config.yml", backups)
+	}
+
+	// The themer's own files are replaced without another backup.
+	checkNoErrors(t, Switch(th, SwitchOpts{HomeDir: home}))
+	if backups, _ := ListBackups(home); len(backups) != 1 {
+		t.Fatalf("second switch backed up again: %+v", backups)
+	}
+
+	b, err := RestoreBackup(home, "")
+	if err != nil {
+		t.Fatalf("RestoreBackup: %v", err)
+	}
+	if b.ID != "20260102T030405Z" {
+		t.Errorf("restored %s, want the latest backup", b.ID)
+	}
+	assertFileContains(t, starship, "format = 'mine'")
+	assertFileContains(t, ghDash, "mine: true")
+	if info, err := os.Lstat(starship); err != nil || info.Mode()&os.ModeSymlink != 0 {
+		t.Errorf("starship.toml is not a regular file after restore")
+	}
+
+	// Restored files are the user's again.
+	managed, err := readManaged(home)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if managed[starship] || managed[ghDash] {
+		t.Errorf("restored files still managed: %v", managed)
+	}
+}
+
+func TestRestoreBackup_Errors(t *testing.T) {
+	home := t.TempDir()
+	if _, err := RestoreBackup(home, ""); err == nil {
+		t.Error("RestoreBackup with no backups: want error")
+	}
+	if _, err := RestoreBackup(home, "20260102T030405Z"); err == nil {
+		t.Error("RestoreBackup of a missing backup: want error")
+	}
+}
+
+func TestSwitch_WritesThroughUserSymlink(t *testing.T) {
+	themesDir, themeDir := setupThemeDir(t, []string{"ghostty", "tcm"}, minimalPaletteTOML)
+	writeFile(t, filepath.Join(themeDir, "ghostty", "test-theme.ghostty"), "ghostty config")
+	writeFile(t, filepath.Join(themeDir, "tcm", "test-theme.json"), `{"tcm": true}`)
+	th, err := LoadTheme(themesDir, "test-theme")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	home := t.TempDir()
+	Install(th, InstallOpts{HomeDir: home})
+
+	// theme.local links into the user's dotfiles; active-theme.json is a
+	// link the themer made in an older version.
+	dotfile := filepath.Join(t.TempDir(), "ghostty.local")
+	writeFile(t, dotfile, "theme = mine\n")
+	themeLocal := filepath.Join(home, ".config", "ghostty", "theme.local")
+	if err := os.Symlink(dotfile, themeLocal); err != nil {
+		t.Fatal(err)
+	}
+	installed := filepath.Join(home, ".config", "tcm", "test-theme.json")
+	active := filepath.Join(home, ".config", "tcm", "active-theme.json")
+	if err := os.Symlink(installed, active); err != nil {
+		t.Fatal(err)
+	}
+
+	checkNoErrors(t, Switch(th, SwitchOpts{HomeDir: home}))
+
+	if target, err := os.Readlink(themeLocal); err != nil || target != dotfile {
+		t.Errorf("theme.local -> %q, %v; want the link into dotfiles kept", target, err)
+	}
+	assertFileContains(t, dotfile, "theme = test-theme.ghostty")
+	if info, err := os.Lstat(active); err != nil || info.Mode()&os.ModeSymlink != 0 {
+		t.Errorf("active-theme.json is still a symlink (err %v)", err)
+	}
+	assertFileContains(t, active, `{"tcm": true}`)
+
+	// The dotfile itself was backed up, so restore puts its contents back.
+	if _, err := RestoreBackup(home, ""); err != nil {
+		t.Fatalf("RestoreBackup: %v", err)
+	}
+	assertFileContains(t, dotfile, "theme = mine")
+	if target, err := os.Readlink(themeLocal); err != nil || target != dotfile {
+		t.Errorf("theme.local -> %q, %v after restore; want the link into dotfiles", target, err)
+	}
+}
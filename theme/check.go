@@ -0,0 +1,117 @@
+package theme
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/kylesnowschwartz/the-themer/adapter"
+)
+
+// Generated is one adapter's output rendered for a theme, with the path in
+// the theme directory it belongs at.
+type Generated struct {
+	Adapter string
+	Path    string // absolute path in the theme directory (see AdapterPath)
+	Content []byte
+}
+
+// Generate renders, in memory, every adapter that the theme has a
+// directory for. Adapters without a directory aren't part of the theme
+// and are skipped. Outputs are in adapter order.
+func Generate(t Theme, adapters []adapter.Adapter) ([]Generated, error) {
+	var out []Generated
+	for _, a := range adapters {
+		dir := filepath.Join(t.Dir, a.DirName())
+		if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+			continue
+		}
+
+		cfg, err := t.Config.ForAdapter(a.Name())
+		if err != nil {
+			return nil, err
+		}
+		content, err := a.Generate(cfg)
+		if err != nil {
+			return nil, fmt.Errorf("adapter %s: %w", a.Name(), err)
+		}
+
+		path, err := AdapterPath(t, a)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, Generated{Adapter: a.Name(), Path: path, Content: content})
+	}
+	return out, nil
+}
+
+// AdapterPath returns the file in t's directory that holds a's output:
+// the one named by a.FileName, or, when that doesn't exist and the app
+// directory holds exactly one file under another name (a theme reusing an
+// upstream theme's file name), that file.
+func AdapterPath(t Theme, a adapter.Adapter) (string, error) {
+	dir := filepath.Join(t.Dir, a.DirName())
+	path := filepath.Join(dir, a.FileName(t.Config.Theme.Name))
+	if _, err := os.Stat(path); err == nil {
+		return path, nil
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return path, nil
+		}
+		return "", fmt.Errorf("reading %s: %w", dir, err)
+	}
+	var files []string
+	for _, e := range entries {
+		if e.Type().IsRegular() && !strings.HasPrefix(e.Name(), ".") {
+			files = append(files, e.Name())
+		}
+	}
+	if len(files) == 1 {
+		return filepath.Join(dir, files[0]), nil
+	}
+	return path, nil
+}
+
+// Drift is a generated file in a theme directory whose contents differ
+// from what its adapter renders from the current palette.
+type Drift struct {
+	Adapter string
+	Path    string // absolute path of the file in the theme directory
+	OnDisk  []byte // nil when the file is missing
+	Want    []byte // freshly generated content
+}
+
+// CheckDrift regenerates the theme's adapters in memory (see Generate)
+// and reports the files that differ from the output.
+func CheckDrift(t Theme, adapters []adapter.Adapter) ([]Drift, error) {
+	generated, err := Generate(t, adapters)
+	if err != nil {
+		return nil, err
+	}
+
+	var drifts []Drift
+	for _, g := range generated {
+		onDisk, err := os.ReadFile(g.Path)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("reading %s: %w", g.Path, err)
+		}
+		if onDisk == nil || !bytes.Equal(onDisk, g.Content) {
+			drifts = append(drifts, Drift{Adapter: g.Adapter, Path: g.Path, OnDisk: onDisk, Want: g.Content})
+		}
+	}
+	return drifts, nil
+}
+
+// Fix rewrites the drifted file with the generated content.
+func (d Drift) Fix() error {
+	if err := os.WriteFile(d.Path, d.Want, 0o644); err != nil {
+		return fmt.Errorf("writing %s: %w", d.Path, err)
+	}
+	return nil
+}
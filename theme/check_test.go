@@ -0,0 +1,131 @@
+package theme
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kylesnowschwartz/the-themer/adapter"
+	_ "github.com/kylesnowschwartz/the-themer/adapter/base16"
+	_ "github.com/kylesnowschwartz/the-themer/adapter/bat"
+	_ "github.com/kylesnowschwartz/the-themer/adapter/delta"
+	_ "github.com/kylesnowschwartz/the-themer/adapter/fzf"
+	_ "github.com/kylesnowschwartz/the-themer/adapter/ghdash"
+	_ "github.com/kylesnowschwartz/the-themer/adapter/ghostty"
+	_ "github.com/kylesnowschwartz/the-themer/adapter/hud"
+	_ "github.com/kylesnowschwartz/the-themer/adapter/tcm"
+)
+
+func TestCheckDrift(t *testing.T) {
+	themesDir, themeDir := setupThemeDir(t, []string{"fzf", "ghostty"}, minimalPaletteTOML)
+	th, err := LoadTheme(themesDir, "test-theme")
+	if err != nil {
+		t.Fatalf("LoadTheme: %v", err)
+	}
+	adapters := adapter.ByName([]string{"fzf", "ghostty", "bat"})
+
+	// Both files missing; bat has no directory, so it isn't checked.
+	drifts, err := CheckDrift(th, adapters)
+	if err != nil {
+		t.Fatalf("CheckDrift: %v", err)
+	}
+	if len(drifts) != 2 {
+		t.Fatalf("got %d drifts, want 2 (fzf, ghostty): %+v", len(drifts), drifts)
+	}
+	for _, d := range drifts {
+		if d.OnDisk != nil {
+			t.Errorf("%s: OnDisk = %q, want nil for a missing file", d.Adapter, d.OnDisk)
+		}
+		if err := d.Fix(); err != nil {
+			t.Fatalf("Fix: %v", err)
+		}
+	}
+
+	if drifts, err := CheckDrift(th, adapters); err != nil || len(drifts) != 0 {
+		t.Fatalf("after Fix: drifts = %+v, err = %v", drifts, err)
+	}
+
+	// Hand-edit a generated file.
+	path := filepath.Join(themeDir, "fzf", "test-theme.zsh")
+	writeFile(t, path, "# stale\n")
+	drifts, err = CheckDrift(th, adapters)
+	if err != nil {
+		t.Fatalf("CheckDrift: %v", err)
+	}
+	if len(drifts) != 1 || drifts[0].Adapter != "fzf" || drifts[0].Path != path || string(drifts[0].OnDisk) != "# stale\n" {
+		t.Fatalf("drifts = %+v, want the edited fzf file", drifts)
+	}
+	if err := drifts[0].Fix(); err != nil {
+		t.Fatalf("Fix: %v", err)
+	}
+	got, _ := os.ReadFile(path)
+	if string(got) != string(drifts[0].Want) {
+		t.Errorf("Fix wrote %q, want the generated content", got)
+	}
+}
+
+// TestCheckDrift_Warehouse keeps the checked-in themes in sync with their
+// palettes and the adapters.
+func TestCheckDrift_Warehouse(t *testing.T) {
+	names, err := ListThemes("../themes")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range names {
+		th, err := LoadTheme("../themes", name)
+		if err != nil {
+			t.Fatalf("LoadTheme(%s): %v", name, err)
+		}
+		drifts, err := CheckDrift(th, adapter.All())
+		if err != nil {
+			t.Fatalf("CheckDrift(%s): %v", name, err)
+		}
+		for _, d := range drifts {
+			t.Errorf("%s is out of date; run the-themer check --fix", d.Path)
+		}
+	}
+}
+
+func TestAdapterPath(t *testing.T) {
+	themesDir, themeDir := setupThemeDir(t, []string{"fzf", "ghostty", "delta"}, minimalPaletteTOML)
+	th, err := LoadTheme(themesDir, "test-theme")
+	if err != nil {
+		t.Fatalf("LoadTheme: %v", err)
+	}
+
+	// fzf keeps the one file it has under its upstream name; ghostty has
+	// two files, so it falls back to FileName; delta's dir is empty.
+	writeFile(t, filepath.Join(themeDir, "fzf", "upstream.zsh"), "# upstream\n")
+	writeFile(t, filepath.Join(themeDir, "fzf", ".DS_Store"), "")
+	writeFile(t, filepath.Join(themeDir, "ghostty", "a"), "")
+	writeFile(t, filepath.Join(themeDir, "ghostty", "b"), "")
+
+	tests := []struct {
+		adapter string
+		want    string
+	}{
+		{"fzf", filepath.Join(themeDir, "fzf", "upstream.zsh")},
+		{"ghostty", filepath.Join(themeDir, "ghostty", "test-theme.ghostty")},
+		{"delta", filepath.Join(themeDir, "delta", "test-theme.gitconfig")},
+		{"bat", filepath.Join(themeDir, "bat", "test-theme.tmTheme")},
+	}
+	for _, tt := range tests {
+		got, err := AdapterPath(th, adapter.ByName([]string{tt.adapter})[0])
+		if err != nil {
+			t.Fatalf("AdapterPath(%s): %v", tt.adapter, err)
+		}
+		if got != tt.want {
+			t.Errorf("AdapterPath(%s) = %s, want %s", tt.adapter, got, tt.want)
+		}
+	}
+
+	// Generate writes nowhere itself but targets the same paths, and skips
+	// bat, which has no directory.
+	generated, err := Generate(th, adapter.ByName([]string{"fzf", "bat"}))
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	if len(generated) != 1 || generated[0].Path != tests[0].want {
+		t.Fatalf("Generate = %+v, want only fzf at %s", generated, tests[0].want)
+	}
+}
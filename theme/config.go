@@ -0,0 +1,183 @@
+package theme
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/BurntSushi/toml"
+)
+
+// Config is the-themer's own configuration, read from config.toml in its
+// state directory. Every field is optional:
+//
+//	themes_dir = "~/src/the-themer/themes"
+//	disabled = ["neovim", "pi"]
+//
+//	[reload]
+//	ghostty = false    # don't send Ghostty its reload keybind
+//	bat_cache = false  # don't run "bat cache --build"
+//
+//	[apps.starship]
+//	file = "~/.config/starship/starship.toml"
+//
+// Each app's [apps.<name>] table can set dir, where install copies the
+// theme's files for the app to find, and file, the file switch writes or
+// links; see appPaths for which apps have which. Defaults live under
+// $XDG_CONFIG_HOME (~/.config).
+type Config struct {
+	ThemesDir string               `toml:"themes_dir"`
+	Disabled  []string             `toml:"disabled"`
+	Reload    ReloadConfig         `toml:"reload"`
+	Apps      map[string]AppConfig `toml:"apps"`
+}
+
+// ReloadConfig chooses which running apps switch and install nudge to pick
+// up a change.
+type ReloadConfig struct {
+	Ghostty  bool `toml:"ghostty"`   // send Cmd+Shift+R to a running Ghostty (macOS)
+	BatCache bool `toml:"bat_cache"` // rebuild bat's cache after changing its themes
+}
+
+// AppConfig overrides an app's destinations. Paths may start with ~/.
+type AppConfig struct {
+	Dir  string `toml:"dir"`
+	File string `toml:"file"`
+}
+
+// appPaths lists the destinations config.toml can override per app, with
+// what each one is. Apps whose files the themer keeps in its own directory
+// (delta's gitconfigs, fzf, starship, gh-dash and hud copies) only expose
+// the file the app itself reads.
+var appPaths = map[string]map[string]string{
+	"ghostty":  {"dir": "themes directory", "file": "theme.local"},
+	"bat":      {"dir": "themes directory", "file": "bat-theme.txt"},
+	"delta":    {"file": "delta-theme.txt"},
+	"fzf":      {"file": "current.zsh symlink"},
+	"tcm":      {"dir": "theme directory", "file": "active-theme.json"},
+	"starship": {"file": "starship.toml symlink"},
+	"eza":      {"dir": "themes directory", "file": "theme.yml symlink"},
+	"gh-dash":  {"file": "config.yml"},
+	"hud":      {"file": "theme-active.toml"},
+	"pi":       {"file": "pi-variant"},
+}
+
+// knownApps are the app names config.toml may disable.
+var knownApps = []string{"ghostty", "bat", "delta", "fzf", "tcm", "starship", "eza", "gh-dash", "hud", "neovim", "pi"}
+
+// DefaultConfig returns the configuration used when config.toml is absent
+// or leaves a setting out.
+func DefaultConfig() Config {
+	return Config{Reload: ReloadConfig{Ghostty: true, BatCache: true}}
+}
+
+// ConfigPath returns the path to config.toml.
+func ConfigPath(home string) string {
+	return filepath.Join(stateDir(home), "config.toml")
+}
+
+// LoadConfig reads config.toml, returning DefaultConfig if there is none.
+// Paths in it have ~/ expanded against home.
+func LoadConfig(home string) (Config, error) {
+	cfg := DefaultConfig()
+	path := ConfigPath(home)
+	md, err := toml.DecodeFile(path, &cfg)
+	if errors.Is(err, os.ErrNotExist) {
+		return DefaultConfig(), nil
+	}
+	if err != nil {
+		return Config{}, fmt.Errorf("reading %s: %w", path, err)
+	}
+
+	var errs []error
+	for _, key := range md.Undecoded() {
+		errs = append(errs, fmt.Errorf("unknown key %q", key.String()))
+	}
+	for _, app := range cfg.Disabled {
+		if !slices.Contains(knownApps, app) {
+			errs = append(errs, fmt.Errorf("disabled: unknown app %q", app))
+		}
+	}
+
+	cfg.ThemesDir = expandHome(home, cfg.ThemesDir)
+	apps := slices.Sorted(maps.Keys(cfg.Apps))
+	for _, app := range apps {
+		ac := cfg.Apps[app]
+		paths, ok := appPaths[app]
+		if !ok {
+			errs = append(errs, fmt.Errorf("apps.%s: no destinations to configure for %q", app, app))
+			continue
+		}
+		for _, f := range []struct {
+			key   string
+			value *string
+		}{{"dir", &ac.Dir}, {"file", &ac.File}} {
+			if *f.value == "" {
+				continue
+			}
+			if _, ok := paths[f.key]; !ok {
+				errs = append(errs, fmt.Errorf("apps.%s.%s: %s has no configurable %s", app, f.key, app, f.key))
+				continue
+			}
+			*f.value = expandHome(home, *f.value)
+			if !filepath.IsAbs(*f.value) {
+				errs = append(errs, fmt.Errorf("apps.%s.%s: %q is not an absolute path", app, f.key, *f.value))
+			}
+		}
+		cfg.Apps[app] = ac
+	}
+
+	if len(errs) > 0 {
+		return Config{}, fmt.Errorf("%s: %w", path, errors.Join(errs...))
+	}
+	return cfg, nil
+}
+
+// disabled reports whether config.toml turns the app off.
+func (c Config) disabled(app string) bool {
+	return slices.Contains(c.Disabled, app)
+}
+
+// configHome returns $XDG_CONFIG_HOME if it is set to an absolute path, as
+// the XDG spec requires, otherwise ~/.config.
+func configHome(home string) string {
+	if dir := os.Getenv("XDG_CONFIG_HOME"); filepath.IsAbs(dir) {
+		return dir
+	}
+	return filepath.Join(home, ".config")
+}
+
+// layout resolves where install, switch and uninstall put each app's
+// files: the config home, with config.toml's overrides on top.
+type layout struct {
+	home   string
+	config string // configHome(home)
+	cfg    Config
+}
+
+func newLayout(home string, cfg *Config) layout {
+	l := layout{home: home, config: configHome(home), cfg: DefaultConfig()}
+	if cfg != nil {
+		l.cfg = *cfg
+	}
+	return l
+}
+
+// dir returns the app's configured dir, or def under the config home.
+func (l layout) dir(app string, def ...string) string {
+	if d := l.cfg.Apps[app].Dir; d != "" {
+		return d
+	}
+	return filepath.Join(append([]string{l.config}, def...)...)
+}
+
+// file returns the app's configured file, or def under the config home.
+func (l layout) file(app string, def ...string) string {
+	if f := l.cfg.Apps[app].File; f != "" {
+		return f
+	}
+	return filepath.Join(append([]string{l.config}, def...)...)
+}
@@ -0,0 +1,138 @@
+package theme
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadConfig(t *testing.T) {
+	home := t.TempDir()
+
+	cfg, err := LoadConfig(home)
+	if err != nil {
+		t.Fatalf("LoadConfig without a file: %v", err)
+	}
+	if !cfg.Reload.Ghostty || !cfg.Reload.BatCache {
+		t.Errorf("defaults: got reload %+v, want everything on", cfg.Reload)
+	}
+
+	writeFile(t, ConfigPath(home), `themes_dir = "~/themes"
+disabled = ["neovim"]
+
+[reload]
+ghostty = false
+
+[apps.starship]
+file = "~/.config/starship/starship.toml"
+`)
+	cfg, err = LoadConfig(home)
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	if want := filepath.Join(home, "themes"); cfg.ThemesDir != want {
+		t.Errorf("themes_dir = %q, want %q", cfg.ThemesDir, want)
+	}
+	if !cfg.disabled("neovim") || cfg.disabled("bat") {
+		t.Errorf("disabled = %v, want just neovim", cfg.Disabled)
+	}
+	if cfg.Reload.Ghostty || !cfg.Reload.BatCache {
+		t.Errorf("reload = %+v, want ghostty off and bat_cache left on", cfg.Reload)
+	}
+	if want := filepath.Join(home, ".config", "starship", "starship.toml"); cfg.Apps["starship"].File != want {
+		t.Errorf("apps.starship.file = %q, want %q", cfg.Apps["starship"].File, want)
+	}
+}
+
+func TestLoadConfig_Errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+		want   string
+	}{
+		{"unknown key", "theme_dir = \"x\"\n", `unknown key "theme_dir"`},
+		{"unknown disabled app", "disabled = [\"vim\"]\n", `unknown app "vim"`},
+		{"unknown app table", "[apps.vim]\nfile = \"/x\"\n", "apps.vim"},
+		{"unsupported destination", "[apps.delta]\ndir = \"/x\"\n", "delta has no configurable dir"},
+		{"relative path", "[apps.bat]\nfile = \"bat-theme.txt\"\n", "not an absolute path"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			home := t.TempDir()
+			writeFile(t, ConfigPath(home), tt.config)
+			_, err := LoadConfig(home)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("got error %v, want one containing %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestSwitch_ConfigAndXDG(t *testing.T) {
+	themesDir, themeDir := setupThemeDir(t, []string{"ghostty", "starship", "fzf"}, minimalPaletteTOML)
+	writeFile(t, filepath.Join(themeDir, "ghostty", "test-theme.ghostty"), "config")
+	writeFile(t, filepath.Join(themeDir, "starship", "chef-starship.toml"), "starship content")
+	writeFile(t, filepath.Join(themeDir, "fzf", "test-theme.zsh"), "fzf content")
+
+	home := t.TempDir()
+	xdg := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", xdg)
+
+	th, err := LoadTheme(themesDir, "test-theme")
+	if err != nil {
+		t.Fatal(err)
+	}
+	starship := filepath.Join(home, "starship", "starship.toml")
+	cfg := DefaultConfig()
+	cfg.Disabled = []string{"fzf"}
+	cfg.Apps = map[string]AppConfig{"starship": {File: starship}}
+
+	checkNoErrors(t, Switch(th, SwitchOpts{HomeDir: home, Config: &cfg}))
+
+	assertFileContains(t, filepath.Join(xdg, "ghostty", "theme.local"), "test-theme.ghostty")
+	assertFileContains(t, starship, "starship content")
+	if _, err := os.Stat(filepath.Join(xdg, "the-themer", "fzf")); !os.IsNotExist(err) {
+		t.Errorf("disabled fzf was installed (err %v)", err)
+	}
+	if _, err := os.Stat(filepath.Join(home, ".config")); !os.IsNotExist(err) {
+		t.Errorf("wrote to ~/.config with XDG_CONFIG_HOME set (err %v)", err)
+	}
+}
+
+func TestMigrateStateDir(t *testing.T) {
+	home := t.TempDir()
+	legacy := filepath.Join(home, ".config", "the-themer")
+	writeFile(t, filepath.Join(legacy, "current"), "test-theme\n")
+	writeFile(t, filepath.Join(legacy, "fzf", "test-theme.zsh"), "fzf content")
+	if err := os.Symlink(filepath.Join(legacy, "fzf", "test-theme.zsh"), filepath.Join(legacy, "fzf", "current.zsh")); err != nil {
+		t.Fatal(err)
+	}
+
+	// Without XDG_CONFIG_HOME the state directory is already in place.
+	if moved, err := MigrateStateDir(home); err != nil || moved {
+		t.Fatalf("MigrateStateDir without XDG_CONFIG_HOME = %v, %v; want no move", moved, err)
+	}
+
+	xdg := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", xdg)
+	// Until it moves, the old directory is still the state directory.
+	if got, err := ReadState(home); err != nil || got != "test-theme" {
+		t.Errorf("ReadState before migrating = %q, %v; want test-theme", got, err)
+	}
+	if moved, err := MigrateStateDir(home); err != nil || !moved {
+		t.Fatalf("MigrateStateDir = %v, %v; want a move", moved, err)
+	}
+	if got, err := ReadState(home); err != nil || got != "test-theme" {
+		t.Errorf("ReadState after migrating = %q, %v; want test-theme", got, err)
+	}
+	if target, err := os.Readlink(legacy); err != nil || target != stateDir(home) {
+		t.Errorf("old state directory = %q, %v; want a symlink to %s", target, err, stateDir(home))
+	}
+	// Links made before the move still resolve through the old path.
+	assertFileContains(t, filepath.Join(stateDir(home), "fzf", "current.zsh"), "fzf content")
+
+	if moved, err := MigrateStateDir(home); err != nil || moved {
+		t.Errorf("second MigrateStateDir = %v, %v; want no move", moved, err)
+	}
+}
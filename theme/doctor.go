@@ -0,0 +1,292 @@
+package theme
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// Status is the outcome of one doctor check, or the worst outcome of an
+// app's checks.
+type Status string
+
+const (
+	StatusPass Status = "pass"
+	StatusWarn Status = "warn"
+	StatusFail Status = "fail"
+	StatusSkip Status = "skip" // the theme has nothing for the app
+)
+
+// rank orders statuses from best to worst.
+func (s Status) rank() int {
+	switch s {
+	case StatusPass:
+		return 1
+	case StatusWarn:
+		return 2
+	case StatusFail:
+		return 3
+	}
+	return 0
+}
+
+// Finding is one check doctor made for an app.
+type Finding struct {
+	Status  Status `json:"status"`
+	Message string `json:"message"`
+}
+
+// AppHealth is everything doctor found for one app. Status is the worst
+// status among the findings.
+type AppHealth struct {
+	App      string    `json:"app"`
+	Status   Status    `json:"status"`
+	Findings []Finding `json:"findings"`
+}
+
+func (h *AppHealth) add(status Status, format string, args ...any) {
+	h.Findings = append(h.Findings, Finding{Status: status, Message: fmt.Sprintf(format, args...)})
+	if status.rank() > h.Status.rank() {
+		h.Status = status
+	}
+}
+
+// DoctorOpts configures the doctor checks.
+type DoctorOpts struct {
+	HomeDir string  // injectable for testing; defaults to os.UserHomeDir()
+	Config  *Config // destinations, disabled apps, reloads; nil for DefaultConfig()
+}
+
+// resolveHome returns opts.HomeDir if set, otherwise os.UserHomeDir().
+func (o DoctorOpts) resolveHome() (string, error) {
+	if o.HomeDir != "" {
+		return o.HomeDir, nil
+	}
+	return os.UserHomeDir()
+}
+
+// appBinaries lists the programs each app needs on PATH; any one of them
+// will do. Apps that only read files (tcm, hud, pi) aren't listed.
+var appBinaries = map[string][]string{
+	"ghostty":  {"ghostty"},
+	"bat":      {"bat"},
+	"delta":    {"delta"},
+	"fzf":      {"fzf"},
+	"starship": {"starship"},
+	"eza":      {"eza"},
+	"gh-dash":  {"gh-dash", "gh"},
+	"neovim":   {"nvim"},
+}
+
+// Doctor checks that t, taken to be the active theme, is installed and
+// switched to: that every file install copies matches the warehouse, that
+// every file and symlink switch manages is what switching to t would leave,
+// that symlinks resolve, that git includes active.gitconfig, that bat's
+// cache lists the theme, and that each app's program is on PATH.
+//
+// Doctor only reads. It plans an install and a switch and reports whatever
+// either would still change.
+func Doctor(t Theme, opts DoctorOpts) ([]AppHealth, error) {
+	home, err := opts.resolveHome()
+	if err != nil {
+		return nil, fmt.Errorf("resolving home directory: %w", err)
+	}
+	install, err := PlanInstall(t, InstallOpts{HomeDir: home, Config: opts.Config})
+	if err != nil {
+		return nil, err
+	}
+	// Force: a regular file where a symlink belongs is a finding, not a
+	// planning error. NoInstall: install's copies are checked above.
+	sw, err := PlanSwitch(t, SwitchOpts{HomeDir: home, Config: opts.Config, Force: true, NoInstall: true})
+	if err != nil {
+		return nil, err
+	}
+
+	var report []AppHealth
+	for _, s := range sw.Apps {
+		h := AppHealth{App: s.App}
+		in, ok := install.App(s.App)
+		installed := ok && !in.Skipped
+		if !installed && s.Skipped {
+			h.Status = StatusSkip
+			report = append(report, h)
+			continue
+		}
+
+		if installed {
+			checkInstalled(&h, in)
+		}
+		if !s.Skipped {
+			checkSwitched(&h, s)
+		}
+		switch {
+		case s.App == "delta" && installed:
+			checkGitInclude(&h, home)
+		case s.App == "bat" && installed:
+			checkBatCache(&h, t)
+		}
+		checkBinaries(&h)
+		report = append(report, h)
+	}
+	return report, nil
+}
+
+// checkInstalled reports the copies an install would still make: files
+// that are missing or differ from the warehouse.
+func checkInstalled(h *AppHealth, p AppPlan) {
+	if p.Err != nil {
+		h.add(StatusFail, "%v", p.Err)
+		return
+	}
+	stale := false
+	for _, a := range p.Actions {
+		if a.Kind != ActionCopy {
+			continue
+		}
+		stale = true
+		if _, err := os.Stat(a.Path); err != nil {
+			h.add(StatusFail, "%s is not installed", a.Path)
+		} else {
+			h.add(StatusFail, "%s differs from the warehouse", a.Path)
+		}
+	}
+	if !stale {
+		h.add(StatusPass, "installed files match the warehouse")
+	}
+}
+
+// checkSwitched reports the files and symlinks a switch would still change.
+func checkSwitched(h *AppHealth, p AppPlan) {
+	if p.Err != nil {
+		h.add(StatusFail, "%v", p.Err)
+		return
+	}
+	for _, a := range p.Actions {
+		switch a.Kind {
+		case ActionWrite, ActionCopy:
+			if a.Kind == ActionCopy {
+				if _, err := os.Stat(a.Src); err != nil {
+					h.add(StatusFail, "%s is not installed", a.Src)
+					continue
+				}
+			}
+			d, err := a.Diff()
+			switch {
+			case err != nil:
+				h.add(StatusFail, "%v", err)
+			case d == "":
+				h.add(StatusPass, "%s is current", a.Path)
+			case fileExists(a.Path):
+				h.add(StatusFail, "%s doesn't match the theme; switch again", a.Path)
+			default:
+				h.add(StatusFail, "%s is missing", a.Path)
+			}
+
+		case ActionSymlink:
+			checkSymlink(h, a)
+		}
+	}
+}
+
+// checkSymlink reports whether the symlink at a.Path points at a.Src and
+// resolves.
+func checkSymlink(h *AppHealth, a Action) {
+	info, err := os.Lstat(a.Path)
+	if err != nil {
+		h.add(StatusFail, "%s is missing", a.Path)
+		return
+	}
+	if info.Mode()&os.ModeSymlink == 0 {
+		h.add(StatusFail, "%s is a regular file, not a symlink", a.Path)
+		return
+	}
+	target, err := os.Readlink(a.Path)
+	if err != nil {
+		h.add(StatusFail, "reading %s: %v", a.Path, err)
+		return
+	}
+	if target != a.Src {
+		h.add(StatusFail, "%s points at %s, not %s", a.Path, target, a.Src)
+		return
+	}
+	if _, err := os.Stat(a.Path); err != nil {
+		h.add(StatusFail, "%s points at %s, which doesn't exist", a.Path, target)
+		return
+	}
+	h.add(StatusPass, "%s -> %s", a.Path, filepath.Base(target))
+}
+
+// checkGitInclude reports whether the global gitconfig includes
+// active.gitconfig, and warns about per-theme includes left over from
+// older installs.
+func checkGitInclude(h *AppHealth, home string) {
+	path := globalGitConfig(home)
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		h.add(StatusFail, "reading %s: %v", path, err)
+		return
+	}
+	includes := gitIncludePaths(data)
+	if containsPath(includes, home, activeDeltaPath(home)) {
+		h.add(StatusPass, "%s includes %s", path, filepath.Base(activeDeltaPath(home)))
+	} else {
+		h.add(StatusFail, "%s doesn't include %s; run install", path, activeDeltaPath(home))
+	}
+	for _, p := range includes {
+		if isStaleDeltaInclude(home, expandHome(home, p)) {
+			h.add(StatusWarn, "%s still includes %s; run install to migrate it", path, p)
+		}
+	}
+}
+
+// checkBatCache reports whether bat --list-themes offers the theme, which
+// it only does once the cache is rebuilt after install.
+func checkBatCache(h *AppHealth, t Theme) {
+	batPath, err := exec.LookPath("bat")
+	if err != nil {
+		return // reported by checkBinaries
+	}
+	file, err := firstFile(filepath.Join(t.Dir, "bat"))
+	if err != nil || file == "" {
+		return
+	}
+	name := strings.TrimSuffix(file, ".tmTheme")
+
+	out, err := exec.Command(batPath, "--list-themes", "--color=never").Output()
+	if err != nil {
+		h.add(StatusWarn, "bat --list-themes failed: %v", err)
+		return
+	}
+	for _, line := range strings.Split(string(out), "\n") {
+		if strings.TrimSpace(line) == name {
+			h.add(StatusPass, "bat cache has %s", name)
+			return
+		}
+	}
+	h.add(StatusFail, "bat cache doesn't have %s; run \"bat cache --build\"", name)
+}
+
+// checkBinaries warns when none of the app's programs is on PATH. The
+// macOS Ghostty app isn't on PATH as a rule, so it isn't checked there.
+func checkBinaries(h *AppHealth) {
+	bins := appBinaries[h.App]
+	if len(bins) == 0 || (h.App == "ghostty" && runtime.GOOS == "darwin") {
+		return
+	}
+	for _, b := range bins {
+		if _, err := exec.LookPath(b); err == nil {
+			h.add(StatusPass, "%s on PATH", b)
+			return
+		}
+	}
+	h.add(StatusWarn, "%s not on PATH", strings.Join(bins, " or "))
+}
+
+// fileExists reports whether something exists at path.
+func fileExists(path string) bool {
+	_, err := os.Lstat(path)
+	return err == nil
+}
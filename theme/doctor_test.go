@@ -0,0 +1,75 @@
+package theme
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDoctor(t *testing.T) {
+	themesDir, themeDir := setupThemeDir(t, []string{"ghostty", "fzf", "delta"}, minimalPaletteTOML)
+	writeFile(t, filepath.Join(themeDir, "ghostty", "test-theme.ghostty"), "ghostty config")
+	writeFile(t, filepath.Join(themeDir, "fzf", "test-theme.zsh"), "fzf config")
+	writeFile(t, filepath.Join(themeDir, "delta", "test-theme.gitconfig"), "[delta \"test-theme\"]\n")
+
+	home := t.TempDir()
+	th, err := LoadTheme(themesDir, "test-theme")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range Install(th, InstallOpts{HomeDir: home}) {
+		if r.Err != nil {
+			t.Fatalf("install %s: %v", r.App, r.Err)
+		}
+	}
+	checkNoErrors(t, Switch(th, SwitchOpts{HomeDir: home}))
+
+	status := func() map[string]AppHealth {
+		t.Helper()
+		report, err := Doctor(th, DoctorOpts{HomeDir: home})
+		if err != nil {
+			t.Fatalf("Doctor: %v", err)
+		}
+		byApp := map[string]AppHealth{}
+		for _, h := range report {
+			byApp[h.App] = h
+		}
+		return byApp
+	}
+
+	// Every check passes; only a program missing from PATH may warn.
+	healthy := status()
+	for _, app := range []string{"ghostty", "fzf", "delta"} {
+		h := healthy[app]
+		if len(h.Findings) == 0 {
+			t.Errorf("%s after install and switch: no findings", app)
+		}
+		for _, f := range h.Findings {
+			missingBinary := f.Status == StatusWarn && strings.HasSuffix(f.Message, " not on PATH")
+			if f.Status != StatusPass && !missingBinary {
+				t.Errorf("%s after install and switch: %s %s", app, f.Status, f.Message)
+			}
+		}
+	}
+	if h := healthy["starship"]; h.Status != StatusSkip {
+		t.Errorf("starship: got %+v, want skip", h)
+	}
+
+	config := filepath.Join(home, ".config")
+	writeFile(t, filepath.Join(config, "ghostty", "themes", "test-theme.ghostty"), "edited")
+	if err := os.Remove(filepath.Join(config, "the-themer", "fzf", "current.zsh")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("elsewhere.zsh", filepath.Join(config, "the-themer", "fzf", "current.zsh")); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, filepath.Join(home, ".gitconfig"), "[user]\n\tname = me\n")
+
+	broken := status()
+	for _, app := range []string{"ghostty", "fzf", "delta"} {
+		if h := broken[app]; h.Status != StatusFail {
+			t.Errorf("%s after breaking it: got %+v, want fail", app, h)
+		}
+	}
+}
@@ -0,0 +1,165 @@
+package theme
+
+import (
+	"fmt"
+	"strings"
+)
+
+// gh-dash's theme lives under theme.colors in ~/.config/gh-dash/config.yml.
+// Switch replaces just that block, line by line, so everything else in the
+// user's config — sections, keybindings, theme.ui, comments — is kept
+// byte for byte.
+
+// mergeGhDashTheme returns config with its theme.colors block replaced by
+// the one in themeFile. themeFile may be a bare theme block, as the
+// gh-dash adapter generates, or a full gh-dash config. A config without a
+// theme block gets one appended.
+func mergeGhDashTheme(config, themeFile []byte) ([]byte, error) {
+	src := splitYAMLLines(string(themeFile))
+	theme, ok := findYAMLKey(src, 0, len(src), 0, "theme")
+	if !ok {
+		return nil, fmt.Errorf("no theme block in gh-dash theme file")
+	}
+	themeEnd := yamlBlockEnd(src, theme, 0)
+	srcIndent := childIndent(src, theme, themeEnd)
+	colors, ok := findYAMLKey(src, theme+1, themeEnd, srcIndent, "colors")
+	if !ok || srcIndent == 0 {
+		return nil, fmt.Errorf("no theme.colors block in gh-dash theme file")
+	}
+	block := src[colors:yamlBlockEnd(src, colors, srcIndent)]
+
+	lines := splitYAMLLines(string(config))
+	dest, ok := findYAMLKey(lines, 0, len(lines), 0, "theme")
+	if !ok {
+		// Append a theme block, after a newline if the file lacks one.
+		if n := len(lines); n > 0 && !strings.HasSuffix(lines[n-1], "\n") {
+			lines[n-1] += "\n"
+		}
+		lines = append(lines, "theme:\n")
+		lines = append(lines, reindent(block, srcIndent, srcIndent)...)
+		return []byte(strings.Join(lines, "")), nil
+	}
+
+	destEnd := yamlBlockEnd(lines, dest, 0)
+	indent := childIndent(lines, dest, destEnd)
+	if indent == 0 {
+		indent = srcIndent
+	}
+	merged := reindent(block, srcIndent, indent)
+
+	var out []string
+	switch colors, ok := findYAMLKey(lines, dest+1, destEnd, indent, "colors"); {
+	case hasInlineValue(lines[dest]):
+		// theme: {} or similar: rewrite it as a block.
+		out = append(out, lines[:dest]...)
+		out = append(out, "theme:\n")
+		out = append(out, merged...)
+		out = append(out, lines[dest+1:]...)
+	case ok:
+		out = append(out, lines[:colors]...)
+		out = append(out, merged...)
+		out = append(out, lines[yamlBlockEnd(lines, colors, indent):]...)
+	default:
+		out = append(out, lines[:dest+1]...)
+		out = append(out, merged...)
+		out = append(out, lines[dest+1:]...)
+	}
+	return []byte(strings.Join(out, "")), nil
+}
+
+// splitYAMLLines splits s into lines that keep their "\n".
+func splitYAMLLines(s string) []string {
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// indentOf returns the number of leading spaces in line.
+func indentOf(line string) int {
+	return len(line) - len(strings.TrimLeft(line, " "))
+}
+
+// isYAMLContent reports whether line holds YAML rather than being blank or
+// only a comment.
+func isYAMLContent(line string) bool {
+	trimmed := strings.TrimSpace(line)
+	return trimmed != "" && !strings.HasPrefix(trimmed, "#")
+}
+
+// findYAMLKey returns the index of the line in lines[from:to] that opens
+// the mapping key at exactly the given indentation.
+func findYAMLKey(lines []string, from, to, indent int, key string) (int, bool) {
+	for i := from; i < to; i++ {
+		line := lines[i]
+		if !isYAMLContent(line) || indentOf(line) != indent {
+			continue
+		}
+		rest, ok := strings.CutPrefix(line[indent:], key)
+		if !ok {
+			continue
+		}
+		rest = strings.TrimLeft(rest, " ")
+		if strings.HasPrefix(rest, ":") {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// yamlBlockEnd returns the index just past the block opened at lines[key],
+// whose key sits at the given indentation: the block runs until the next
+// content line indented no deeper. Blank and comment lines at its tail that
+// aren't indented into the block belong to whatever follows.
+func yamlBlockEnd(lines []string, key, indent int) int {
+	end := key + 1
+	for i := key + 1; i < len(lines); i++ {
+		line := lines[i]
+		if isYAMLContent(line) {
+			if indentOf(line) <= indent {
+				break
+			}
+			end = i + 1
+			continue
+		}
+		if strings.TrimSpace(line) != "" && indentOf(line) > indent {
+			end = i + 1
+		}
+	}
+	return end
+}
+
+// childIndent returns the indentation of the first content line inside the
+// block lines[key+1:end], or 0 if the block is empty.
+func childIndent(lines []string, key, end int) int {
+	for _, line := range lines[key+1 : end] {
+		if isYAMLContent(line) {
+			return indentOf(line)
+		}
+	}
+	return 0
+}
+
+// hasInlineValue reports whether a "key:" line carries its value inline
+// (theme: {}) rather than opening a block.
+func hasInlineValue(line string) bool {
+	_, value, _ := strings.Cut(line, ":")
+	value = strings.TrimSpace(value)
+	return value != "" && !strings.HasPrefix(value, "#")
+}
+
+// reindent rescales block's indentation from steps of from spaces to steps
+// of to spaces.
+func reindent(block []string, from, to int) []string {
+	out := make([]string, len(block))
+	for i, line := range block {
+		n := indentOf(line)
+		if strings.TrimSpace(line) == "" || from == to {
+			out[i] = line
+			continue
+		}
+		out[i] = strings.Repeat(" ", n/from*to+n%from) + line[n:]
+	}
+	return out
+}
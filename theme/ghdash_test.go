@@ -0,0 +1,182 @@
+package theme
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// ghDashThemeYAML is a gh-dash theme file as the adapter generates it.
+const ghDashThemeYAML = `# test-theme theme for gh-dash
+theme:
+    colors:
+        text:
+            primary: "#0000aa"
+        border:
+            faint: "#222222"
+`
+
+func TestMergeGhDashTheme(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+		want   string
+	}{
+		{
+			name: "replaces colors, keeps the rest",
+			config: `# my gh-dash config
+prSections:
+  - title: Mine # inline comment
+    filters: is:open author:@me
+theme:
+  # colors below are managed
+  colors:
+    text:
+      primary: "#ffffff"
+      # a stale note
+    background:
+      selected: "#333333"
+
+  # keep my ui tweaks
+  ui:
+    table:
+      compact: true
+keybindings: {}
+`,
+			want: `# my gh-dash config
+prSections:
+  - title: Mine # inline comment
+    filters: is:open author:@me
+theme:
+  # colors below are managed
+  colors:
+    text:
+      primary: "#0000aa"
+    border:
+      faint: "#222222"
+
+  # keep my ui tweaks
+  ui:
+    table:
+      compact: true
+keybindings: {}
+`,
+		},
+		{
+			name: "theme without colors",
+			config: `theme:
+    ui:
+        sectionsShowCount: false
+`,
+			want: `theme:
+    colors:
+        text:
+            primary: "#0000aa"
+        border:
+            faint: "#222222"
+    ui:
+        sectionsShowCount: false
+`,
+		},
+		{
+			name:   "inline theme",
+			config: "theme: {}\nconfirmQuit: true\n",
+			want: `theme:
+    colors:
+        text:
+            primary: "#0000aa"
+        border:
+            faint: "#222222"
+confirmQuit: true
+`,
+		},
+		{
+			name:   "no theme",
+			config: "confirmQuit: true",
+			want: `confirmQuit: true
+theme:
+    colors:
+        text:
+            primary: "#0000aa"
+        border:
+            faint: "#222222"
+`,
+		},
+		{
+			name:   "no config",
+			config: "",
+			want: `theme:
+    colors:
+        text:
+            primary: "#0000aa"
+        border:
+            faint: "#222222"
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := mergeGhDashTheme([]byte(tt.config), []byte(ghDashThemeYAML))
+			if err != nil {
+				t.Fatalf("mergeGhDashTheme: %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("got:\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMergeGhDashTheme_NoColors(t *testing.T) {
+	if _, err := mergeGhDashTheme(nil, []byte("prSections: []\n")); err == nil {
+		t.Error("theme file without a theme block: want error")
+	}
+	if _, err := mergeGhDashTheme(nil, []byte("theme:\n  ui: {}\n")); err == nil {
+		t.Error("theme file without theme.colors: want error")
+	}
+}
+
+func TestSwitch_GhDashMergesTheme(t *testing.T) {
+	themesDir, themeDir := setupThemeDir(t, []string{"gh-dash"}, minimalPaletteTOML)
+	writeFile(t, filepath.Join(themeDir, "gh-dash", "cobalt2.yml"), ghDashThemeYAML)
+	th, err := LoadTheme(themesDir, "test-theme")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	home := t.TempDir()
+	Install(th, InstallOpts{HomeDir: home})
+	config := filepath.Join(home, ".config", "gh-dash", "config.yml")
+	writeFile(t, config, "# mine\nprSections: []\n")
+
+	checkNoErrors(t, Switch(th, SwitchOpts{HomeDir: home}))
+	assertFileContains(t, config, "# mine\nprSections: []\ntheme:\n    colors:\n")
+	assertFileContains(t, config, `primary: "#0000aa"`)
+}
+
+func TestSwitch_GhDashSymlinkedConfig(t *testing.T) {
+	themesDir, themeDir := setupThemeDir(t, []string{"gh-dash"}, minimalPaletteTOML)
+	writeFile(t, filepath.Join(themeDir, "gh-dash", "cobalt2.yml"), ghDashThemeYAML)
+	th, err := LoadTheme(themesDir, "test-theme")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	home := t.TempDir()
+	dotfile := filepath.Join(t.TempDir(), "gh-dash.yml")
+	writeFile(t, dotfile, "# mine\nprSections: []\n")
+	config := filepath.Join(home, ".config", "gh-dash", "config.yml")
+	if err := os.MkdirAll(filepath.Dir(config), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(dotfile, config); err != nil {
+		t.Fatal(err)
+	}
+
+	checkNoErrors(t, Switch(th, SwitchOpts{HomeDir: home}))
+	if target, err := os.Readlink(config); err != nil || target != dotfile {
+		t.Errorf("config.yml -> %q, %v; want the link into dotfiles kept", target, err)
+	}
+	assertFileContains(t, dotfile, "# mine\nprSections: []\ntheme:\n    colors:\n")
+	assertFileContains(t, dotfile, `primary: "#0000aa"`)
+}
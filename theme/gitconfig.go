@@ -0,0 +1,308 @@
+package theme
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Delta themes reach git through one managed include,
+// ~/.config/the-themer/delta/active.gitconfig, which switch rewrites to
+// include the active theme's gitconfig and select its delta feature. The
+// include comes last in the global gitconfig, so its delta.features wins;
+// switch copies the user's own features into it ahead of the theme's.
+// Install adds that include to the global gitconfig once; older installs
+// added an include.path per theme, which install migrates away.
+//
+// The global gitconfig is edited here rather than through git config, so
+// install works without git and tests can run against a temp HOME. Edits
+// are line-based and only touch include.path lines in plain [include]
+// sections; everything else in the file is kept byte for byte.
+
+// deltaDir returns the directory install copies delta gitconfigs into.
+func deltaDir(home string) string {
+	return filepath.Join(stateDir(home), "delta")
+}
+
+// activeDeltaPath returns the managed include file switch rewrites.
+func activeDeltaPath(home string) string {
+	return filepath.Join(deltaDir(home), "active.gitconfig")
+}
+
+// globalGitConfig returns the file git config --global uses:
+// $GIT_CONFIG_GLOBAL if set, else ~/.gitconfig unless only
+// $XDG_CONFIG_HOME/git/config exists.
+func globalGitConfig(home string) string {
+	if p := os.Getenv("GIT_CONFIG_GLOBAL"); p != "" {
+		return p
+	}
+	dotfile := filepath.Join(home, ".gitconfig")
+	if _, err := os.Stat(dotfile); err == nil {
+		return dotfile
+	}
+	xdg := filepath.Join(configHome(home), "git", "config")
+	if _, err := os.Stat(xdg); err == nil {
+		return xdg
+	}
+	return dotfile
+}
+
+// activeDeltaConfig returns the contents of active.gitconfig for a delta
+// feature. includeFile is the theme's gitconfig, relative to
+// active.gitconfig, or "" for a feature the user defines themselves.
+// userFeatures is the delta.features value from the global gitconfig, kept
+// ahead of the theme's feature so side-by-side and the like survive.
+func activeDeltaConfig(feature, includeFile, userFeatures string) []byte {
+	features := []string{}
+	for _, f := range strings.Fields(userFeatures) {
+		if f != feature {
+			features = append(features, f)
+		}
+	}
+	features = append(features, feature)
+
+	var b strings.Builder
+	b.WriteString("; Managed by the-themer: rewritten by \"the-themer switch\".\n")
+	if includeFile != "" {
+		b.WriteString("[include]\n\tpath = " + quoteGitValue(includeFile) + "\n")
+	}
+	b.WriteString("[delta]\n\tfeatures = " + quoteGitValue(strings.Join(features, " ")) + "\n")
+	return []byte(b.String())
+}
+
+// userDeltaFeatures returns the last delta.features value set directly in
+// the global gitconfig, or "" if there is none.
+func userDeltaFeatures(home string) (string, error) {
+	path := globalGitConfig(home)
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return "", fmt.Errorf("reading %s: %w", path, err)
+	}
+	return gitConfigValue(data, "delta", "features"), nil
+}
+
+// planGitIncludes plans the global gitconfig edit that removes every
+// include.path drop reports true for and adds add (unless add is "" or
+// already included). Paths are passed to drop with ~/ expanded. It returns
+// nil and the number of removed includes when the file needs no change.
+func planGitIncludes(home string, drop func(path string) bool, add string) (*Action, int, error) {
+	path := globalGitConfig(home)
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, 0, fmt.Errorf("reading %s: %w", path, err)
+	}
+
+	expandedDrop := func(p string) bool { return drop(expandHome(home, p)) }
+	edited, removed := editGitIncludes(data, expandedDrop, "")
+	if add != "" && !containsPath(gitIncludePaths(edited), home, add) {
+		edited, _ = editGitIncludes(edited, func(string) bool { return false }, add)
+	}
+	if string(edited) == string(data) {
+		return nil, 0, nil
+	}
+	// A gitconfig linked into the user's dotfiles is edited at its target,
+	// so the link stays and the temp file lands on the target's filesystem.
+	target, err := linkTarget(path)
+	if err != nil {
+		return nil, 0, err
+	}
+	return &Action{Kind: ActionWrite, Path: target, Content: edited, Atomic: true}, removed, nil
+}
+
+// containsPath reports whether paths holds want once ~/ is expanded.
+func containsPath(paths []string, home, want string) bool {
+	for _, p := range paths {
+		if filepath.Clean(expandHome(home, p)) == filepath.Clean(want) {
+			return true
+		}
+	}
+	return false
+}
+
+// expandHome expands a leading ~/ the way git does for include paths.
+func expandHome(home, path string) string {
+	if rest, ok := strings.CutPrefix(path, "~/"); ok {
+		return filepath.Join(home, rest)
+	}
+	return path
+}
+
+// gitIncludePaths returns the include.path values in a gitconfig, in order.
+func gitIncludePaths(data []byte) []string {
+	var paths []string
+	section := ""
+	for _, line := range splitGitLines(data) {
+		if name, ok := gitSectionHeader(line); ok {
+			section = name
+			continue
+		}
+		if section != "include" {
+			continue
+		}
+		if key, value, ok := gitKeyValue(line); ok && key == "path" {
+			paths = append(paths, value)
+		}
+	}
+	return paths
+}
+
+// gitConfigValue returns the last value of key in the plain section named
+// section, or "" if it isn't set. Included files aren't followed.
+func gitConfigValue(data []byte, section, key string) string {
+	value := ""
+	current := ""
+	for _, line := range splitGitLines(data) {
+		if name, ok := gitSectionHeader(line); ok {
+			current = name
+			continue
+		}
+		if current != section {
+			continue
+		}
+		if k, v, ok := gitKeyValue(line); ok && k == key {
+			value = v
+		}
+	}
+	return value
+}
+
+// editGitIncludes returns data with every include.path that drop reports
+// true for removed, and add appended in a new [include] section if add
+// isn't "". An [include] section left with no entries loses its header
+// too. It also returns the number of include.path lines removed.
+func editGitIncludes(data []byte, drop func(path string) bool, add string) ([]byte, int) {
+	var out []string
+	removed := 0
+	section := ""
+	header := -1       // index in out of the current [include] header
+	kept, gone := 0, 0 // entries kept and dropped in that section
+
+	closeSection := func() {
+		if header >= 0 && gone > 0 && kept == 0 {
+			out = append(out[:header], out[header+1:]...)
+		}
+		header, kept, gone = -1, 0, 0
+	}
+
+	for _, line := range splitGitLines(data) {
+		if name, ok := gitSectionHeader(line); ok {
+			closeSection()
+			section = name
+			if section == "include" {
+				header = len(out)
+			}
+			out = append(out, line)
+			continue
+		}
+		if section == "include" {
+			if key, value, ok := gitKeyValue(line); ok {
+				if key == "path" && drop(value) {
+					gone++
+					removed++
+					continue
+				}
+				kept++
+			}
+		}
+		out = append(out, line)
+	}
+	closeSection()
+
+	if add != "" {
+		if n := len(out); n > 0 && !strings.HasSuffix(out[n-1], "\n") {
+			out[n-1] += "\n"
+		}
+		out = append(out, "[include]\n", "\tpath = "+quoteGitValue(add)+"\n")
+	}
+	return []byte(strings.Join(out, "")), removed
+}
+
+// splitGitLines splits data into lines that keep their "\n".
+func splitGitLines(data []byte) []string {
+	if len(data) == 0 {
+		return nil
+	}
+	lines := strings.SplitAfter(string(data), "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// gitSectionHeader returns the lowercased name of the section a header
+// line opens. Subsections ([includeIf "gitdir:..."], [delta "name"]) are
+// returned with their subsection, so they never read as plain "include".
+func gitSectionHeader(line string) (string, bool) {
+	trimmed := strings.TrimSpace(line)
+	if !strings.HasPrefix(trimmed, "[") {
+		return "", false
+	}
+	end := strings.Index(trimmed, "]")
+	if end < 0 {
+		return "", false
+	}
+	name := strings.TrimSpace(trimmed[1:end])
+	if section, sub, ok := strings.Cut(name, " "); ok {
+		return strings.ToLower(section) + " " + strings.TrimSpace(sub), true
+	}
+	// A key after the header on the same line isn't read as an entry; such
+	// sections are left as they are.
+	if strings.TrimSpace(trimmed[end+1:]) != "" {
+		return strings.ToLower(name) + " (inline)", true
+	}
+	return strings.ToLower(name), true
+}
+
+// gitKeyValue parses a "key = value" line, lowercasing the key and
+// unquoting the value. Comments and blank lines report false.
+func gitKeyValue(line string) (string, string, bool) {
+	trimmed := strings.TrimSpace(line)
+	if trimmed == "" || trimmed[0] == '#' || trimmed[0] == ';' {
+		return "", "", false
+	}
+	key, value, ok := strings.Cut(trimmed, "=")
+	if !ok {
+		return strings.ToLower(trimmed), "", true // boolean shorthand
+	}
+	return strings.ToLower(strings.TrimSpace(key)), unquoteGitValue(value), true
+}
+
+// unquoteGitValue reads a gitconfig value: quotes group, backslash escapes
+// \" \\ \n \t, and an unquoted # or ; starts a comment.
+func unquoteGitValue(raw string) string {
+	var b strings.Builder
+	quoted := false
+	for i := 0; i < len(raw); i++ {
+		c := raw[i]
+		switch {
+		case c == '\\' && i+1 < len(raw):
+			i++
+			switch raw[i] {
+			case 'n':
+				b.WriteByte('\n')
+			case 't':
+				b.WriteByte('\t')
+			default:
+				b.WriteByte(raw[i])
+			}
+		case c == '"':
+			quoted = !quoted
+		case (c == '#' || c == ';') && !quoted:
+			return strings.TrimSpace(b.String())
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// quoteGitValue quotes a value if git would otherwise misread it.
+func quoteGitValue(v string) string {
+	if !strings.ContainsAny(v, " \t#;\"\\") {
+		return v
+	}
+	v = strings.ReplaceAll(v, `\`, `\\`)
+	v = strings.ReplaceAll(v, `"`, `\"`)
+	return `"` + v + `"`
+}
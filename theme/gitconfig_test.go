@@ -0,0 +1,195 @@
+package theme
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestEditGitIncludes(t *testing.T) {
+	drop := func(path string) bool { return filepath.Base(path) == "old.gitconfig" }
+
+	tests := []struct {
+		name    string
+		config  string
+		add     string
+		want    string
+		removed int
+	}{
+		{
+			name:   "empty file",
+			config: "",
+			add:    "/a/active.gitconfig",
+			want:   "[include]\n\tpath = /a/active.gitconfig\n",
+		},
+		{
+			name:    "drops the entry and its emptied section",
+			config:  "[user]\n\tname = me\n[include]\n\tpath = /a/old.gitconfig\n[core]\n\tpager = delta\n",
+			want:    "[user]\n\tname = me\n[core]\n\tpager = delta\n",
+			removed: 1,
+		},
+		{
+			name:    "keeps other entries in the section",
+			config:  "[include]\n\tpath = ~/work.gitconfig\n\tpath = \"/a/old.gitconfig\" ; theme\n",
+			want:    "[include]\n\tpath = ~/work.gitconfig\n",
+			removed: 1,
+		},
+		{
+			name:   "leaves includeIf alone",
+			config: "[includeIf \"gitdir:~/src/\"]\n\tpath = /a/old.gitconfig\n",
+			add:    "/a/active.gitconfig",
+			want:   "[includeIf \"gitdir:~/src/\"]\n\tpath = /a/old.gitconfig\n[include]\n\tpath = /a/active.gitconfig\n",
+		},
+		{
+			name:   "adds a newline before appending",
+			config: "[user]\n\tname = me",
+			add:    "/my files/active.gitconfig",
+			want:   "[user]\n\tname = me\n[include]\n\tpath = \"/my files/active.gitconfig\"\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, removed := editGitIncludes([]byte(tt.config), drop, tt.add)
+			if string(got) != tt.want {
+				t.Errorf("got:\n%s\nwant:\n%s", got, tt.want)
+			}
+			if removed != tt.removed {
+				t.Errorf("removed = %d, want %d", removed, tt.removed)
+			}
+		})
+	}
+}
+
+func TestGitIncludePaths(t *testing.T) {
+	config := `# comment
+[Include]
+	path = ~/a.gitconfig
+	path = "/with \"quotes\"/b.gitconfig" # comment
+[include "sub"]
+	path = /not/this
+[alias]
+	path = /nor/this
+`
+	got := gitIncludePaths([]byte(config))
+	want := []string{"~/a.gitconfig", `/with "quotes"/b.gitconfig`}
+	if !slices.Equal(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestInstall_DeltaMigratesIncludes(t *testing.T) {
+	themesDir, themeDir := setupThemeDir(t, []string{"delta"}, minimalPaletteTOML)
+	writeFile(t, filepath.Join(themeDir, "delta", "test-theme.gitconfig"), "[delta \"test-theme\"]\n")
+
+	home := t.TempDir()
+	gitconfig := filepath.Join(home, ".gitconfig")
+	writeFile(t, gitconfig, "[user]\n\tname = me\n"+
+		"[include]\n\tpath = ~/.config/the-themer/delta/old-a.gitconfig\n"+
+		"[include]\n\tpath = "+filepath.Join(deltaDir(home), "old-b.gitconfig")+"\n\tpath = ~/mine.gitconfig\n")
+
+	th, err := LoadTheme(themesDir, "test-theme")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		results := Install(th, InstallOpts{HomeDir: home})
+		for _, r := range results {
+			if r.Err != nil {
+				t.Fatalf("install %s: %v", r.App, r.Err)
+			}
+		}
+	}
+
+	data, err := os.ReadFile(gitconfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "[user]\n\tname = me\n[include]\n\tpath = ~/mine.gitconfig\n[include]\n\tpath = " + activeDeltaPath(home) + "\n"
+	if string(data) != want {
+		t.Errorf("gitconfig after two installs:\n%s\nwant:\n%s", data, want)
+	}
+}
+
+func TestSwitch_DeltaActiveGitconfig(t *testing.T) {
+	themesDir, themeDir := setupThemeDir(t, []string{"delta"}, minimalPaletteTOML)
+	writeFile(t, filepath.Join(themeDir, "delta", "test-theme.gitconfig"), "delta content")
+
+	home := t.TempDir()
+	th, err := LoadTheme(themesDir, "test-theme")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	checkNoErrors(t, Switch(th, SwitchOpts{HomeDir: home}))
+
+	data, err := os.ReadFile(activeDeltaPath(home))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := gitIncludePaths(data); !slices.Equal(got, []string{"test-theme.gitconfig"}) {
+		t.Errorf("active.gitconfig includes %q, want test-theme.gitconfig", got)
+	}
+	assertFileContains(t, activeDeltaPath(home), "features = test-theme")
+}
+
+func TestSwitch_DeltaKeepsUserFeatures(t *testing.T) {
+	themesDir, themeDir := setupThemeDir(t, []string{"delta"}, minimalPaletteTOML)
+	writeFile(t, filepath.Join(themeDir, "delta", "test-theme.gitconfig"), "delta content")
+
+	home := t.TempDir()
+	writeFile(t, filepath.Join(home, ".gitconfig"), "[delta]\n\tfeatures = side-by-side test-theme\n\tline-numbers = true\n"+
+		"[delta \"test-theme\"]\n\tfeatures = ignored\n[delta]\n\tfeatures = side-by-side line-numbers test-theme\n")
+	th, err := LoadTheme(themesDir, "test-theme")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	checkNoErrors(t, Switch(th, SwitchOpts{HomeDir: home}))
+
+	data, err := os.ReadFile(activeDeltaPath(home))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := gitConfigValue(data, "delta", "features"); got != "side-by-side line-numbers test-theme" {
+		t.Errorf("active.gitconfig features = %q, want the user's features then the theme", got)
+	}
+}
+
+func TestInstall_DeltaSymlinkedGitconfig(t *testing.T) {
+	themesDir, themeDir := setupThemeDir(t, []string{"delta"}, minimalPaletteTOML)
+	writeFile(t, filepath.Join(themeDir, "delta", "test-theme.gitconfig"), "[delta \"test-theme\"]\n")
+	th, err := LoadTheme(themesDir, "test-theme")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	home := t.TempDir()
+	dotfile := filepath.Join(t.TempDir(), "gitconfig")
+	writeFile(t, dotfile, "[user]\n\tname = me\n")
+	if err := os.Chmod(dotfile, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	gitconfig := filepath.Join(home, ".gitconfig")
+	if err := os.Symlink(dotfile, gitconfig); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, r := range Install(th, InstallOpts{HomeDir: home}) {
+		if r.Err != nil {
+			t.Fatalf("install %s: %v", r.App, r.Err)
+		}
+	}
+
+	if target, err := os.Readlink(gitconfig); err != nil || target != dotfile {
+		t.Errorf("~/.gitconfig -> %q, %v; want the link into dotfiles kept", target, err)
+	}
+	assertFileContains(t, dotfile, "[user]\n\tname = me\n[include]\n\tpath = "+activeDeltaPath(home)+"\n")
+	info, err := os.Stat(dotfile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0o600 {
+		t.Errorf("dotfile mode = %v, want 0600 kept", info.Mode().Perm())
+	}
+}
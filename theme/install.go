@@ -12,7 +12,8 @@ import (
 
 // InstallOpts configures the install operation.
 type InstallOpts struct {
-	HomeDir string // injectable for testing; defaults to os.UserHomeDir()
+	HomeDir string  // injectable for testing; defaults to os.UserHomeDir()
+	Config  *Config // destinations, disabled apps, reloads; nil for DefaultConfig()
 }
 
 // resolveHome returns opts.HomeDir if set, otherwise os.UserHomeDir().
@@ -34,15 +35,60 @@ type InstallResult struct {
 // Install deploys a theme's per-app configs to their filesystem destinations.
 // It iterates all known app handlers, skipping any that the theme doesn't
 // include. Errors are collected best-effort — every app is attempted.
+//
+// Files the themer didn't write itself are backed up before they're first
+// replaced (see RestoreBackup).
 func Install(t Theme, opts InstallOpts) []InstallResult {
+	plan, err := PlanInstall(t, opts)
+	if err != nil {
+		return []InstallResult{{App: "home", Err: err}}
+	}
 	home, err := opts.resolveHome()
 	if err != nil {
 		return []InstallResult{{App: "home", Err: fmt.Errorf("resolving home directory: %w", err)}}
 	}
 
+	var results []InstallResult
+	backup, err := backupUnmanaged(home, plan.Actions())
+	if err != nil {
+		return []InstallResult{{App: "backup", Err: err}}
+	}
+	if backup != nil {
+		results = append(results, InstallResult{App: "backup", Message: backup.String()})
+	}
+
+	var installed []Action
+	for _, p := range plan.Apps {
+		if p.Skipped {
+			results = append(results, InstallResult{App: p.App, Skipped: true, Message: p.Message})
+			continue
+		}
+		msg, err := p.Apply()
+		results = append(results, InstallResult{App: p.App, Message: msg, Err: err})
+		if err == nil {
+			installed = append(installed, p.Actions...)
+		}
+	}
+
+	if err := markManaged(home, installed); err != nil {
+		results = append(results, InstallResult{App: "backup", Err: err})
+	}
+	return results
+}
+
+// PlanInstall computes what Install would do without doing any of it.
+// Planning only reads: the installed files, git's include.path, bat's
+// config dir.
+func PlanInstall(t Theme, opts InstallOpts) (Plan, error) {
+	home, err := opts.resolveHome()
+	if err != nil {
+		return Plan{}, fmt.Errorf("resolving home directory: %w", err)
+	}
+
+	l := newLayout(home, opts.Config)
 	handlers := []struct {
-		app     string
-		install func(t Theme, home string) (string, error)
+		app  string
+		plan func(t Theme, l layout) (string, []Action, error)
 	}{
 		{"ghostty", installGhostty},
 		{"bat", installBat},
@@ -55,58 +101,71 @@ func Install(t Theme, opts InstallOpts) []InstallResult {
 		{"hud", installHud},
 	}
 
-	var results []InstallResult
+	var plan Plan
 	for _, h := range handlers {
+		if l.cfg.disabled(h.app) {
+			plan.Apps = append(plan.Apps, AppPlan{App: h.app, Skipped: true, Message: "disabled in config.toml"})
+			continue
+		}
 		appDir := filepath.Join(t.Dir, h.app)
 		if _, err := os.Stat(appDir); os.IsNotExist(err) {
-			results = append(results, InstallResult{App: h.app, Skipped: true, Message: "no config in theme"})
+			plan.Apps = append(plan.Apps, AppPlan{App: h.app, Skipped: true, Message: "no config in theme"})
 			continue
 		}
 
-		msg, err := h.install(t, home)
-		results = append(results, InstallResult{App: h.app, Message: msg, Err: err})
+		msg, actions, err := h.plan(t, l)
+		if err == nil {
+			actions, err = writeThrough(home, actions)
+		}
+		plan.Apps = append(plan.Apps, AppPlan{App: h.app, Message: msg, Actions: actions, Err: err})
 	}
-	return results
+	return plan, nil
 }
 
 // installGhostty copies theme files to ~/.config/ghostty/themes/.
-func installGhostty(t Theme, home string) (string, error) {
+func installGhostty(t Theme, l layout) (string, []Action, error) {
 	srcDir := filepath.Join(t.Dir, "ghostty")
-	destDir := filepath.Join(home, ".config", "ghostty", "themes")
+	destDir := l.dir("ghostty", "ghostty", "themes")
 	return copyDirContents(srcDir, destDir)
 }
 
 // installBat copies .tmTheme to bat's themes dir and rebuilds the cache.
-func installBat(t Theme, home string) (string, error) {
+func installBat(t Theme, l layout) (string, []Action, error) {
 	srcDir := filepath.Join(t.Dir, "bat")
 
 	// bat stores themes in $(bat --config-dir)/themes/
-	destDir, err := batThemesDir(home)
+	destDir, err := batThemesDir(l)
 	if err != nil {
-		return "", err
+		return "", nil, err
 	}
 
-	msg, err := copyDirContents(srcDir, destDir)
+	msg, actions, err := copyDirContents(srcDir, destDir)
 	if err != nil {
-		return msg, err
+		return msg, nil, err
 	}
 
 	// Rebuild bat cache so the new theme is available.
+	if !l.cfg.Reload.BatCache {
+		return msg, actions, nil
+	}
 	if batPath, lookErr := exec.LookPath("bat"); lookErr == nil {
-		cmd := exec.Command(batPath, "cache", "--build")
-		if out, runErr := cmd.CombinedOutput(); runErr != nil {
-			return msg, fmt.Errorf("bat cache --build failed: %s: %w", strings.TrimSpace(string(out)), runErr)
-		}
-		msg += "; bat cache rebuilt"
+		actions = append(actions, Action{
+			Kind: ActionCommand,
+			Args: []string{batPath, "cache", "--build"},
+			Note: "bat cache rebuilt",
+		})
 	} else {
 		msg += "; bat not on PATH, skipped cache rebuild"
 	}
-	return msg, nil
+	return msg, actions, nil
 }
 
-// batThemesDir returns the bat themes directory. Tries `bat --config-dir`
-// first, falls back to ~/.config/bat/themes/.
-func batThemesDir(home string) (string, error) {
+// batThemesDir returns the bat themes directory: the configured one, else
+// $(bat --config-dir)/themes, falling back to ~/.config/bat/themes/.
+func batThemesDir(l layout) (string, error) {
+	if d := l.cfg.Apps["bat"].Dir; d != "" {
+		return d, nil
+	}
 	if batPath, err := exec.LookPath("bat"); err == nil {
 		cmd := exec.Command(batPath, "--config-dir")
 		out, err := cmd.Output()
@@ -114,118 +173,110 @@ func batThemesDir(home string) (string, error) {
 			return filepath.Join(strings.TrimSpace(string(out)), "themes"), nil
 		}
 	}
-	return filepath.Join(home, ".config", "bat", "themes"), nil
+	return l.dir("bat", "bat", "themes"), nil
 }
 
-// installDelta copies gitconfig to ~/.config/the-themer/delta/ and adds
-// an include.path entry to global git config if not already present.
-func installDelta(t Theme, home string) (string, error) {
+// installDelta copies gitconfig to ~/.config/the-themer/delta/ and makes
+// sure the global gitconfig includes active.gitconfig, replacing any
+// per-theme or pre-XDG include.path entries earlier installs added.
+func installDelta(t Theme, l layout) (string, []Action, error) {
 	srcDir := filepath.Join(t.Dir, "delta")
-	destDir := filepath.Join(home, ".config", "the-themer", "delta")
+	destDir := deltaDir(l.home)
 
-	msg, err := copyDirContents(srcDir, destDir)
+	msg, actions, err := copyDirContents(srcDir, destDir)
 	if err != nil {
-		return msg, err
+		return msg, nil, err
 	}
 
-	// Add include.path to global git config for each installed file.
-	entries, err := os.ReadDir(srcDir)
+	active := activeDeltaPath(l.home)
+	edit, migrated, err := planGitIncludes(l.home, func(path string) bool {
+		return isStaleDeltaInclude(l.home, path)
+	}, active)
 	if err != nil {
-		return msg, err
+		return msg, nil, fmt.Errorf("configuring git include.path: %w", err)
 	}
-
-	for _, e := range entries {
-		if e.IsDir() {
-			continue
-		}
-		destPath := filepath.Join(destDir, e.Name())
-		if err := gitAddIncludePath(destPath); err != nil {
-			return msg, fmt.Errorf("adding git include.path for %s: %w", e.Name(), err)
-		}
+	switch {
+	case edit == nil:
+		msg += "; git include.path configured"
+	case migrated > 0:
+		msg += fmt.Sprintf("; replaced %d old git include(s) with %s", migrated, filepath.Base(active))
+	default:
+		msg += fmt.Sprintf("; git include.path -> %s", filepath.Base(active))
 	}
-	msg += "; git include.path configured"
-	return msg, nil
-}
-
-// gitAddIncludePath adds path to git's global include.path if not already present.
-func gitAddIncludePath(path string) error {
-	gitPath, err := exec.LookPath("git")
-	if err != nil {
-		return fmt.Errorf("git not found: %w", err)
+	if edit != nil {
+		actions = append(actions, *edit)
 	}
+	return msg, actions, nil
+}
 
-	// Check existing include paths.
-	cmd := exec.Command(gitPath, "config", "--global", "--get-all", "include.path")
-	out, _ := cmd.Output() // exit 1 = no entries, that's fine
-	for _, line := range strings.Split(string(out), "\n") {
-		if strings.TrimSpace(line) == path {
-			return nil // already present
-		}
+// isStaleDeltaInclude reports whether an include.path points at a theme's
+// gitconfig in the delta directory rather than at active.gitconfig, or at
+// any gitconfig in the delta directory's pre-XDG_CONFIG_HOME location.
+func isStaleDeltaInclude(home, path string) bool {
+	path = filepath.Clean(path)
+	if !strings.HasSuffix(path, ".gitconfig") {
+		return false
 	}
-
-	addCmd := exec.Command(gitPath, "config", "--global", "--add", "include.path", path)
-	if out, err := addCmd.CombinedOutput(); err != nil {
-		return fmt.Errorf("%s: %w", strings.TrimSpace(string(out)), err)
+	if legacy := filepath.Join(legacyStateDir(home), "delta"); legacy != deltaDir(home) && filepath.Dir(path) == legacy {
+		return true
 	}
-	return nil
+	return filepath.Dir(path) == deltaDir(home) && path != activeDeltaPath(home)
 }
 
 // installFzf copies fzf config to ~/.config/the-themer/fzf/.
-func installFzf(t Theme, home string) (string, error) {
+func installFzf(t Theme, l layout) (string, []Action, error) {
 	srcDir := filepath.Join(t.Dir, "fzf")
-	destDir := filepath.Join(home, ".config", "the-themer", "fzf")
+	destDir := filepath.Join(stateDir(l.home), "fzf")
 	return copyDirContents(srcDir, destDir)
 }
 
 // installTCM copies the generated theme JSON to ~/.config/tcm/.
 // The matching switch handler atomically writes active-theme.json from the
 // installed file, which tcm watches via fs.watch and reloads on rename.
-func installTCM(t Theme, home string) (string, error) {
+func installTCM(t Theme, l layout) (string, []Action, error) {
 	srcDir := filepath.Join(t.Dir, "tcm")
-	destDir := filepath.Join(home, ".config", "tcm")
+	destDir := l.dir("tcm", "tcm")
 	return copyDirContents(srcDir, destDir)
 }
 
 // installStarship copies starship config to ~/.config/the-themer/starship/.
-func installStarship(t Theme, home string) (string, error) {
+func installStarship(t Theme, l layout) (string, []Action, error) {
 	srcDir := filepath.Join(t.Dir, "starship")
-	destDir := filepath.Join(home, ".config", "the-themer", "starship")
+	destDir := filepath.Join(stateDir(l.home), "starship")
 	return copyDirContents(srcDir, destDir)
 }
 
 // installEza copies eza theme to ~/.config/eza/themes/.
-func installEza(t Theme, home string) (string, error) {
+func installEza(t Theme, l layout) (string, []Action, error) {
 	srcDir := filepath.Join(t.Dir, "eza")
-	destDir := filepath.Join(home, ".config", "eza", "themes")
+	destDir := l.dir("eza", "eza", "themes")
 	return copyDirContents(srcDir, destDir)
 }
 
 // installHud copies the tail-claude-hud widget-color TOML to ~/.config/the-themer/hud/.
-func installHud(t Theme, home string) (string, error) {
+func installHud(t Theme, l layout) (string, []Action, error) {
 	srcDir := filepath.Join(t.Dir, "hud")
-	destDir := filepath.Join(home, ".config", "the-themer", "hud")
+	destDir := filepath.Join(stateDir(l.home), "hud")
 	return copyDirContents(srcDir, destDir)
 }
 
 // installGhDash copies gh-dash config to ~/.config/the-themer/gh-dash/.
-func installGhDash(t Theme, home string) (string, error) {
+func installGhDash(t Theme, l layout) (string, []Action, error) {
 	srcDir := filepath.Join(t.Dir, "gh-dash")
-	destDir := filepath.Join(home, ".config", "the-themer", "gh-dash")
+	destDir := filepath.Join(stateDir(l.home), "gh-dash")
 	return copyDirContents(srcDir, destDir)
 }
 
-// copyDirContents copies all files from src to dest, creating dest if needed.
-// Returns a summary message listing copied files.
-func copyDirContents(srcDir, destDir string) (string, error) {
-	if err := os.MkdirAll(destDir, 0o755); err != nil {
-		return "", fmt.Errorf("creating %s: %w", destDir, err)
-	}
-
+// copyDirContents plans copying all files from src to dest. Files already
+// identical at dest are left alone. Returns a summary message listing the
+// copied files.
+func copyDirContents(srcDir, destDir string) (string, []Action, error) {
 	entries, err := os.ReadDir(srcDir)
 	if err != nil {
-		return "", fmt.Errorf("reading %s: %w", srcDir, err)
+		return "", nil, fmt.Errorf("reading %s: %w", srcDir, err)
 	}
 
+	var actions []Action
 	var copied, overwrote, unchanged []string
 	for _, e := range entries {
 		if e.IsDir() {
@@ -240,21 +291,15 @@ func copyDirContents(srcDir, destDir string) (string, error) {
 				continue
 			}
 			// File exists but differs — overwrite it.
-			if err := copyFile(src, dest); err != nil {
-				return "", fmt.Errorf("copying %s: %w", e.Name(), err)
-			}
 			overwrote = append(overwrote, e.Name())
-			continue
-		}
-
-		if err := copyFile(src, dest); err != nil {
-			return "", fmt.Errorf("copying %s: %w", e.Name(), err)
+		} else {
+			copied = append(copied, e.Name())
 		}
-		copied = append(copied, e.Name())
+		actions = append(actions, Action{Kind: ActionCopy, Src: src, Path: dest})
 	}
 
 	if len(copied) == 0 && len(overwrote) == 0 && len(unchanged) > 0 {
-		return fmt.Sprintf("unchanged in %s", destDir), nil
+		return fmt.Sprintf("unchanged in %s", destDir), nil, nil
 	}
 
 	// Build message from whichever lists have entries.
@@ -269,7 +314,7 @@ func copyDirContents(srcDir, destDir string) (string, error) {
 	if len(unchanged) > 0 {
 		msg += fmt.Sprintf(" (unchanged: %s)", strings.Join(unchanged, ", "))
 	}
-	return msg, nil
+	return msg, actions, nil
 }
 
 // copyFile copies a single file from src to dest, preserving permissions.
@@ -0,0 +1,325 @@
+package theme
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/kylesnowschwartz/the-themer/diff"
+)
+
+// ActionKind identifies the side effect an Action has.
+type ActionKind string
+
+const (
+	ActionWrite   ActionKind = "write"   // write Content to Path
+	ActionCopy    ActionKind = "copy"    // copy the file at Src to Path
+	ActionSymlink ActionKind = "symlink" // point the symlink at Path to Src
+	ActionCommand ActionKind = "command" // run Args
+	ActionRemove  ActionKind = "remove"  // delete the file or symlink at Path
+)
+
+// Action is one side effect of install, switch or generate: a file write,
+// copy, symlink, removal, or external command. Actions are computed up
+// front, so a dry run can report them without touching the filesystem.
+type Action struct {
+	Kind    ActionKind
+	Path    string   // destination of a write, copy, symlink or removal
+	Src     string   // copy source or symlink target
+	Content []byte   // bytes to write (ActionWrite)
+	Args    []string // argv (ActionCommand)
+
+	// Atomic writes and copies go through a sibling .tmp file and a rename,
+	// for watchers (tcm, pi) that only reliably see renames.
+	Atomic bool
+
+	// ReplaceLink marks a symlink at Path as the themer's own (see
+	// writeThrough): a write or copy replaces it with a regular file.
+	// Any other symlink is written through.
+	ReplaceLink bool
+
+	// BestEffort actions don't fail their app; a failure is noted in the
+	// app's message instead.
+	BestEffort bool
+
+	// Note is appended to the app's message once the action has run, for
+	// outcomes that only hold if it succeeds (e.g. "bat cache rebuilt").
+	Note string
+}
+
+// AppPlan is everything install or switch will do for one app.
+type AppPlan struct {
+	App     string
+	Skipped bool   // the theme has nothing for this app
+	Message string // human-readable summary of the plan
+	Actions []Action
+	Err     error // planning failed; the app is not applied
+}
+
+// Plan is the full set of actions for one install, switch or generate, in
+// the order they run.
+type Plan struct {
+	Apps []AppPlan
+}
+
+// Actions returns every action in the plan, in order.
+func (p Plan) Actions() []Action {
+	var out []Action
+	for _, a := range p.Apps {
+		out = append(out, a.Actions...)
+	}
+	return out
+}
+
+// App returns the plan for the named app, if it is in the plan.
+func (p Plan) App(name string) (AppPlan, bool) {
+	for _, a := range p.Apps {
+		if a.App == name {
+			return a, true
+		}
+	}
+	return AppPlan{}, false
+}
+
+// Apply runs the app's actions in order, stopping at the first failure of
+// an action that isn't best-effort. It returns the app's message with the
+// notes of the actions that ran.
+func (p AppPlan) Apply() (string, error) {
+	if p.Err != nil {
+		return "", p.Err
+	}
+	msg := p.Message
+	for _, a := range p.Actions {
+		if err := a.Apply(); err != nil {
+			if a.BestEffort {
+				msg += fmt.Sprintf("; %s failed: %v", filepath.Base(a.Args[0]), err)
+				continue
+			}
+			return msg, err
+		}
+		if a.Note != "" {
+			msg += "; " + a.Note
+		}
+	}
+	return msg, nil
+}
+
+// Apply performs the action.
+func (a Action) Apply() error {
+	if a.Kind == ActionWrite || a.Kind == ActionCopy {
+		if info, err := os.Lstat(a.Path); err == nil && info.Mode()&os.ModeSymlink != 0 {
+			if a.ReplaceLink {
+				if err := os.Remove(a.Path); err != nil {
+					return err
+				}
+			} else {
+				target, err := linkTarget(a.Path)
+				if err != nil {
+					return err
+				}
+				a.Path = target
+			}
+		}
+	}
+
+	switch a.Kind {
+	case ActionWrite:
+		return putFile(a.Path, a.Content, a.Atomic)
+	case ActionCopy:
+		if !a.Atomic {
+			if err := os.MkdirAll(filepath.Dir(a.Path), 0o755); err != nil {
+				return fmt.Errorf("creating %s: %w", filepath.Dir(a.Path), err)
+			}
+			if err := copyFile(a.Src, a.Path); err != nil {
+				return fmt.Errorf("copying %s: %w", filepath.Base(a.Src), err)
+			}
+			return nil
+		}
+		data, err := os.ReadFile(a.Src)
+		if err != nil {
+			return fmt.Errorf("reading %s: %w", a.Src, err)
+		}
+		return putFile(a.Path, data, true)
+	case ActionSymlink:
+		if err := os.MkdirAll(filepath.Dir(a.Path), 0o755); err != nil {
+			return err
+		}
+		// Remove the existing link before creating the new one.
+		os.Remove(a.Path)
+		return os.Symlink(a.Src, a.Path)
+	case ActionRemove:
+		if err := os.Remove(a.Path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("removing %s: %w", a.Path, err)
+		}
+		return nil
+	case ActionCommand:
+		out, err := exec.Command(a.Args[0], a.Args[1:]...).CombinedOutput()
+		if err != nil {
+			return fmt.Errorf("%s failed: %s: %w", strings.Join(a.Args, " "), strings.TrimSpace(string(out)), err)
+		}
+		return nil
+	}
+	return fmt.Errorf("unknown action kind %q", a.Kind)
+}
+
+// String describes the action on one line.
+func (a Action) String() string {
+	switch a.Kind {
+	case ActionWrite:
+		return "write " + a.Path
+	case ActionCopy:
+		return fmt.Sprintf("copy %s -> %s", a.Src, a.Path)
+	case ActionSymlink:
+		return fmt.Sprintf("symlink %s -> %s", a.Path, a.Src)
+	case ActionRemove:
+		return "remove " + a.Path
+	case ActionCommand:
+		return "run " + strings.Join(a.Args, " ")
+	}
+	return string(a.Kind)
+}
+
+// Diff returns a unified diff from what is at the action's destination now
+// to what the action will leave there. Writes, copies and removals diff
+// file contents; symlinks diff their targets. Commands have no diff, and
+// neither does an action that changes nothing.
+func (a Action) Diff() (string, error) {
+	switch a.Kind {
+	case ActionWrite, ActionCopy:
+		want := a.Content
+		wantName := a.Path + " (planned)"
+		if a.Kind == ActionCopy {
+			data, err := os.ReadFile(a.Src)
+			if err != nil && !errors.Is(err, os.ErrNotExist) {
+				return "", fmt.Errorf("reading %s: %w", a.Src, err)
+			}
+			if err != nil {
+				// The source is installed by an earlier step that hasn't
+				// run yet, e.g. switch before install.
+				return fmt.Sprintf("(%s does not exist yet)\n", a.Src), nil
+			}
+			want, wantName = data, a.Src
+		}
+
+		have, haveName, err := currentFile(a.Path)
+		if err != nil {
+			return "", err
+		}
+		return diff.Unified(haveName, wantName, have, want), nil
+
+	case ActionRemove:
+		have, haveName, err := currentFile(a.Path)
+		if err != nil {
+			return "", err
+		}
+		return diff.Unified(haveName, "/dev/null", have, nil), nil
+
+	case ActionSymlink:
+		have, haveName := "", "/dev/null"
+		if target, err := os.Readlink(a.Path); err == nil {
+			have, haveName = target+"\n", a.Path
+		}
+		return diff.Unified(haveName, a.Path+" (planned)", []byte(have), []byte(a.Src+"\n")), nil
+	}
+	return "", nil
+}
+
+// currentFile reads path for diffing, naming a missing file /dev/null the
+// way diff -N does.
+func currentFile(path string) ([]byte, string, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, "/dev/null", nil
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("reading %s: %w", path, err)
+	}
+	return data, path, nil
+}
+
+// linkTarget follows the symlinks at path to the file they end at, which
+// need not exist yet. A path that isn't a symlink is returned as is.
+func linkTarget(path string) (string, error) {
+	for range 40 {
+		info, err := os.Lstat(path)
+		if err != nil || info.Mode()&os.ModeSymlink == 0 {
+			return path, nil
+		}
+		target, err := os.Readlink(path)
+		if err != nil {
+			return "", fmt.Errorf("reading link %s: %w", path, err)
+		}
+		if !filepath.IsAbs(target) {
+			target = filepath.Join(filepath.Dir(path), target)
+		}
+		path = target
+	}
+	return "", fmt.Errorf("resolving %s: too many levels of symbolic links", path)
+}
+
+// writeThrough points writes and copies whose destination is a symlink the
+// user made, such as one into their dotfiles, at the file behind it, so
+// the link survives and snapshots and backups cover the file that changes.
+// A symlink the themer made itself, into its state directory or into the
+// directory a copy's source was installed to (tcm's active-theme.json once
+// was one), is marked ReplaceLink instead.
+func writeThrough(home string, actions []Action) ([]Action, error) {
+	out := make([]Action, len(actions))
+	for i, a := range actions {
+		out[i] = a
+		if a.Kind != ActionWrite && a.Kind != ActionCopy {
+			continue
+		}
+		if info, err := os.Lstat(a.Path); err != nil || info.Mode()&os.ModeSymlink == 0 {
+			continue
+		}
+		target, err := linkTarget(a.Path)
+		if err != nil {
+			return nil, err
+		}
+		if inStateDir(home, target) || (a.Kind == ActionCopy && filepath.Dir(target) == filepath.Dir(a.Src)) {
+			out[i].ReplaceLink = true
+			continue
+		}
+		out[i].Path = target
+	}
+	return out, nil
+}
+
+// putFile writes data to path, creating its directory. Atomic writes go to
+// path.tmp first and are renamed over path; rename is atomic on the same
+// filesystem and reliably triggers fs.watch. A file that already exists
+// keeps its permissions (e.g. a 0600 ~/.gitconfig); new files get 0644.
+func putFile(path string, data []byte, atomic bool) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("creating %s: %w", filepath.Dir(path), err)
+	}
+	perm := os.FileMode(0o644)
+	if info, err := os.Stat(path); err == nil {
+		perm = info.Mode().Perm()
+	}
+	if !atomic {
+		if err := os.WriteFile(path, data, perm); err != nil {
+			return fmt.Errorf("writing %s: %w", path, err)
+		}
+		return nil
+	}
+
+	tmp := path + ".tmp"
+	os.Remove(tmp) // WriteFile only applies perm to a file it creates
+	if err := os.WriteFile(tmp, data, perm); err != nil {
+		return fmt.Errorf("writing %s: %w", tmp, err)
+	}
+	if err := os.Chmod(tmp, perm); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("writing %s: %w", tmp, err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("renaming %s: %w", tmp, err)
+	}
+	return nil
+}
@@ -0,0 +1,134 @@
+package theme
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPlanSwitch_WritesNothing(t *testing.T) {
+	themesDir, themeDir := setupThemeDir(t, []string{"ghostty", "fzf", "tcm", "pi"}, minimalPaletteTOML)
+	writeFile(t, filepath.Join(themeDir, "ghostty", "test-theme.ghostty"), "config")
+	writeFile(t, filepath.Join(themeDir, "fzf", "test-theme.zsh"), "fzf content")
+	writeFile(t, filepath.Join(themeDir, "tcm", "test-theme.json"), "{}")
+
+	home := t.TempDir()
+	th, err := LoadTheme(themesDir, "test-theme")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	plan, err := PlanSwitch(th, SwitchOpts{HomeDir: home})
+	if err != nil {
+		t.Fatalf("PlanSwitch: %v", err)
+	}
+
+	config := filepath.Join(home, ".config")
+	want := map[string]Action{
+		"ghostty": {Kind: ActionWrite, Path: filepath.Join(config, "ghostty", "theme.local")},
+		"bat":     {Kind: ActionWrite, Path: filepath.Join(config, "bat-theme.txt")},
+		"fzf":     {Kind: ActionSymlink, Path: filepath.Join(config, "the-themer", "fzf", "current.zsh"), Src: filepath.Join(config, "the-themer", "fzf", "test-theme.zsh")},
+		"tcm":     {Kind: ActionCopy, Path: filepath.Join(config, "tcm", "active-theme.json"), Src: filepath.Join(config, "tcm", "test-theme.json"), Atomic: true},
+		"pi":      {Kind: ActionWrite, Path: filepath.Join(config, "the-themer", "pi-variant"), Atomic: true},
+	}
+	for app, w := range want {
+		p, ok := plan.App(app)
+		if !ok || p.Skipped || len(p.Actions) == 0 {
+			t.Errorf("%s: got plan %+v, want an action", app, p)
+			continue
+		}
+		got := p.Actions[0]
+		if got.Kind != w.Kind || got.Path != w.Path || got.Src != w.Src || got.Atomic != w.Atomic {
+			t.Errorf("%s: got %v (atomic %v), want %v (atomic %v)", app, got, got.Atomic, w, w.Atomic)
+		}
+	}
+	if p, _ := plan.App("starship"); !p.Skipped {
+		t.Errorf("starship: got %+v, want skipped", p)
+	}
+	if p, _ := plan.App("ghostty"); !strings.Contains(string(p.Actions[0].Content), "theme = test-theme.ghostty") {
+		t.Errorf("ghostty content = %q", p.Actions[0].Content)
+	}
+	if p, ok := plan.App("install"); !ok || len(p.Actions) != 3 {
+		t.Errorf("install step: got %+v, want copies for ghostty, fzf and tcm", p)
+	}
+
+	entries, err := os.ReadDir(home)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("planning wrote to home: %v", entries)
+	}
+}
+
+func TestPlanInstall_SkipsUnchanged(t *testing.T) {
+	themesDir, themeDir := setupThemeDir(t, []string{"ghostty", "fzf"}, minimalPaletteTOML)
+	writeFile(t, filepath.Join(themeDir, "ghostty", "test-theme.ghostty"), "ghostty config content")
+	writeFile(t, filepath.Join(themeDir, "fzf", "test-theme.zsh"), "fzf config content")
+
+	home := t.TempDir()
+	th, err := LoadTheme(themesDir, "test-theme")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	plan, err := PlanInstall(th, InstallOpts{HomeDir: home})
+	if err != nil {
+		t.Fatalf("PlanInstall: %v", err)
+	}
+	if n := len(plan.Actions()); n != 2 {
+		t.Fatalf("fresh home: got %d actions, want 2 copies: %v", n, plan.Actions())
+	}
+
+	Install(th, InstallOpts{HomeDir: home})
+	writeFile(t, filepath.Join(themeDir, "fzf", "test-theme.zsh"), "fzf config content v2")
+
+	plan, err = PlanInstall(th, InstallOpts{HomeDir: home})
+	if err != nil {
+		t.Fatalf("PlanInstall: %v", err)
+	}
+	actions := plan.Actions()
+	if len(actions) != 1 || actions[0].Kind != ActionCopy || filepath.Base(actions[0].Path) != "test-theme.zsh" {
+		t.Fatalf("after install: got %v, want only the changed fzf copy", actions)
+	}
+	d, err := actions[0].Diff()
+	if err != nil {
+		t.Fatalf("Diff: %v", err)
+	}
+	if !strings.Contains(d, "-fzf config content\n") || !strings.Contains(d, "+fzf config content v2\n") {
+		t.Errorf("Diff =\n%s", d)
+	}
+}
+
+func TestAction_Diff(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "theme.local")
+
+	write := Action{Kind: ActionWrite, Path: path, Content: []byte("theme = b\n")}
+	d, err := write.Diff()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(d, "--- /dev/null\n") || !strings.Contains(d, "+theme = b\n") {
+		t.Errorf("diff against a missing file =\n%s", d)
+	}
+
+	writeFile(t, path, "theme = b\n")
+	if d, _ := write.Diff(); d != "" {
+		t.Errorf("diff of an unchanged write = %q, want empty", d)
+	}
+
+	link := filepath.Join(dir, "current.zsh")
+	if err := os.Symlink(filepath.Join(dir, "a.zsh"), link); err != nil {
+		t.Fatal(err)
+	}
+	symlink := Action{Kind: ActionSymlink, Path: link, Src: filepath.Join(dir, "b.zsh")}
+	d, err = symlink.Diff()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(d, "-"+filepath.Join(dir, "a.zsh")) || !strings.Contains(d, "+"+filepath.Join(dir, "b.zsh")) {
+		t.Errorf("symlink diff =\n%s", d)
+	}
+}
@@ -0,0 +1,122 @@
+package theme
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// snapshot records what is at a set of destination paths — file contents,
+// symlink targets, or nothing — so a failed switch can put them back.
+type snapshot struct {
+	entries []snapshotEntry
+	dirs    []string // directories that didn't exist, deepest first
+}
+
+type snapshotEntry struct {
+	path   string
+	exists bool
+	target string // symlink target; "" for a regular file
+	data   []byte
+	mode   os.FileMode
+}
+
+// takeSnapshot records the current state of every path the actions write,
+// copy or link to, and of the directories that writing them would create.
+func takeSnapshot(actions []Action) (*snapshot, error) {
+	s := &snapshot{}
+	seen := map[string]bool{}
+	missingDirs := map[string]bool{}
+
+	for _, a := range actions {
+		if a.Kind == ActionCommand {
+			continue
+		}
+		paths := []string{a.Path}
+		if a.Atomic {
+			paths = append(paths, a.Path+".tmp")
+		}
+		for _, path := range paths {
+			if seen[path] {
+				continue
+			}
+			seen[path] = true
+			e, err := snapshotPath(path)
+			if err != nil {
+				return nil, err
+			}
+			s.entries = append(s.entries, e)
+		}
+
+		for dir := filepath.Dir(a.Path); ; dir = filepath.Dir(dir) {
+			if _, err := os.Lstat(dir); err == nil || dir == filepath.Dir(dir) {
+				break
+			}
+			missingDirs[dir] = true
+		}
+	}
+
+	for dir := range missingDirs {
+		s.dirs = append(s.dirs, dir)
+	}
+	// Longest path first removes children before their parents.
+	sort.Slice(s.dirs, func(i, j int) bool { return len(s.dirs[i]) > len(s.dirs[j]) })
+	return s, nil
+}
+
+func snapshotPath(path string) (snapshotEntry, error) {
+	e := snapshotEntry{path: path}
+	info, err := os.Lstat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return e, nil
+	}
+	if err != nil {
+		return e, fmt.Errorf("snapshotting %s: %w", path, err)
+	}
+
+	e.exists, e.mode = true, info.Mode()
+	if info.Mode()&os.ModeSymlink != 0 {
+		e.target, err = os.Readlink(path)
+	} else {
+		e.data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return e, fmt.Errorf("snapshotting %s: %w", path, err)
+	}
+	return e, nil
+}
+
+// restore puts every recorded path back as it was and removes the
+// directories created since. It attempts every path and returns the
+// errors joined.
+func (s *snapshot) restore() error {
+	var errs []error
+	for _, e := range s.entries {
+		if err := os.Remove(e.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			errs = append(errs, fmt.Errorf("restoring %s: %w", e.path, err))
+			continue
+		}
+		if !e.exists {
+			continue
+		}
+
+		var err error
+		if e.target != "" {
+			err = os.Symlink(e.target, e.path)
+		} else {
+			err = os.WriteFile(e.path, e.data, e.mode.Perm())
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("restoring %s: %w", e.path, err))
+		}
+	}
+
+	for _, dir := range s.dirs {
+		if err := os.Remove(dir); err != nil && !errors.Is(err, os.ErrNotExist) {
+			errs = append(errs, fmt.Errorf("removing %s: %w", dir, err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,144 @@
+package theme
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// failingSwitchHandler appends a handler that fails after every other app
+// has switched, restoring the real handlers when the test ends.
+func failingSwitchHandler(t *testing.T) {
+	t.Helper()
+	orig := switchHandlers
+	t.Cleanup(func() { switchHandlers = orig })
+	switchHandlers = append(append([]switchHandler{}, orig...), switchHandler{
+		app: "broken",
+		plan: func(t Theme, l layout) (string, []Action, error) {
+			// Plans fine, fails to apply.
+			missing := filepath.Join(l.home, "missing")
+			return "broken", []Action{{Kind: ActionCopy, Src: missing, Path: missing + ".copy"}}, nil
+		},
+	})
+}
+
+// treeState captures every path under root with its contents, symlink
+// target, and mode.
+func treeState(t *testing.T, root string) map[string]string {
+	t.Helper()
+	state := map[string]string{}
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(root, path)
+		switch {
+		case info.Mode()&os.ModeSymlink != 0:
+			target, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			state[rel] = "-> " + target
+		case info.IsDir():
+			state[rel] = "dir " + info.Mode().String()
+		default:
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			state[rel] = info.Mode().String() + " " + string(data)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return state
+}
+
+func setupSwitchHome(t *testing.T) (Theme, string) {
+	t.Helper()
+	themesDir, themeDir := setupThemeDir(t, []string{"ghostty", "fzf", "starship", "gh-dash", "tcm", "pi"}, minimalPaletteTOML)
+	writeFile(t, filepath.Join(themeDir, "ghostty", "test-theme.ghostty"), "config")
+	writeFile(t, filepath.Join(themeDir, "fzf", "test-theme.zsh"), "fzf content")
+	writeFile(t, filepath.Join(themeDir, "starship", "test-starship.toml"), "starship content")
+	writeFile(t, filepath.Join(themeDir, "gh-dash", "test-theme.yml"), ghDashThemeYAML)
+	writeFile(t, filepath.Join(themeDir, "tcm", "test-theme.json"), "{\"name\": \"new\"}\n")
+
+	th, err := LoadTheme(themesDir, "test-theme")
+	if err != nil {
+		t.Fatal(err)
+	}
+	home := t.TempDir()
+	Install(th, InstallOpts{HomeDir: home})
+
+	// What a previous theme left behind.
+	config := filepath.Join(home, ".config")
+	writeFile(t, filepath.Join(config, "ghostty", "theme.local"), "theme = old.ghostty\n")
+	writeFile(t, filepath.Join(config, "bat-theme.txt"), "old\n")
+	writeFile(t, filepath.Join(config, "gh-dash", "config.yml"), "theme: old\n")
+	if err := os.Chmod(filepath.Join(config, "gh-dash", "config.yml"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, filepath.Join(config, "the-themer", "fzf", "old.zsh"), "old fzf")
+	if err := os.Symlink(filepath.Join(config, "the-themer", "fzf", "old.zsh"), filepath.Join(config, "the-themer", "fzf", "current.zsh")); err != nil {
+		t.Fatal(err)
+	}
+	return th, home
+}
+
+func TestSwitch_RollsBackOnFailure(t *testing.T) {
+	th, home := setupSwitchHome(t)
+	failingSwitchHandler(t)
+	before := treeState(t, home)
+
+	results := Switch(th, SwitchOpts{HomeDir: home})
+
+	var failed, rolledBack int
+	for _, r := range results {
+		if r.Err != nil {
+			failed++
+			if r.App != "broken" {
+				t.Errorf("unexpected error for %s: %v", r.App, r.Err)
+			}
+		}
+		if r.RolledBack {
+			rolledBack++
+		}
+	}
+	if failed != 1 || rolledBack == 0 {
+		t.Fatalf("got %d failures and %d rollbacks, want the broken app to fail and the rest roll back: %+v", failed, rolledBack, results)
+	}
+
+	after := treeState(t, home)
+	for path, want := range before {
+		if got := after[path]; got != want {
+			t.Errorf("%s: got %q, want %q", path, got, want)
+		}
+	}
+	for path, got := range after {
+		if _, ok := before[path]; !ok {
+			t.Errorf("%s left behind after rollback: %q", path, got)
+		}
+	}
+}
+
+func TestSwitch_BestEffortKeepsSwitchedApps(t *testing.T) {
+	th, home := setupSwitchHome(t)
+	failingSwitchHandler(t)
+
+	results := Switch(th, SwitchOpts{HomeDir: home, BestEffort: true})
+	for _, r := range results {
+		if r.RolledBack {
+			t.Errorf("%s rolled back under BestEffort", r.App)
+		}
+	}
+
+	assertFileContains(t, filepath.Join(home, ".config", "ghostty", "theme.local"), "theme = test-theme.ghostty")
+	assertFileContains(t, filepath.Join(home, ".config", "gh-dash", "config.yml"), `primary: "#0000aa"`)
+}
@@ -7,11 +7,73 @@ import (
 	"strings"
 )
 
-// stateDir returns the path to the-themer's state directory.
+// stateDir returns the path to the-themer's state directory. Until
+// MigrateStateDir has moved it, that is still ~/.config/the-themer.
 func stateDir(home string) string {
+	if stateDirPending(home) {
+		return legacyStateDir(home)
+	}
+	return xdgStateDir(home)
+}
+
+// xdgStateDir is where the state directory belongs: under XDG_CONFIG_HOME.
+func xdgStateDir(home string) string {
+	return filepath.Join(configHome(home), "the-themer")
+}
+
+// legacyStateDir is where the state directory lived before the-themer
+// honoured XDG_CONFIG_HOME.
+func legacyStateDir(home string) string {
 	return filepath.Join(home, ".config", "the-themer")
 }
 
+// stateDirPending reports whether the state directory has yet to move from
+// ~/.config to $XDG_CONFIG_HOME: XDG_CONFIG_HOME points elsewhere, the old
+// directory is a real one (not the symlink a move leaves behind), and
+// nothing is at the new path.
+func stateDirPending(home string) bool {
+	from, to := legacyStateDir(home), xdgStateDir(home)
+	if from == to {
+		return false
+	}
+	if info, err := os.Lstat(from); err != nil || !info.IsDir() {
+		return false
+	}
+	_, err := os.Lstat(to)
+	return os.IsNotExist(err)
+}
+
+// StateDirMove returns the paths MigrateStateDir would move the state
+// directory from and to, and whether it would move it at all.
+func StateDirMove(home string) (from, to string, ok bool) {
+	return legacyStateDir(home), xdgStateDir(home), stateDirPending(home)
+}
+
+// MigrateStateDir moves ~/.config/the-themer to $XDG_CONFIG_HOME/the-themer
+// when XDG_CONFIG_HOME points elsewhere and only the old directory exists,
+// so the current theme, installed copies, managed files and backups follow.
+// A symlink is left at the old path: fzf and starship links, the git
+// include of active.gitconfig and other absolute paths written before the
+// move keep resolving until the next install and switch rewrite them.
+// Only commands that change files call it; until then the state directory
+// is read and written where it is. It reports whether it moved anything.
+func MigrateStateDir(home string) (bool, error) {
+	from, to, ok := StateDirMove(home)
+	if !ok {
+		return false, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(to), 0o755); err != nil {
+		return false, fmt.Errorf("creating %s: %w", filepath.Dir(to), err)
+	}
+	if err := os.Rename(from, to); err != nil {
+		return false, fmt.Errorf("moving %s to %s (move it by hand, or unset XDG_CONFIG_HOME): %w", from, to, err)
+	}
+	if err := os.Symlink(to, from); err != nil {
+		return true, fmt.Errorf("linking %s to %s: %w", from, to, err)
+	}
+	return true, nil
+}
+
 // statePath returns the path to the current theme state file.
 func statePath(home string) string {
 	return filepath.Join(stateDir(home), "current")
@@ -11,7 +11,20 @@ import (
 
 // SwitchOpts configures the switch operation.
 type SwitchOpts struct {
-	HomeDir string // injectable for testing; defaults to os.UserHomeDir()
+	HomeDir string  // injectable for testing; defaults to os.UserHomeDir()
+	Config  *Config // destinations, disabled apps, reloads; nil for DefaultConfig()
+
+	// BestEffort keeps going past a failing app and leaves the apps that
+	// did switch switched. By default a failure rolls every app back.
+	BestEffort bool
+
+	// Force replaces regular files where switch puts a symlink (e.g. a
+	// hand-written ~/.config/starship.toml). They're backed up first.
+	Force bool
+
+	// NoInstall skips installing the theme's missing or out-of-date files
+	// before switching, leaving symlinks to files install hasn't copied.
+	NoInstall bool
 }
 
 // resolveHome returns opts.HomeDir if set, otherwise os.UserHomeDir().
@@ -24,50 +37,228 @@ func (o SwitchOpts) resolveHome() (string, error) {
 
 // SwitchResult holds the outcome of switching one app.
 type SwitchResult struct {
-	App     string
-	Skipped bool
-	Message string
-	Err     error
+	App        string
+	Skipped    bool
+	RolledBack bool // switched, then restored after a later app failed
+	Message    string
+	Err        error
 }
 
-// Switch activates a theme across all supported apps. Each app is handled
-// independently — errors are collected best-effort.
+// Switch activates a theme across all supported apps.
+//
+// Switch is transactional: it snapshots every file and symlink it is about
+// to touch, and if any app fails, it restores them all and stops. External
+// commands (nvim, osascript) can't be undone, so a rollback after one of
+// them has run leaves that app switched. With opts.BestEffort, each app is
+// handled independently instead — errors are collected and every app is
+// attempted.
+//
+// Unless opts.NoInstall is set, installed files that are missing or differ
+// from the warehouse are installed first, as an "install" step that is
+// rolled back with the rest.
+//
+// Files the themer didn't write itself are backed up before they're first
+// replaced (see RestoreBackup).
 func Switch(t Theme, opts SwitchOpts) []SwitchResult {
+	plan, err := PlanSwitch(t, opts)
+	if err != nil {
+		return []SwitchResult{{App: "home", Err: err}}
+	}
 	home, err := opts.resolveHome()
 	if err != nil {
 		return []SwitchResult{{App: "home", Err: fmt.Errorf("resolving home directory: %w", err)}}
 	}
 
-	handlers := []struct {
-		app     string
-		switch_ func(t Theme, home string) (string, error)
-	}{
-		{"ghostty", switchGhostty},
-		{"bat", switchBat},
-		{"delta", switchDelta},
-		{"fzf", switchFzf},
-		{"tcm", switchTCM},
-		{"starship", switchStarship},
-		{"eza", switchEza},
-		{"gh-dash", switchGhDash},
-		{"hud", switchHud},
-		{"neovim", switchNeovim},
-		{"pi", switchPi},
+	var snap *snapshot
+	if !opts.BestEffort {
+		// An app that can't even be planned fails the switch before
+		// anything is touched.
+		if results, ok := planFailures(plan); !ok {
+			return results
+		}
+		if snap, err = takeSnapshot(plan.Actions()); err != nil {
+			return []SwitchResult{{App: "snapshot", Err: err}}
+		}
+	}
+
+	backup, err := backupUnmanaged(home, plan.Actions())
+	if err != nil {
+		return []SwitchResult{{App: "backup", Err: err}}
 	}
 
 	var results []SwitchResult
-	for _, h := range handlers {
-		msg, err := h.switch_(t, home)
-		if msg == "" && err == nil {
-			// Handler signaled nothing to do.
-			results = append(results, SwitchResult{App: h.app, Skipped: true, Message: "not configured for this theme"})
+	var switched []Action
+	for i, p := range plan.Apps {
+		if p.Skipped {
+			results = append(results, SwitchResult{App: p.App, Skipped: true, Message: p.Message})
 			continue
 		}
-		results = append(results, SwitchResult{App: h.app, Message: msg, Err: err})
+		msg, err := p.Apply()
+		results = append(results, SwitchResult{App: p.App, Message: msg, Err: err})
+		if err == nil {
+			switched = append(switched, p.Actions...)
+		}
+		if err == nil || snap == nil {
+			continue
+		}
+
+		// Roll back: restore the snapshot and report the apps that had
+		// switched, and the ones never attempted.
+		for j := range results[:len(results)-1] {
+			if !results[j].Skipped {
+				results[j].RolledBack = true
+			}
+		}
+		for _, rest := range plan.Apps[i+1:] {
+			results = append(results, SwitchResult{App: rest.App, Skipped: true, Message: "not attempted"})
+		}
+		if err := snap.restore(); err != nil {
+			results = append(results, SwitchResult{App: "rollback", Err: err})
+		}
+		// Nothing was replaced, so the backup has nothing to restore.
+		backup.discard()
+		return results
+	}
+
+	if backup != nil {
+		results = append([]SwitchResult{{App: "backup", Message: backup.String()}}, results...)
+	}
+	if err := markManaged(home, switched); err != nil {
+		results = append(results, SwitchResult{App: "backup", Err: err})
 	}
 	return results
 }
 
+// planFailures reports the apps whose plans failed, with every other app
+// not attempted. ok is true when every app planned.
+func planFailures(plan Plan) (results []SwitchResult, ok bool) {
+	ok = true
+	for _, p := range plan.Apps {
+		switch {
+		case p.Err != nil:
+			results = append(results, SwitchResult{App: p.App, Err: p.Err})
+			ok = false
+		case p.Skipped:
+			results = append(results, SwitchResult{App: p.App, Skipped: true, Message: p.Message})
+		default:
+			results = append(results, SwitchResult{App: p.App, Skipped: true, Message: "not attempted"})
+		}
+	}
+	return results, ok
+}
+
+// PlanSwitch computes what Switch would do without doing any of it.
+func PlanSwitch(t Theme, opts SwitchOpts) (Plan, error) {
+	home, err := opts.resolveHome()
+	if err != nil {
+		return Plan{}, fmt.Errorf("resolving home directory: %w", err)
+	}
+
+	l := newLayout(home, opts.Config)
+	var plan Plan
+	if !opts.NoInstall {
+		install, err := planSwitchInstall(t, home, opts.Config)
+		if err != nil {
+			return Plan{}, err
+		}
+		if install != nil {
+			plan.Apps = append(plan.Apps, *install)
+		}
+	}
+	for _, h := range switchHandlers {
+		if l.cfg.disabled(h.app) {
+			plan.Apps = append(plan.Apps, AppPlan{App: h.app, Skipped: true, Message: "disabled in config.toml"})
+			continue
+		}
+		msg, actions, err := h.plan(t, l)
+		if msg == "" && len(actions) == 0 && err == nil {
+			// Handler signaled nothing to do.
+			plan.Apps = append(plan.Apps, AppPlan{App: h.app, Skipped: true, Message: "not configured for this theme"})
+			continue
+		}
+		if err == nil {
+			actions, err = writeThrough(home, actions)
+		}
+		if err == nil && !opts.Force {
+			err = checkClobber(actions)
+		}
+		plan.Apps = append(plan.Apps, AppPlan{App: h.app, Message: msg, Actions: actions, Err: err})
+	}
+	return plan, nil
+}
+
+// planSwitchInstall returns the install step of a switch: the install
+// actions of every app with files missing or out of date, or nil if the
+// theme is fully installed.
+func planSwitchInstall(t Theme, home string, cfg *Config) (*AppPlan, error) {
+	install, err := PlanInstall(t, InstallOpts{HomeDir: home, Config: cfg})
+	if err != nil {
+		return nil, err
+	}
+
+	step := AppPlan{App: "install"}
+	var apps []string
+	for _, p := range install.Apps {
+		if p.Err != nil {
+			step.Err = fmt.Errorf("installing %s: %w", p.App, p.Err)
+			return &step, nil
+		}
+		// Commands alone (bat's cache rebuild) don't make an app stale.
+		stale := false
+		for _, a := range p.Actions {
+			if a.Kind != ActionCommand {
+				stale = true
+			}
+		}
+		if stale {
+			apps = append(apps, p.App)
+			step.Actions = append(step.Actions, p.Actions...)
+		}
+	}
+	if len(apps) == 0 {
+		return nil, nil
+	}
+	step.Message = fmt.Sprintf("installed %s (missing or out of date)", strings.Join(apps, ", "))
+	return &step, nil
+}
+
+// checkClobber refuses symlinks that would replace a regular file: switch
+// owns symlinks like ~/.config/starship.toml, but a regular file there is
+// the user's own config.
+func checkClobber(actions []Action) error {
+	for _, a := range actions {
+		if a.Kind != ActionSymlink {
+			continue
+		}
+		if info, err := os.Lstat(a.Path); err == nil && info.Mode()&os.ModeSymlink == 0 {
+			return fmt.Errorf("%s is a regular file, not a symlink; use --force to replace it (it is backed up first)", a.Path)
+		}
+	}
+	return nil
+}
+
+// switchHandler plans switching one app. A handler returning no message,
+// no actions and no error has nothing to do for the theme.
+type switchHandler struct {
+	app  string
+	plan func(t Theme, l layout) (string, []Action, error)
+}
+
+// switchHandlers run in order. A variable so tests can inject handlers.
+var switchHandlers = []switchHandler{
+	{"ghostty", switchGhostty},
+	{"bat", switchBat},
+	{"delta", switchDelta},
+	{"fzf", switchFzf},
+	{"tcm", switchTCM},
+	{"starship", switchStarship},
+	{"eza", switchEza},
+	{"gh-dash", switchGhDash},
+	{"hud", switchHud},
+	{"neovim", switchNeovim},
+	{"pi", switchPi},
+}
+
 // switchPi writes the active theme's variant ("light" or "dark") to a tiny
 // marker file at ~/.config/the-themer/pi-variant. The pi `theme-toggle`
 // extension watches that file via fs.watch and calls setTheme(variant) on
@@ -81,68 +272,60 @@ func Switch(t Theme, opts SwitchOpts) []SwitchResult {
 // Atomic write (write-tmp + rename) for the same reason tcm needs it: macOS
 // fs.watch on a regular file is reliably triggered by rename, less so by
 // in-place truncate.
-func switchPi(t Theme, home string) (string, error) {
+func switchPi(t Theme, l layout) (string, []Action, error) {
 	if !dirExists(filepath.Join(t.Dir, "pi")) {
-		return "", nil
+		return "", nil, nil
 	}
 	variant := t.Config.Theme.Variant
 	if variant != "light" && variant != "dark" {
-		return "", nil
-	}
-	dir := filepath.Join(home, ".config", "the-themer")
-	if err := os.MkdirAll(dir, 0o755); err != nil {
-		return "", err
-	}
-	dest := filepath.Join(dir, "pi-variant")
-	tmp := dest + ".tmp"
-	if err := os.WriteFile(tmp, []byte(variant+"\n"), 0o644); err != nil {
-		return "", err
+		return "", nil, nil
 	}
-	if err := os.Rename(tmp, dest); err != nil {
-		os.Remove(tmp)
-		return "", err
+	write := Action{
+		Kind:    ActionWrite,
+		Path:    l.file("pi", "the-themer", "pi-variant"),
+		Content: []byte(variant + "\n"),
+		Atomic:  true,
 	}
-	return fmt.Sprintf("pi-variant -> %s", variant), nil
+	return fmt.Sprintf("pi-variant -> %s", variant), []Action{write}, nil
 }
 
 // switchGhostty writes theme.local with the theme filename reference.
 // Ghostty matches the `theme` value against filenames in its themes directory,
 // so we use the full filename including any .ghostty extension.
-func switchGhostty(t Theme, home string) (string, error) {
+func switchGhostty(t Theme, l layout) (string, []Action, error) {
 	ghosttyDir := filepath.Join(t.Dir, "ghostty")
 	if _, err := os.Stat(ghosttyDir); os.IsNotExist(err) {
-		return "", nil
+		return "", nil, nil
 	}
 
 	themeFile, err := firstFile(ghosttyDir)
 	if err != nil {
-		return "", fmt.Errorf("reading ghostty dir: %w", err)
+		return "", nil, fmt.Errorf("reading ghostty dir: %w", err)
 	}
 	if themeFile == "" {
-		return "", nil
+		return "", nil, nil
 	}
 
 	content := fmt.Sprintf("# Managed by the-themer — do not edit\ntheme = %s\n", themeFile)
-	dest := filepath.Join(home, ".config", "ghostty", "theme.local")
-
-	if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
-		return "", err
-	}
-	if err := os.WriteFile(dest, []byte(content), 0o644); err != nil {
-		return "", err
-	}
-	msg := fmt.Sprintf("theme.local -> %s", themeFile)
-	if nudge := nudgeGhosttyReload(); nudge != "" {
-		msg += "; " + nudge
-	}
-	return msg, nil
+	actions := []Action{{
+		Kind:    ActionWrite,
+		Path:    l.file("ghostty", "ghostty", "theme.local"),
+		Content: []byte(content),
+	}}
+	if !l.cfg.Reload.Ghostty {
+		return fmt.Sprintf("theme.local -> %s", themeFile), actions, nil
+	}
+	if nudge := nudgeGhosttyReload(); nudge != nil {
+		actions = append(actions, *nudge)
+	}
+	return fmt.Sprintf("theme.local -> %s", themeFile), actions, nil
 }
 
-// nudgeGhosttyReload sends Cmd+Shift+R via System Events on macOS so a
-// running Ghostty picks up theme.local without manual intervention. Best-
-// effort: if Ghostty isn't running, osascript is missing, the platform
-// isn't macOS, or TCC denies the action, the function silently returns ""
-// and the switch result still reports success.
+// nudgeGhosttyReload plans sending Cmd+Shift+R via System Events on macOS
+// so a running Ghostty picks up theme.local without manual intervention.
+// Best-effort: if Ghostty isn't running, osascript is missing, or the
+// platform isn't macOS, it returns nil; if TCC denies the action, the
+// switch result still reports success.
 //
 // Why osascript and not an IPC call: Ghostty's macOS build has no CLI->
 // running-app channel (`ghostty +new-window` reports "not supported on
@@ -150,123 +333,134 @@ func switchGhostty(t Theme, home string) (string, error) {
 // AppleScript. Requires Ghostty to be granted Automation → System Events
 // in System Settings → Privacy & Security; first invocation triggers the
 // TCC prompt.
-func nudgeGhosttyReload() string {
+func nudgeGhosttyReload() *Action {
 	if runtime.GOOS != "darwin" {
-		return ""
+		return nil
 	}
 	osa, err := exec.LookPath("osascript")
 	if err != nil {
-		return ""
+		return nil
 	}
 	// Don't launch Ghostty just to reload it.
 	check := exec.Command(osa, "-e", `application id "com.mitchellh.ghostty" is running`)
 	out, err := check.Output()
 	if err != nil || strings.TrimSpace(string(out)) != "true" {
-		return ""
+		return nil
 	}
 	// activate brings Ghostty to focus so the keystroke lands in it. In the
 	// common case (running `theme` from a Ghostty terminal) Ghostty is
 	// already frontmost, so activate is a visual no-op.
 	script := `tell application id "com.mitchellh.ghostty" to activate
 tell application "System Events" to keystroke "r" using {command down, shift down}`
-	if err := exec.Command(osa, "-e", script).Run(); err != nil {
-		return fmt.Sprintf("reload nudge failed: %v", err)
+	return &Action{
+		Kind:       ActionCommand,
+		Args:       []string{osa, "-e", script},
+		BestEffort: true,
+		Note:       "reloaded",
 	}
-	return "reloaded"
 }
 
 // switchBat writes the bat theme name to bat-theme.txt.
 // bat identifies custom themes by filename (sans .tmTheme extension), so we
 // read the actual filename from the theme's bat/ directory.
 // If only a reference is set, use that directly.
-func switchBat(t Theme, home string) (string, error) {
+func switchBat(t Theme, l layout) (string, []Action, error) {
 	batDir := filepath.Join(t.Dir, "bat")
 	hasBatDir := dirExists(batDir)
 	refName := t.Config.References["bat"]
 
 	if !hasBatDir && refName == "" {
-		return "", nil
+		return "", nil, nil
 	}
 
 	var themeName string
 	if hasBatDir {
 		file, err := firstFile(batDir)
 		if err != nil {
-			return "", fmt.Errorf("reading bat dir: %w", err)
+			return "", nil, fmt.Errorf("reading bat dir: %w", err)
 		}
 		if file == "" {
-			return "", nil
+			return "", nil, nil
 		}
 		themeName = strings.TrimSuffix(file, ".tmTheme")
 	} else {
 		themeName = refName
 	}
 
-	dest := filepath.Join(home, ".config", "bat-theme.txt")
-	if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
-		return "", err
-	}
-	if err := os.WriteFile(dest, []byte(themeName+"\n"), 0o644); err != nil {
-		return "", err
+	write := Action{
+		Kind:    ActionWrite,
+		Path:    l.file("bat", "bat-theme.txt"),
+		Content: []byte(themeName + "\n"),
 	}
-	return fmt.Sprintf("bat-theme.txt -> %s", themeName), nil
+	return fmt.Sprintf("bat-theme.txt -> %s", themeName), []Action{write}, nil
 }
 
-// switchDelta writes the delta feature name to delta-theme.txt.
+// switchDelta writes the delta feature name to delta-theme.txt and
+// rewrites active.gitconfig, the one include install adds to the global
+// gitconfig, to include the theme's gitconfig and select its feature.
 // The feature name is derived from the gitconfig filename (without extension).
-func switchDelta(t Theme, home string) (string, error) {
-	deltaDir := filepath.Join(t.Dir, "delta")
-	hasDeltaDir := dirExists(deltaDir)
+func switchDelta(t Theme, l layout) (string, []Action, error) {
+	deltaThemeDir := filepath.Join(t.Dir, "delta")
+	hasDeltaDir := dirExists(deltaThemeDir)
 	refName := t.Config.References["delta"]
 
 	if !hasDeltaDir && refName == "" {
-		return "", nil
+		return "", nil, nil
 	}
 
-	var featureName string
+	var featureName, includeFile string
 	if hasDeltaDir {
 		// The gitconfig filename (without .gitconfig) is the delta feature name.
-		file, err := firstFile(deltaDir)
+		file, err := firstFile(deltaThemeDir)
 		if err != nil {
-			return "", err
+			return "", nil, err
 		}
 		featureName = strings.TrimSuffix(file, ".gitconfig")
+		includeFile = file
 	} else {
 		featureName = refName
 	}
 
-	dest := filepath.Join(home, ".config", "delta-theme.txt")
-	if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
-		return "", err
-	}
-	if err := os.WriteFile(dest, []byte(featureName+"\n"), 0o644); err != nil {
-		return "", err
-	}
-	return fmt.Sprintf("delta-theme.txt -> %s", featureName), nil
+	userFeatures, err := userDeltaFeatures(l.home)
+	if err != nil {
+		return "", nil, err
+	}
+
+	actions := []Action{
+		{
+			Kind:    ActionWrite,
+			Path:    l.file("delta", "delta-theme.txt"),
+			Content: []byte(featureName + "\n"),
+		},
+		{
+			Kind:    ActionWrite,
+			Path:    activeDeltaPath(l.home),
+			Content: activeDeltaConfig(featureName, includeFile, userFeatures),
+			Atomic:  true,
+		},
+	}
+	return fmt.Sprintf("delta-theme.txt, active.gitconfig -> %s", featureName), actions, nil
 }
 
 // switchFzf creates a symlink current.zsh pointing to the installed fzf config.
-func switchFzf(t Theme, home string) (string, error) {
+func switchFzf(t Theme, l layout) (string, []Action, error) {
 	fzfDir := filepath.Join(t.Dir, "fzf")
 	if !dirExists(fzfDir) {
-		return "", nil
+		return "", nil, nil
 	}
 
 	srcFile, err := firstFile(fzfDir)
 	if err != nil || srcFile == "" {
-		return "", err
+		return "", nil, err
 	}
 
-	installedDir := filepath.Join(home, ".config", "the-themer", "fzf")
-	installedFile := filepath.Join(installedDir, srcFile)
-	link := filepath.Join(installedDir, "current.zsh")
-
-	// Remove existing symlink before creating new one.
-	os.Remove(link)
-	if err := os.Symlink(installedFile, link); err != nil {
-		return "", err
+	installedDir := filepath.Join(stateDir(l.home), "fzf")
+	link := Action{
+		Kind: ActionSymlink,
+		Path: l.file("fzf", "the-themer", "fzf", "current.zsh"),
+		Src:  filepath.Join(installedDir, srcFile),
 	}
-	return fmt.Sprintf("fzf/current.zsh -> %s", srcFile), nil
+	return fmt.Sprintf("fzf/current.zsh -> %s", srcFile), []Action{link}, nil
 }
 
 // switchTCM atomically writes ~/.config/tcm/active-theme.json with the
@@ -277,158 +471,150 @@ func switchFzf(t Theme, home string) (string, error) {
 // "Atomic writes (rename trick) are detected." So we write to a sibling .tmp
 // file and rename onto active-theme.json; rename is atomic on the same
 // filesystem and reliably triggers fs.watch.
-func switchTCM(t Theme, home string) (string, error) {
+//
+// Copying (rather than linking) also matters because dest may currently be
+// a symlink from the previous symlink-based implementation; we want to land
+// a regular file at dest so fs.watch tracks it directly.
+func switchTCM(t Theme, l layout) (string, []Action, error) {
 	tcmDir := filepath.Join(t.Dir, "tcm")
 	if !dirExists(tcmDir) {
-		return "", nil
+		return "", nil, nil
 	}
 
 	srcFile, err := firstFile(tcmDir)
 	if err != nil || srcFile == "" {
-		return "", err
+		return "", nil, err
 	}
 
-	installedFile := filepath.Join(home, ".config", "tcm", srcFile)
-	dest := filepath.Join(home, ".config", "tcm", "active-theme.json")
-	tmp := dest + ".tmp"
-
-	if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
-		return "", err
+	cp := Action{
+		Kind:   ActionCopy,
+		Src:    filepath.Join(l.dir("tcm", "tcm"), srcFile),
+		Path:   l.file("tcm", "tcm", "active-theme.json"),
+		Atomic: true,
 	}
-
-	// Read the installed source. Required because dest may currently be a
-	// symlink (from the previous symlink-based implementation); we want to
-	// land a regular file at dest so fs.watch tracks it directly.
-	data, err := os.ReadFile(installedFile)
-	if err != nil {
-		return "", fmt.Errorf("reading %s: %w", installedFile, err)
-	}
-
-	if err := os.WriteFile(tmp, data, 0o644); err != nil {
-		return "", err
-	}
-	if err := os.Rename(tmp, dest); err != nil {
-		os.Remove(tmp)
-		return "", err
-	}
-	return fmt.Sprintf("tcm/active-theme.json <- %s (atomic write)", srcFile), nil
+	return fmt.Sprintf("tcm/active-theme.json <- %s (atomic write)", srcFile), []Action{cp}, nil
 }
 
 // switchStarship symlinks ~/.config/starship.toml to the installed starship config.
-func switchStarship(t Theme, home string) (string, error) {
+func switchStarship(t Theme, l layout) (string, []Action, error) {
 	starshipDir := filepath.Join(t.Dir, "starship")
 	if !dirExists(starshipDir) {
-		return "", nil
+		return "", nil, nil
 	}
 
 	srcFile, err := firstFile(starshipDir)
 	if err != nil || srcFile == "" {
-		return "", err
+		return "", nil, err
 	}
 
-	installedFile := filepath.Join(home, ".config", "the-themer", "starship", srcFile)
-	link := filepath.Join(home, ".config", "starship.toml")
-
-	os.Remove(link)
-	if err := os.Symlink(installedFile, link); err != nil {
-		return "", err
+	link := Action{
+		Kind: ActionSymlink,
+		Path: l.file("starship", "starship.toml"),
+		Src:  filepath.Join(stateDir(l.home), "starship", srcFile),
 	}
-	return fmt.Sprintf("starship.toml -> %s", srcFile), nil
+	return fmt.Sprintf("starship.toml -> %s", srcFile), []Action{link}, nil
 }
 
 // switchEza symlinks ~/.config/eza/theme.yml to the installed eza theme.
-func switchEza(t Theme, home string) (string, error) {
+func switchEza(t Theme, l layout) (string, []Action, error) {
 	ezaDir := filepath.Join(t.Dir, "eza")
 	if !dirExists(ezaDir) {
-		return "", nil
+		return "", nil, nil
 	}
 
 	srcFile, err := firstFile(ezaDir)
 	if err != nil || srcFile == "" {
-		return "", err
+		return "", nil, err
 	}
 
-	installedFile := filepath.Join(home, ".config", "eza", "themes", srcFile)
-	link := filepath.Join(home, ".config", "eza", "theme.yml")
-
-	if err := os.MkdirAll(filepath.Dir(link), 0o755); err != nil {
-		return "", err
-	}
-	os.Remove(link)
-	if err := os.Symlink(installedFile, link); err != nil {
-		return "", err
+	link := Action{
+		Kind: ActionSymlink,
+		Path: l.file("eza", "eza", "theme.yml"),
+		Src:  filepath.Join(l.dir("eza", "eza", "themes"), srcFile),
 	}
-	return fmt.Sprintf("eza/theme.yml -> %s", srcFile), nil
+	return fmt.Sprintf("eza/theme.yml -> %s", srcFile), []Action{link}, nil
 }
 
-// switchGhDash copies the installed gh-dash config to ~/.config/gh-dash/config.yml.
-func switchGhDash(t Theme, home string) (string, error) {
+// switchGhDash merges the theme's gh-dash file into
+// ~/.config/gh-dash/config.yml, replacing only its theme.colors block (see
+// mergeGhDashTheme). A missing config is created with just the theme. The
+// merge reads the warehouse copy: switch plans before its install step has
+// copied anything. A config.yml linked into the user's dotfiles is read and
+// written at the link's target, so the link stays.
+func switchGhDash(t Theme, l layout) (string, []Action, error) {
 	ghDashDir := filepath.Join(t.Dir, "gh-dash")
 	if !dirExists(ghDashDir) {
-		return "", nil
+		return "", nil, nil
 	}
 
 	srcFile, err := firstFile(ghDashDir)
 	if err != nil || srcFile == "" {
-		return "", err
+		return "", nil, err
 	}
 
-	src := filepath.Join(home, ".config", "the-themer", "gh-dash", srcFile)
-	dest := filepath.Join(home, ".config", "gh-dash", "config.yml")
+	src := filepath.Join(ghDashDir, srcFile)
+	dest, err := linkTarget(l.file("gh-dash", "gh-dash", "config.yml"))
+	if err != nil {
+		return "", nil, err
+	}
 
-	if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
-		return "", err
+	themeFile, err := os.ReadFile(src)
+	if err != nil {
+		return "", nil, fmt.Errorf("reading %s: %w", src, err)
 	}
-	if err := copyFile(src, dest); err != nil {
-		return "", err
+	config, err := os.ReadFile(dest)
+	if err != nil && !os.IsNotExist(err) {
+		return "", nil, fmt.Errorf("reading %s: %w", dest, err)
 	}
-	return fmt.Sprintf("gh-dash/config.yml -> %s", srcFile), nil
+	merged, err := mergeGhDashTheme(config, themeFile)
+	if err != nil {
+		return "", nil, fmt.Errorf("%s: %w", srcFile, err)
+	}
+
+	write := Action{Kind: ActionWrite, Path: dest, Content: merged}
+	return fmt.Sprintf("gh-dash/config.yml theme <- %s", srcFile), []Action{write}, nil
 }
 
 // switchHud copies the installed widget-color TOML to
 // ~/.config/tail-claude-hud/theme-active.toml, which the statusline layers
 // over its resolved theme on every render tick (no reload signal needed).
-func switchHud(t Theme, home string) (string, error) {
+func switchHud(t Theme, l layout) (string, []Action, error) {
 	hudDir := filepath.Join(t.Dir, "hud")
 	if !dirExists(hudDir) {
-		return "", nil
+		return "", nil, nil
 	}
 
 	srcFile, err := firstFile(hudDir)
 	if err != nil || srcFile == "" {
-		return "", err
+		return "", nil, err
 	}
 
-	src := filepath.Join(home, ".config", "the-themer", "hud", srcFile)
-	dest := filepath.Join(home, ".config", "tail-claude-hud", "theme-active.toml")
-
-	if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
-		return "", err
-	}
-	if err := copyFile(src, dest); err != nil {
-		return "", err
+	cp := Action{
+		Kind: ActionCopy,
+		Src:  filepath.Join(stateDir(l.home), "hud", srcFile),
+		Path: l.file("hud", "tail-claude-hud", "theme-active.toml"),
 	}
-	return fmt.Sprintf("tail-claude-hud/theme-active.toml -> %s", srcFile), nil
+	return fmt.Sprintf("tail-claude-hud/theme-active.toml -> %s", srcFile), []Action{cp}, nil
 }
 
 // switchNeovim uses headless nvim to set the colorscheme via Themery.
-func switchNeovim(t Theme, home string) (string, error) {
+func switchNeovim(t Theme, l layout) (string, []Action, error) {
 	name := t.Config.References["neovim"]
 	if name == "" {
-		return "", nil
+		return "", nil, nil
 	}
 
 	nvimPath, err := exec.LookPath("nvim")
 	if err != nil {
-		return "nvim not on PATH, skipped", nil
+		return "nvim not on PATH, skipped", nil, nil
 	}
 
 	luaCmd := fmt.Sprintf(`pcall(function() require('themery').setThemeByName('%s', true) end)`, name)
-	cmd := exec.Command(nvimPath, "--headless", "-c", fmt.Sprintf("lua %s", luaCmd), "-c", "qa")
-	if out, err := cmd.CombinedOutput(); err != nil {
-		return "", fmt.Errorf("nvim themery switch failed: %s: %w", strings.TrimSpace(string(out)), err)
+	run := Action{
+		Kind: ActionCommand,
+		Args: []string{nvimPath, "--headless", "-c", fmt.Sprintf("lua %s", luaCmd), "-c", "qa"},
 	}
-	return fmt.Sprintf("neovim -> %s", name), nil
+	return fmt.Sprintf("neovim -> %s", name), []Action{run}, nil
 }
 
 // firstFile returns the name of the first regular file in dir, or "" if empty.
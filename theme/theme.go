@@ -22,13 +22,18 @@ type Theme struct {
 // a fully resolved Theme. The themesDir is the parent directory containing
 // all theme directories (e.g., "./themes/").
 func LoadTheme(themesDir, name string) (Theme, error) {
+	return LoadThemeWith(themesDir, name, palette.LoadOpts{})
+}
+
+// LoadThemeWith is LoadTheme with palette loading options.
+func LoadThemeWith(themesDir, name string, opts palette.LoadOpts) (Theme, error) {
 	dir, err := filepath.Abs(filepath.Join(themesDir, name))
 	if err != nil {
 		return Theme{}, fmt.Errorf("resolving theme path: %w", err)
 	}
 
 	palettePath := filepath.Join(dir, "palette.toml")
-	cfg, err := palette.Load(palettePath)
+	cfg, err := palette.LoadWith(palettePath, opts)
 	if err != nil {
 		return Theme{}, fmt.Errorf("loading theme %q: %w", name, err)
 	}
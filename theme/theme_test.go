@@ -7,6 +7,14 @@ import (
 	"testing"
 )
 
+// TestMain keeps the caller's XDG_CONFIG_HOME and GIT_CONFIG_GLOBAL from
+// sending writes outside the tests' temp home directories.
+func TestMain(m *testing.M) {
+	os.Unsetenv("XDG_CONFIG_HOME")
+	os.Unsetenv("GIT_CONFIG_GLOBAL")
+	os.Exit(m.Run())
+}
+
 // minimalPaletteTOML is a valid palette.toml for testing.
 const minimalPaletteTOML = `
 [theme]
@@ -92,6 +100,32 @@ func TestLoadTheme(t *testing.T) {
 	}
 }
 
+func TestLoadTheme_Extends(t *testing.T) {
+	themesDir, _ := setupThemeDir(t, nil, minimalPaletteTOML)
+	writeFile(t, filepath.Join(themesDir, "test-theme-alt", "palette.toml"), `
+[theme]
+name = "test-theme-alt"
+extends = "test-theme"
+
+[palette]
+color4 = "#3344ff"
+`)
+
+	th, err := LoadTheme(themesDir, "test-theme-alt")
+	if err != nil {
+		t.Fatalf("LoadTheme failed: %v", err)
+	}
+	if th.Config.Palette.Color4 != "#3344ff" {
+		t.Errorf("Color4 = %q, want %q", th.Config.Palette.Color4, "#3344ff")
+	}
+	if th.Config.Palette.BG != "#111111" {
+		t.Errorf("BG = %q, want inherited %q", th.Config.Palette.BG, "#111111")
+	}
+	if th.Config.References["neovim"] != "test-scheme" {
+		t.Errorf("References[neovim] = %q, want inherited %q", th.Config.References["neovim"], "test-scheme")
+	}
+}
+
 func TestListThemes(t *testing.T) {
 	tmpDir := t.TempDir()
 	themesDir := filepath.Join(tmpDir, "themes")
@@ -266,6 +300,66 @@ func TestSwitch_StarshipSymlink(t *testing.T) {
 	}
 }
 
+func TestSwitch_InstallsMissingFiles(t *testing.T) {
+	themesDir, themeDir := setupThemeDir(t, []string{"fzf", "starship"}, minimalPaletteTOML)
+	writeFile(t, filepath.Join(themeDir, "fzf", "test-theme.zsh"), "fzf content")
+	writeFile(t, filepath.Join(themeDir, "starship", "chef-starship.toml"), "starship content")
+
+	home := t.TempDir()
+	th, err := LoadTheme(themesDir, "test-theme")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// An out-of-date installed copy is replaced too.
+	installed := filepath.Join(home, ".config", "the-themer", "starship", "chef-starship.toml")
+	writeFile(t, installed, "old starship content")
+
+	results := Switch(th, SwitchOpts{HomeDir: home})
+	checkNoErrors(t, results)
+
+	var install *SwitchResult
+	for i := range results {
+		if results[i].App == "install" {
+			install = &results[i]
+		}
+	}
+	if install == nil || !strings.Contains(install.Message, "fzf, starship") {
+		t.Errorf("install result = %+v, want fzf and starship installed", install)
+	}
+	assertFileContains(t, filepath.Join(home, ".config", "the-themer", "fzf", "current.zsh"), "fzf content")
+	assertFileContains(t, filepath.Join(home, ".config", "starship.toml"), "starship content")
+
+	// Once installed, switching again installs nothing.
+	for _, r := range Switch(th, SwitchOpts{HomeDir: home}) {
+		if r.App == "install" {
+			t.Errorf("second switch installed again: %s", r.Message)
+		}
+	}
+}
+
+func TestSwitch_NoInstall(t *testing.T) {
+	themesDir, themeDir := setupThemeDir(t, []string{"fzf"}, minimalPaletteTOML)
+	writeFile(t, filepath.Join(themeDir, "fzf", "test-theme.zsh"), "fzf content")
+
+	home := t.TempDir()
+	th, err := LoadTheme(themesDir, "test-theme")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	results := Switch(th, SwitchOpts{HomeDir: home, NoInstall: true})
+	checkNoErrors(t, results)
+	for _, r := range results {
+		if r.App == "install" {
+			t.Errorf("installed with NoInstall: %s", r.Message)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(home, ".config", "the-themer", "fzf", "test-theme.zsh")); !os.IsNotExist(err) {
+		t.Errorf("fzf config was installed with NoInstall (err %v)", err)
+	}
+}
+
 func TestSwitch_ReferenceFallback_Bat(t *testing.T) {
 	// Theme with no bat/ dir but references.bat = "Dracula".
 	themesDir, _ := setupThemeDir(t, nil, minimalPaletteTOML)
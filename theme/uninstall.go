@@ -0,0 +1,290 @@
+package theme
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// UninstallOpts configures the uninstall operation.
+type UninstallOpts struct {
+	HomeDir string  // injectable for testing; defaults to os.UserHomeDir()
+	Config  *Config // destinations, disabled apps, reloads; nil for DefaultConfig()
+}
+
+// resolveHome returns opts.HomeDir if set, otherwise os.UserHomeDir().
+func (o UninstallOpts) resolveHome() (string, error) {
+	if o.HomeDir != "" {
+		return o.HomeDir, nil
+	}
+	return os.UserHomeDir()
+}
+
+// UninstallResult holds the outcome of uninstalling one app's config.
+type UninstallResult struct {
+	App     string
+	Skipped bool   // true if the app dir didn't exist in the theme
+	Message string // human-readable outcome
+	Err     error
+}
+
+// Uninstall reverses Install: it removes the files Install copied for each
+// app, drops the theme's git include.path entry, and rebuilds bat's cache.
+// Like Install, every app is attempted.
+//
+// Uninstall doesn't look at which theme is active; removing the active
+// theme's files leaves the apps pointing at nothing, so callers check
+// ReadState first.
+func Uninstall(t Theme, opts UninstallOpts) []UninstallResult {
+	plan, err := PlanUninstall(t, opts)
+	if err != nil {
+		return []UninstallResult{{App: "home", Err: err}}
+	}
+	home, err := opts.resolveHome()
+	if err != nil {
+		return []UninstallResult{{App: "home", Err: fmt.Errorf("resolving home directory: %w", err)}}
+	}
+
+	// Files installed before the themer kept a managed list are backed up
+	// like any other file it didn't write.
+	var results []UninstallResult
+	backup, err := backupUnmanaged(home, plan.Actions())
+	if err != nil {
+		return []UninstallResult{{App: "backup", Err: err}}
+	}
+	if backup != nil {
+		results = append(results, UninstallResult{App: "backup", Message: backup.String()})
+	}
+
+	var removed []Action
+	for _, p := range plan.Apps {
+		if p.Skipped {
+			results = append(results, UninstallResult{App: p.App, Skipped: true, Message: p.Message})
+			continue
+		}
+		msg, err := p.Apply()
+		results = append(results, UninstallResult{App: p.App, Message: msg, Err: err})
+		if err == nil {
+			removed = append(removed, p.Actions...)
+		}
+	}
+
+	if err := unmarkManaged(home, removed); err != nil {
+		results = append(results, UninstallResult{App: "backup", Err: err})
+	}
+	return results
+}
+
+// PlanUninstall computes what Uninstall would do without doing any of it.
+func PlanUninstall(t Theme, opts UninstallOpts) (Plan, error) {
+	home, err := opts.resolveHome()
+	if err != nil {
+		return Plan{}, fmt.Errorf("resolving home directory: %w", err)
+	}
+
+	l := newLayout(home, opts.Config)
+	handlers := []struct {
+		app  string
+		plan func(t Theme, l layout) (string, []Action, error)
+	}{
+		{"ghostty", uninstallGhostty},
+		{"bat", uninstallBat},
+		{"delta", uninstallDelta},
+		{"fzf", uninstallFzf},
+		{"tcm", uninstallTCM},
+		{"starship", uninstallStarship},
+		{"eza", uninstallEza},
+		{"gh-dash", uninstallGhDash},
+		{"hud", uninstallHud},
+	}
+
+	var plan Plan
+	for _, h := range handlers {
+		if l.cfg.disabled(h.app) {
+			plan.Apps = append(plan.Apps, AppPlan{App: h.app, Skipped: true, Message: "disabled in config.toml"})
+			continue
+		}
+		appDir := filepath.Join(t.Dir, h.app)
+		if _, err := os.Stat(appDir); os.IsNotExist(err) {
+			plan.Apps = append(plan.Apps, AppPlan{App: h.app, Skipped: true, Message: "no config in theme"})
+			continue
+		}
+
+		msg, actions, err := h.plan(t, l)
+		plan.Apps = append(plan.Apps, AppPlan{App: h.app, Message: msg, Actions: actions, Err: err})
+	}
+	return plan, nil
+}
+
+// uninstallGhostty removes the theme's files from ~/.config/ghostty/themes/.
+func uninstallGhostty(t Theme, l layout) (string, []Action, error) {
+	srcDir := filepath.Join(t.Dir, "ghostty")
+	destDir := l.dir("ghostty", "ghostty", "themes")
+	return removeDirContents(t, srcDir, destDir)
+}
+
+// uninstallBat removes the .tmTheme from bat's themes dir and rebuilds the
+// cache, which otherwise keeps offering the removed theme.
+func uninstallBat(t Theme, l layout) (string, []Action, error) {
+	srcDir := filepath.Join(t.Dir, "bat")
+	destDir, err := batThemesDir(l)
+	if err != nil {
+		return "", nil, err
+	}
+
+	msg, actions, err := removeDirContents(t, srcDir, destDir)
+	if err != nil || len(actions) == 0 || !l.cfg.Reload.BatCache {
+		return msg, actions, err
+	}
+
+	if batPath, lookErr := exec.LookPath("bat"); lookErr == nil {
+		actions = append(actions, Action{
+			Kind: ActionCommand,
+			Args: []string{batPath, "cache", "--build"},
+			Note: "bat cache rebuilt",
+		})
+	} else {
+		msg += "; bat not on PATH, skipped cache rebuild"
+	}
+	return msg, actions, nil
+}
+
+// uninstallDelta removes the gitconfig from ~/.config/the-themer/delta/
+// and any per-theme include.path entry an earlier install added for it.
+// The active.gitconfig include stays: it's shared by every theme.
+func uninstallDelta(t Theme, l layout) (string, []Action, error) {
+	srcDir := filepath.Join(t.Dir, "delta")
+	destDir := deltaDir(l.home)
+
+	msg, actions, err := removeDirContents(t, srcDir, destDir)
+	if err != nil {
+		return msg, nil, err
+	}
+
+	entries, err := os.ReadDir(srcDir)
+	if err != nil {
+		return msg, nil, err
+	}
+	ours := map[string]bool{}
+	for _, e := range entries {
+		if !e.IsDir() {
+			ours[filepath.Join(destDir, e.Name())] = true
+		}
+	}
+
+	// The include entry goes even if the file is already gone: git
+	// silently skips a missing include, but the entry would linger.
+	edit, _, err := planGitIncludes(l.home, func(path string) bool {
+		return ours[filepath.Clean(path)]
+	}, "")
+	if err != nil {
+		return msg, nil, fmt.Errorf("removing git include.path: %w", err)
+	}
+	if edit != nil {
+		edit.Note = "git include.path removed"
+		actions = append(actions, *edit)
+	}
+	return msg, actions, nil
+}
+
+// uninstallFzf removes fzf config from ~/.config/the-themer/fzf/.
+func uninstallFzf(t Theme, l layout) (string, []Action, error) {
+	srcDir := filepath.Join(t.Dir, "fzf")
+	destDir := filepath.Join(stateDir(l.home), "fzf")
+	return removeDirContents(t, srcDir, destDir)
+}
+
+// uninstallTCM removes the theme JSON from ~/.config/tcm/.
+func uninstallTCM(t Theme, l layout) (string, []Action, error) {
+	srcDir := filepath.Join(t.Dir, "tcm")
+	destDir := l.dir("tcm", "tcm")
+	return removeDirContents(t, srcDir, destDir)
+}
+
+// uninstallStarship removes starship config from ~/.config/the-themer/starship/.
+func uninstallStarship(t Theme, l layout) (string, []Action, error) {
+	srcDir := filepath.Join(t.Dir, "starship")
+	destDir := filepath.Join(stateDir(l.home), "starship")
+	return removeDirContents(t, srcDir, destDir)
+}
+
+// uninstallEza removes the eza theme from ~/.config/eza/themes/.
+func uninstallEza(t Theme, l layout) (string, []Action, error) {
+	srcDir := filepath.Join(t.Dir, "eza")
+	destDir := l.dir("eza", "eza", "themes")
+	return removeDirContents(t, srcDir, destDir)
+}
+
+// uninstallGhDash removes gh-dash config from ~/.config/the-themer/gh-dash/.
+func uninstallGhDash(t Theme, l layout) (string, []Action, error) {
+	srcDir := filepath.Join(t.Dir, "gh-dash")
+	destDir := filepath.Join(stateDir(l.home), "gh-dash")
+	return removeDirContents(t, srcDir, destDir)
+}
+
+// uninstallHud removes the widget-color TOML from ~/.config/the-themer/hud/.
+func uninstallHud(t Theme, l layout) (string, []Action, error) {
+	srcDir := filepath.Join(t.Dir, "hud")
+	destDir := filepath.Join(stateDir(l.home), "hud")
+	return removeDirContents(t, srcDir, destDir)
+}
+
+// removeDirContents plans removing from dest every file install would have
+// copied there from src. Files that aren't installed are left out, and so
+// are files another theme in the warehouse ships too (starship configs are
+// shared by name), since that theme may still use them.
+func removeDirContents(t Theme, srcDir, destDir string) (string, []Action, error) {
+	entries, err := os.ReadDir(srcDir)
+	if err != nil {
+		return "", nil, fmt.Errorf("reading %s: %w", srcDir, err)
+	}
+
+	var actions []Action
+	var removed, kept []string
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		dest := filepath.Join(destDir, e.Name())
+		if _, err := os.Lstat(dest); err != nil {
+			continue
+		}
+		if other := sharedWith(t, filepath.Base(srcDir), e.Name()); other != "" {
+			kept = append(kept, fmt.Sprintf("%s (shared with %s)", e.Name(), other))
+			continue
+		}
+		removed = append(removed, e.Name())
+		actions = append(actions, Action{Kind: ActionRemove, Path: dest})
+	}
+
+	switch {
+	case len(removed) > 0 && len(kept) > 0:
+		return fmt.Sprintf("removed %s from %s (kept: %s)", strings.Join(removed, ", "), destDir, strings.Join(kept, ", ")), actions, nil
+	case len(removed) > 0:
+		return fmt.Sprintf("removed %s from %s", strings.Join(removed, ", "), destDir), actions, nil
+	case len(kept) > 0:
+		return fmt.Sprintf("kept %s in %s", strings.Join(kept, ", "), destDir), nil, nil
+	}
+	return fmt.Sprintf("not installed in %s", destDir), nil, nil
+}
+
+// sharedWith returns the name of another theme beside t whose app dir has
+// a file with the given name, or "" if none does.
+func sharedWith(t Theme, app, name string) string {
+	themesDir := filepath.Dir(t.Dir)
+	entries, err := os.ReadDir(themesDir)
+	if err != nil {
+		return ""
+	}
+	for _, e := range entries {
+		if !e.IsDir() || e.Name() == filepath.Base(t.Dir) {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(themesDir, e.Name(), app, name)); err == nil {
+			return e.Name()
+		}
+	}
+	return ""
+}
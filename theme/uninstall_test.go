@@ -0,0 +1,113 @@
+package theme
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestUninstall_RemovesInstalledFiles(t *testing.T) {
+	themesDir, themeDir := setupThemeDir(t, []string{"ghostty", "fzf", "eza"}, minimalPaletteTOML)
+	writeFile(t, filepath.Join(themeDir, "ghostty", "test-theme.ghostty"), "ghostty config content")
+	writeFile(t, filepath.Join(themeDir, "fzf", "test-theme.zsh"), "fzf config content")
+	writeFile(t, filepath.Join(themeDir, "eza", "test-theme.yml"), "eza config content")
+
+	home := t.TempDir()
+	th, err := LoadTheme(themesDir, "test-theme")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range Install(th, InstallOpts{HomeDir: home}) {
+		if r.Err != nil {
+			t.Fatalf("install %s: %v", r.App, r.Err)
+		}
+	}
+
+	// Another theme's file in the same directory stays.
+	config := filepath.Join(home, ".config")
+	other := filepath.Join(config, "ghostty", "themes", "other.ghostty")
+	writeFile(t, other, "other theme")
+
+	for _, r := range Uninstall(th, UninstallOpts{HomeDir: home}) {
+		if r.Err != nil {
+			t.Errorf("uninstall %s: %v", r.App, r.Err)
+		}
+		if r.App == "backup" {
+			t.Errorf("installed files were backed up: %s", r.Message)
+		}
+	}
+
+	for _, path := range []string{
+		filepath.Join(config, "ghostty", "themes", "test-theme.ghostty"),
+		filepath.Join(config, "the-themer", "fzf", "test-theme.zsh"),
+		filepath.Join(config, "eza", "themes", "test-theme.yml"),
+	} {
+		if _, err := os.Lstat(path); !os.IsNotExist(err) {
+			t.Errorf("%s still exists after uninstall", path)
+		}
+	}
+	assertFileContains(t, other, "other theme")
+
+	managed, err := readManaged(home)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(managed) != 0 {
+		t.Errorf("managed list after uninstall = %v, want empty", managed)
+	}
+}
+
+func TestPlanUninstall_NotInstalled(t *testing.T) {
+	themesDir, themeDir := setupThemeDir(t, []string{"ghostty"}, minimalPaletteTOML)
+	writeFile(t, filepath.Join(themeDir, "ghostty", "test-theme.ghostty"), "config")
+
+	home := t.TempDir()
+	th, err := LoadTheme(themesDir, "test-theme")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	plan, err := PlanUninstall(th, UninstallOpts{HomeDir: home})
+	if err != nil {
+		t.Fatalf("PlanUninstall: %v", err)
+	}
+	if n := len(plan.Actions()); n != 0 {
+		t.Errorf("got %d actions for a theme that isn't installed: %v", n, plan.Actions())
+	}
+	if p, _ := plan.App("ghostty"); p.Skipped || p.Err != nil {
+		t.Errorf("ghostty: got %+v, want a not-installed message", p)
+	}
+	if p, _ := plan.App("fzf"); !p.Skipped {
+		t.Errorf("fzf: got %+v, want skipped", p)
+	}
+}
+
+func TestPlanUninstall_KeepsSharedFiles(t *testing.T) {
+	themesDir, themeDir := setupThemeDir(t, []string{"starship"}, minimalPaletteTOML)
+	writeFile(t, filepath.Join(themeDir, "starship", "shared-starship.toml"), "starship config")
+	writeFile(t, filepath.Join(themesDir, "other-theme", "starship", "shared-starship.toml"), "starship config")
+
+	home := t.TempDir()
+	th, err := LoadTheme(themesDir, "test-theme")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range Install(th, InstallOpts{HomeDir: home}) {
+		if r.Err != nil {
+			t.Fatalf("install %s: %v", r.App, r.Err)
+		}
+	}
+
+	plan, err := PlanUninstall(th, UninstallOpts{HomeDir: home})
+	if err != nil {
+		t.Fatalf("PlanUninstall: %v", err)
+	}
+	p, _ := plan.App("starship")
+	if len(p.Actions) != 0 {
+		t.Errorf("planned removing a file other-theme ships: %v", p.Actions)
+	}
+	if !strings.Contains(p.Message, "shared with other-theme") {
+		t.Errorf("message = %q, want it to name other-theme", p.Message)
+	}
+}